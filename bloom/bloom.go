@@ -0,0 +1,260 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package bloom implements Bloom filters using CircleHash64f.
+//
+// Filter derives k bit indices from one Hash64 digest using double hashing:
+//
+//	h1 = circlehash.Hash64(data, seed)
+//	h2 = circlehash.Hash64Uint64x2(h1, 0, seed)
+//	index i = high 64 bits of (h1 + i*h2) * m, for i in [0, k)
+//
+// where m is number of bits in filter and arithmetic is modulo 2^64.
+// Serialized filters record the seed and this algorithm, so filters
+// produced by different services can be shared and merged.
+package bloom
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/fxamacker/circlehash"
+)
+
+const (
+	formatVersion = 1
+
+	// algorithmCircleHash64fDoubleHashing is the index derivation described
+	// in package documentation.
+	algorithmCircleHash64fDoubleHashing = 1
+
+	// headerSize is the size of serialized filter header:
+	// magic (4), version (1), algorithm (1), reserved (2), k (4), m (8), seed (8).
+	headerSize = 28
+
+	// maxK limits number of hash functions.
+	maxK = 64
+)
+
+var magic = [4]byte{'C', 'H', 'B', 'F'}
+
+var (
+	// ErrIncompatible is returned when combining filters with different
+	// number of bits, number of hash functions, or seed.
+	ErrIncompatible = errors.New("bloom: filters have different parameters")
+
+	// ErrInvalidData is returned by UnmarshalBinary when data isn't a valid serialized filter.
+	ErrInvalidData = errors.New("bloom: invalid serialized filter")
+)
+
+// Filter is a Bloom filter.  It is not safe for concurrent use.
+type Filter struct {
+	m    uint64 // number of bits
+	k    uint32 // number of hash functions
+	seed uint64
+	bits []uint64
+}
+
+// New returns a Filter sized for n items with false positive rate fpRate.
+func New(n uint64, fpRate float64, seed uint64) (*Filter, error) {
+	if fpRate <= 0 || fpRate >= 1 || math.IsNaN(fpRate) {
+		return nil, fmt.Errorf("bloom: false positive rate %v is not in range (0, 1)", fpRate)
+	}
+	if n == 0 {
+		n = 1
+	}
+
+	m := math.Ceil(-float64(n) * math.Log(fpRate) / (math.Ln2 * math.Ln2))
+	k := math.Round(m / float64(n) * math.Ln2)
+
+	return NewWithSize(uint64(m), uint32(math.Max(1, math.Min(k, maxK))), seed)
+}
+
+// NewWithSize returns a Filter with m bits and k hash functions.
+func NewWithSize(m uint64, k uint32, seed uint64) (*Filter, error) {
+	if m == 0 {
+		return nil, errors.New("bloom: number of bits is 0")
+	}
+	if k == 0 || k > maxK {
+		return nil, fmt.Errorf("bloom: number of hash functions %d is not in range [1, %d]", k, maxK)
+	}
+	if m > math.MaxInt32*64 {
+		return nil, fmt.Errorf("bloom: number of bits %d is too large", m)
+	}
+	return &Filter{
+		m:    m,
+		k:    k,
+		seed: seed,
+		bits: make([]uint64, (m+63)/64),
+	}, nil
+}
+
+// M returns number of bits in f.
+func (f *Filter) M() uint64 {
+	return f.m
+}
+
+// K returns number of hash functions used by f.
+func (f *Filter) K() uint32 {
+	return f.k
+}
+
+// Seed returns the seed used by f.
+func (f *Filter) Seed() uint64 {
+	return f.seed
+}
+
+// Add adds b to f.
+func (f *Filter) Add(b []byte) {
+	f.add(circlehash.Hash64(b, f.seed))
+}
+
+// AddString adds s to f.
+func (f *Filter) AddString(s string) {
+	f.add(circlehash.Hash64String(s, f.seed))
+}
+
+// Test returns true if b may be in f, and false if b is definitely not in f.
+func (f *Filter) Test(b []byte) bool {
+	return f.test(circlehash.Hash64(b, f.seed))
+}
+
+// TestString returns true if s may be in f, and false if s is definitely not in f.
+func (f *Filter) TestString(s string) bool {
+	return f.test(circlehash.Hash64String(s, f.seed))
+}
+
+func (f *Filter) add(h1 uint64) {
+	h2 := circlehash.Hash64Uint64x2(h1, 0, f.seed)
+	for i := uint32(0); i < f.k; i++ {
+		idx, _ := bits.Mul64(h1, f.m)
+		f.bits[idx/64] |= 1 << (idx % 64)
+		h1 += h2
+	}
+}
+
+func (f *Filter) test(h1 uint64) bool {
+	h2 := circlehash.Hash64Uint64x2(h1, 0, f.seed)
+	for i := uint32(0); i < f.k; i++ {
+		idx, _ := bits.Mul64(h1, f.m)
+		if f.bits[idx/64]&(1<<(idx%64)) == 0 {
+			return false
+		}
+		h1 += h2
+	}
+	return true
+}
+
+// Union sets f to union of f and g, so f contains items added to either filter.
+// It returns ErrIncompatible if g has different parameters.
+func (f *Filter) Union(g *Filter) error {
+	if !f.compatible(g) {
+		return ErrIncompatible
+	}
+	for i := range f.bits {
+		f.bits[i] |= g.bits[i]
+	}
+	return nil
+}
+
+// Intersect sets f to intersection of f and g.  Items added to both filters
+// remain in f, and false positive rate is at most that of f or g.
+// It returns ErrIncompatible if g has different parameters.
+func (f *Filter) Intersect(g *Filter) error {
+	if !f.compatible(g) {
+		return ErrIncompatible
+	}
+	for i := range f.bits {
+		f.bits[i] &= g.bits[i]
+	}
+	return nil
+}
+
+func (f *Filter) compatible(g *Filter) bool {
+	return f.m == g.m && f.k == g.k && f.seed == g.seed
+}
+
+// EstimatedCount returns estimated number of distinct items added to f.
+func (f *Filter) EstimatedCount() uint64 {
+	ones := 0
+	for _, w := range f.bits {
+		ones += bits.OnesCount64(w)
+	}
+	if uint64(ones) == f.m {
+		return math.MaxUint64
+	}
+	m := float64(f.m)
+	return uint64(math.Round(-m / float64(f.k) * math.Log(1-float64(ones)/m)))
+}
+
+// MarshalBinary returns serialized f.  Serialized filter records format
+// version, index algorithm, number of hash functions, number of bits, and seed.
+func (f *Filter) MarshalBinary() ([]byte, error) {
+	data := make([]byte, headerSize+8*len(f.bits))
+	copy(data, magic[:])
+	data[4] = formatVersion
+	data[5] = algorithmCircleHash64fDoubleHashing
+	binary.LittleEndian.PutUint32(data[8:], f.k)
+	binary.LittleEndian.PutUint64(data[12:], f.m)
+	binary.LittleEndian.PutUint64(data[20:], f.seed)
+	for i, w := range f.bits {
+		binary.LittleEndian.PutUint64(data[headerSize+8*i:], w)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary sets f to filter serialized by MarshalBinary.
+func (f *Filter) UnmarshalBinary(data []byte) error {
+	if len(data) < headerSize || [4]byte{data[0], data[1], data[2], data[3]} != magic {
+		return ErrInvalidData
+	}
+	if data[4] != formatVersion {
+		return fmt.Errorf("bloom: unsupported format version %d", data[4])
+	}
+	if data[5] != algorithmCircleHash64fDoubleHashing {
+		return fmt.Errorf("bloom: unsupported algorithm %d", data[5])
+	}
+
+	k := binary.LittleEndian.Uint32(data[8:])
+	m := binary.LittleEndian.Uint64(data[12:])
+	seed := binary.LittleEndian.Uint64(data[20:])
+
+	// Check data size before allocating bits, so untrusted m can't
+	// allocate more memory than data size.
+	words := m / 64
+	if m%64 != 0 {
+		words++
+	}
+	if n := uint64(len(data) - headerSize); n%8 != 0 || n/8 != words {
+		return ErrInvalidData
+	}
+
+	g, err := NewWithSize(m, k, seed)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidData, err)
+	}
+	for i := range g.bits {
+		g.bits[i] = binary.LittleEndian.Uint64(data[headerSize+8*i:])
+	}
+	if m%64 != 0 && g.bits[len(g.bits)-1]>>(m%64) != 0 {
+		// Bits beyond m must be 0.
+		return ErrInvalidData
+	}
+
+	*f = *g
+	return nil
+}
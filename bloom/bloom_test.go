@@ -0,0 +1,300 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bloom
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+)
+
+const testSeed = uint64(0x9E3779B97F4A7C15)
+
+func newTestFilter(t *testing.T, n uint64, fpRate float64) *Filter {
+	f, err := New(n, fpRate, testSeed)
+	if err != nil {
+		t.Fatalf("New(%d, %v) returned error %v", n, fpRate, err)
+	}
+	return f
+}
+
+func TestNew(t *testing.T) {
+	testCases := []struct {
+		n      uint64
+		fpRate float64
+		wantM  uint64
+		wantK  uint32
+	}{
+		{0, 0.01, 10, 7},
+		{1000, 0.01, 9586, 7},
+		{1000, 0.001, 14378, 10},
+		{1000000, 0.05, 6235225, 4},
+	}
+
+	for _, tc := range testCases {
+		f := newTestFilter(t, tc.n, tc.fpRate)
+		if f.M() != tc.wantM || f.K() != tc.wantK {
+			t.Errorf("New(%d, %v) returned filter with m=%d, k=%d; want m=%d, k=%d", tc.n, tc.fpRate, f.M(), f.K(), tc.wantM, tc.wantK)
+		}
+		if f.Seed() != testSeed {
+			t.Errorf("New(%d, %v) returned filter with seed 0x%x; want 0x%x", tc.n, tc.fpRate, f.Seed(), testSeed)
+		}
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	for _, fpRate := range []float64{0, 1, -0.5, 2} {
+		if _, err := New(1000, fpRate, testSeed); err == nil {
+			t.Errorf("New(1000, %v) didn't return error", fpRate)
+		}
+	}
+	if _, err := NewWithSize(0, 3, testSeed); err == nil {
+		t.Error("NewWithSize(0, 3) didn't return error")
+	}
+	if _, err := NewWithSize(1000, 0, testSeed); err == nil {
+		t.Error("NewWithSize(1000, 0) didn't return error")
+	}
+	if _, err := NewWithSize(1000, maxK+1, testSeed); err == nil {
+		t.Errorf("NewWithSize(1000, %d) didn't return error", maxK+1)
+	}
+}
+
+func TestFalsePositiveRate(t *testing.T) {
+	const n = 20000
+
+	for _, fpRate := range []float64{0.1, 0.01, 0.001} {
+		f := newTestFilter(t, n, fpRate)
+		for i := 0; i < n; i++ {
+			f.AddString("key" + strconv.Itoa(i))
+		}
+
+		// No false negatives.
+		for i := 0; i < n; i++ {
+			if !f.Test([]byte("key" + strconv.Itoa(i))) {
+				t.Fatalf("Test(%q) = false after Add", "key"+strconv.Itoa(i))
+			}
+		}
+
+		const trials = 200000
+		fp := 0
+		for i := 0; i < trials; i++ {
+			if f.TestString("other" + strconv.Itoa(i)) {
+				fp++
+			}
+		}
+
+		got := float64(fp) / trials
+		if got > fpRate*1.5 || got < fpRate/1.5 {
+			t.Errorf("false positive rate = %v; want about %v", got, fpRate)
+		}
+
+		if est := f.EstimatedCount(); est < n*95/100 || est > n*105/100 {
+			t.Errorf("EstimatedCount() = %d; want about %d", est, n)
+		}
+	}
+}
+
+func TestSeed(t *testing.T) {
+	f1, _ := NewWithSize(1024, 3, 1)
+	f2, _ := NewWithSize(1024, 3, 2)
+	f1.AddString("hello")
+	f2.AddString("hello")
+
+	m1, _ := f1.MarshalBinary()
+	m2, _ := f2.MarshalBinary()
+	if bytes.Equal(m1[headerSize:], m2[headerSize:]) {
+		t.Error("filters with different seeds set the same bits")
+	}
+}
+
+func TestUnionIntersect(t *testing.T) {
+	f1 := newTestFilter(t, 1000, 0.001)
+	f2 := newTestFilter(t, 1000, 0.001)
+	for i := 0; i < 500; i++ {
+		f1.AddString("a" + strconv.Itoa(i))
+		f2.AddString("b" + strconv.Itoa(i))
+	}
+	f1.AddString("both")
+	f2.AddString("both")
+
+	union := newTestFilter(t, 1000, 0.001)
+	_ = union.Union(f1)
+	if err := union.Union(f2); err != nil {
+		t.Fatalf("Union() returned error %v", err)
+	}
+	for i := 0; i < 500; i++ {
+		if !union.TestString("a"+strconv.Itoa(i)) || !union.TestString("b"+strconv.Itoa(i)) {
+			t.Fatalf("union doesn't contain item %d", i)
+		}
+	}
+
+	if err := f1.Intersect(f2); err != nil {
+		t.Fatalf("Intersect() returned error %v", err)
+	}
+	if !f1.TestString("both") {
+		t.Error("intersection doesn't contain item added to both filters")
+	}
+	found := 0
+	for i := 0; i < 500; i++ {
+		if f1.TestString("a" + strconv.Itoa(i)) {
+			found++
+		}
+	}
+	if found > 10 {
+		t.Errorf("intersection contains %d of 500 items added to one filter", found)
+	}
+}
+
+func TestIncompatible(t *testing.T) {
+	f, _ := NewWithSize(1024, 3, 1)
+	others := []*Filter{
+		{m: 1025, k: 3, seed: 1, bits: make([]uint64, 17)},
+		{m: 1024, k: 4, seed: 1, bits: make([]uint64, 16)},
+		{m: 1024, k: 3, seed: 2, bits: make([]uint64, 16)},
+	}
+	for _, g := range others {
+		if err := f.Union(g); !errors.Is(err, ErrIncompatible) {
+			t.Errorf("Union(m=%d, k=%d, seed=%d) returned error %v; want %v", g.m, g.k, g.seed, err, ErrIncompatible)
+		}
+		if err := f.Intersect(g); !errors.Is(err, ErrIncompatible) {
+			t.Errorf("Intersect(m=%d, k=%d, seed=%d) returned error %v; want %v", g.m, g.k, g.seed, err, ErrIncompatible)
+		}
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	f := newTestFilter(t, 1000, 0.01)
+	for i := 0; i < 1000; i++ {
+		f.AddString(strconv.Itoa(i))
+	}
+
+	data, err := f.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() returned error %v", err)
+	}
+
+	var g Filter
+	if err := g.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary() returned error %v", err)
+	}
+	if g.M() != f.M() || g.K() != f.K() || g.Seed() != f.Seed() {
+		t.Errorf("UnmarshalBinary() returned filter with m=%d, k=%d, seed=0x%x; want m=%d, k=%d, seed=0x%x",
+			g.M(), g.K(), g.Seed(), f.M(), f.K(), f.Seed())
+	}
+	for i := 0; i < 1000; i++ {
+		if !g.TestString(strconv.Itoa(i)) {
+			t.Fatalf("unmarshaled filter doesn't contain %d", i)
+		}
+	}
+
+	data2, _ := g.MarshalBinary()
+	if !bytes.Equal(data, data2) {
+		t.Error("MarshalBinary() of unmarshaled filter returned different data")
+	}
+}
+
+func TestMarshalBinaryStable(t *testing.T) {
+	// Serialized filter must not change, so filters can be shared.
+	f, _ := NewWithSize(64, 3, testSeed)
+	f.AddString("hello")
+
+	data, _ := f.MarshalBinary()
+	want := []byte{
+		'C', 'H', 'B', 'F', 1, 1, 0, 0,
+		3, 0, 0, 0,
+		64, 0, 0, 0, 0, 0, 0, 0,
+		0x15, 0x7c, 0x4a, 0x7f, 0xb9, 0x79, 0x37, 0x9e,
+	}
+	if !bytes.Equal(data[:headerSize], want) {
+		t.Errorf("MarshalBinary() header = %x; want %x", data[:headerSize], want)
+	}
+}
+
+func TestUnmarshalBinaryInvalid(t *testing.T) {
+	f, _ := NewWithSize(100, 3, testSeed)
+	f.AddString("hello")
+	valid, _ := f.MarshalBinary()
+
+	modify := func(fn func(b []byte) []byte) []byte {
+		b := append([]byte(nil), valid...)
+		return fn(b)
+	}
+
+	testCases := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"short header", valid[:headerSize-1]},
+		{"bad magic", modify(func(b []byte) []byte { b[0] = 'X'; return b })},
+		{"bad version", modify(func(b []byte) []byte { b[4] = 2; return b })},
+		{"bad algorithm", modify(func(b []byte) []byte { b[5] = 0; return b })},
+		{"zero k", modify(func(b []byte) []byte { b[8] = 0; return b })},
+		{"zero m", modify(func(b []byte) []byte { b[12] = 0; return b })},
+		{"truncated bits", valid[:len(valid)-1]},
+		{"extra bits", append(append([]byte(nil), valid...), 0)},
+		{"bits beyond m", modify(func(b []byte) []byte { b[len(b)-1] = 0x80; return b })},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var g Filter
+			if err := g.UnmarshalBinary(tc.data); err == nil {
+				t.Error("UnmarshalBinary() didn't return error")
+			}
+		})
+	}
+}
+
+func TestUnmarshalBinaryHugeM(t *testing.T) {
+	f, _ := NewWithSize(100, 3, testSeed)
+	data, _ := f.MarshalBinary()
+	data = data[:headerSize]
+
+	// Header declares m close to the limit (16 GiB of bits) without bits.
+	for _, m := range []uint64{math.MaxInt32 * 64, math.MaxUint64} {
+		binary.LittleEndian.PutUint64(data[12:], m)
+
+		var g Filter
+		allocs := testing.AllocsPerRun(10, func() {
+			if err := g.UnmarshalBinary(data); err == nil {
+				t.Errorf("UnmarshalBinary() with m %d and no bits didn't return error", m)
+			}
+		})
+		if allocs != 0 {
+			t.Errorf("UnmarshalBinary() with m %d and no bits allocated %v times; want 0", m, allocs)
+		}
+	}
+}
+
+func BenchmarkAdd(b *testing.B) {
+	f, _ := New(1000000, 0.01, testSeed)
+	key := []byte("user.name@example.com")
+	for i := 0; i < b.N; i++ {
+		f.Add(key)
+	}
+}
+
+func BenchmarkTest(b *testing.B) {
+	f, _ := New(1000000, 0.01, testSeed)
+	key := []byte("user.name@example.com")
+	f.Add(key)
+	for i := 0; i < b.N; i++ {
+		_ = f.Test(key)
+	}
+}
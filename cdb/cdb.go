@@ -0,0 +1,496 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cdb implements a constant database: a read-only file of key/value
+// records with a hash table for lookup, similar to D. J. Bernstein's cdb.
+// Keys are hashed with circlehash.Hash64 using seed stored in file header.
+//
+// File layout (integers are little-endian):
+//
+//	header (64 bytes):
+//	  magic "CHDB" (4), version (1), algorithm (1), reserved (2),
+//	  seed (8), number of records (8), table offset (8),
+//	  number of home slots (8), number of table slots (8),
+//	  max probe length (4), reserved (4),
+//	  checksum of preceding header bytes with seed 0 (8)
+//	records, starting at offset 64:
+//	  key length (4), value length (4), key, value,
+//	  checksum of preceding record bytes (8)
+//	table, at table offset:
+//	  slots of key digest (8), record offset (8), record length (8),
+//	  checksum of preceding slot bytes (8)
+//
+// Record and slot checksums are circlehash.Hash64 with seed.  Empty slots
+// have checksums too, so a corrupted slot is detected instead of ending
+// the probe or skipping the key.  Key with digest h is stored
+// in the first empty slot at or after home slot
+//
+//	home = high 64 bits of 128-bit product h * number of home slots
+//
+// Table has extra slots after home slots so probing doesn't wrap around,
+// and probe length is at most max probe length.  Reader finds a key with
+// two reads: slots from home slot to home slot + max probe length, and
+// the record (unless digests of different keys collide).
+package cdb
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/bits"
+	"os"
+
+	"github.com/fxamacker/circlehash"
+)
+
+const (
+	formatVersion = 1
+
+	// algorithmCircleHash64fLinearProbing is a table with linear probing
+	// using CircleHash64f digests of keys.
+	algorithmCircleHash64fLinearProbing = 1
+
+	headerSize       = 64
+	slotSize         = 32
+	recordHeaderSize = 8
+	checksumSize     = 8
+
+	// MaxKeyLen is maximum length of key.
+	MaxKeyLen = math.MaxUint32
+
+	// MaxValueLen is maximum length of value.
+	MaxValueLen = math.MaxUint32
+)
+
+var magic = [4]byte{'C', 'H', 'D', 'B'}
+
+var (
+	// ErrNotFound is returned by Get when key isn't in database.
+	ErrNotFound = errors.New("cdb: key not found")
+
+	// ErrInvalidData is returned when file isn't a valid database.
+	ErrInvalidData = errors.New("cdb: invalid database")
+
+	// ErrChecksum is returned when checksum of header, record, or table
+	// slot doesn't match.
+	ErrChecksum = errors.New("cdb: checksum mismatch")
+
+	// ErrClosed is returned when Writer is used after Close.
+	ErrClosed = errors.New("cdb: writer is closed")
+)
+
+// slot is a hash table slot.  Empty slot has offset 0.
+type slot struct {
+	hash   uint64
+	offset uint64
+	length uint64
+}
+
+// Writer writes a database.  Records are written as they are added,
+// and hash table and header are written by Close.
+type Writer struct {
+	ws     io.WriteSeeker
+	bw     *bufio.Writer
+	seed   uint64
+	offset uint64 // offset of next record
+	slots  []slot // slots of added records in order
+	err    error
+}
+
+// NewWriter returns a Writer that writes database to ws with seed.
+// Database starts at current offset of ws.
+func NewWriter(ws io.WriteSeeker, seed uint64) (*Writer, error) {
+	// Reserve space for header.
+	var header [headerSize]byte
+	if _, err := ws.Write(header[:]); err != nil {
+		return nil, err
+	}
+	return &Writer{
+		ws:     ws,
+		bw:     bufio.NewWriter(ws),
+		seed:   seed,
+		offset: headerSize,
+	}, nil
+}
+
+// Put adds record with key and value.  If key is added more than once,
+// Get returns the first value, and Reader.ForEach returns all values.
+func (w *Writer) Put(key, value []byte) error {
+	if w.err != nil {
+		return w.err
+	}
+	if uint64(len(key)) > MaxKeyLen || uint64(len(value)) > MaxValueLen {
+		return fmt.Errorf("cdb: key length %d or value length %d is too large", len(key), len(value))
+	}
+
+	var b [recordHeaderSize]byte
+	binary.LittleEndian.PutUint32(b[:], uint32(len(key)))
+	binary.LittleEndian.PutUint32(b[4:], uint32(len(value)))
+
+	d := circlehash.NewDigest64(w.seed)
+	d.Write(b[:])
+	d.Write(key)
+	d.Write(value)
+
+	var sum [checksumSize]byte
+	binary.LittleEndian.PutUint64(sum[:], d.Sum64())
+
+	for _, p := range [][]byte{b[:], key, value, sum[:]} {
+		if _, err := w.bw.Write(p); err != nil {
+			w.err = err
+			return err
+		}
+	}
+
+	length := uint64(recordHeaderSize + len(key) + len(value) + checksumSize)
+	w.slots = append(w.slots, slot{
+		hash:   circlehash.Hash64(key, w.seed),
+		offset: w.offset,
+		length: length,
+	})
+	w.offset += length
+	return nil
+}
+
+// PutString adds record with key and value.
+func (w *Writer) PutString(key, value string) error {
+	return w.Put([]byte(key), []byte(value))
+}
+
+// Close writes hash table and header.  It doesn't close underlying writer.
+func (w *Writer) Close() error {
+	if w.err != nil {
+		return w.err
+	}
+	w.err = ErrClosed
+
+	homeSlots := uint64(2 * len(w.slots))
+	if homeSlots == 0 {
+		homeSlots = 1
+	}
+
+	// Insert records in order, so the first record with a key is found first.
+	table := make([]slot, homeSlots)
+	maxProbe := uint64(0)
+	for _, s := range w.slots {
+		home, _ := bits.Mul64(s.hash, homeSlots)
+		i := home
+		for i < uint64(len(table)) && table[i].offset != 0 {
+			i++
+		}
+		if i == uint64(len(table)) {
+			table = append(table, slot{})
+		}
+		table[i] = s
+		if probe := i - home + 1; probe > maxProbe {
+			maxProbe = probe
+		}
+	}
+	if maxProbe > math.MaxUint32 {
+		return fmt.Errorf("cdb: probe length %d is too large", maxProbe)
+	}
+
+	var b [slotSize]byte
+	for _, s := range table {
+		binary.LittleEndian.PutUint64(b[:], s.hash)
+		binary.LittleEndian.PutUint64(b[8:], s.offset)
+		binary.LittleEndian.PutUint64(b[16:], s.length)
+		binary.LittleEndian.PutUint64(b[24:], circlehash.Hash64(b[:24], w.seed))
+		if _, err := w.bw.Write(b[:]); err != nil {
+			return err
+		}
+	}
+	if err := w.bw.Flush(); err != nil {
+		return err
+	}
+
+	h := header{
+		seed:       w.seed,
+		numRecords: uint64(len(w.slots)),
+		tableOff:   w.offset,
+		homeSlots:  homeSlots,
+		tableSlots: uint64(len(table)),
+		maxProbe:   uint32(maxProbe),
+	}
+	hb := h.marshal()
+
+	tableEnd := w.offset + uint64(len(table))*slotSize
+	if _, err := w.ws.Seek(-int64(tableEnd), io.SeekCurrent); err != nil {
+		return err
+	}
+	if _, err := w.ws.Write(hb[:]); err != nil {
+		return err
+	}
+	if _, err := w.ws.Seek(int64(tableEnd-headerSize), io.SeekCurrent); err != nil {
+		return err
+	}
+	return nil
+}
+
+type header struct {
+	seed       uint64
+	numRecords uint64
+	tableOff   uint64
+	homeSlots  uint64
+	tableSlots uint64
+	maxProbe   uint32
+}
+
+func (h *header) marshal() [headerSize]byte {
+	var b [headerSize]byte
+	copy(b[:], magic[:])
+	b[4] = formatVersion
+	b[5] = algorithmCircleHash64fLinearProbing
+	binary.LittleEndian.PutUint64(b[8:], h.seed)
+	binary.LittleEndian.PutUint64(b[16:], h.numRecords)
+	binary.LittleEndian.PutUint64(b[24:], h.tableOff)
+	binary.LittleEndian.PutUint64(b[32:], h.homeSlots)
+	binary.LittleEndian.PutUint64(b[40:], h.tableSlots)
+	binary.LittleEndian.PutUint32(b[48:], h.maxProbe)
+	binary.LittleEndian.PutUint64(b[56:], circlehash.Hash64(b[:56], 0))
+	return b
+}
+
+func (h *header) unmarshal(b []byte, size int64) error {
+	if len(b) < headerSize || [4]byte{b[0], b[1], b[2], b[3]} != magic {
+		return ErrInvalidData
+	}
+	if b[4] != formatVersion {
+		return fmt.Errorf("cdb: unsupported format version %d", b[4])
+	}
+	if b[5] != algorithmCircleHash64fLinearProbing {
+		return fmt.Errorf("cdb: unsupported algorithm %d", b[5])
+	}
+
+	h.seed = binary.LittleEndian.Uint64(b[8:])
+	// Header checksum uses seed 0 instead of stored seed, so a change to
+	// stored seed can't be canceled by the same change to hashed bytes.
+	if binary.LittleEndian.Uint64(b[56:]) != circlehash.Hash64(b[:56], 0) {
+		return fmt.Errorf("%w: header", ErrChecksum)
+	}
+
+	h.numRecords = binary.LittleEndian.Uint64(b[16:])
+	h.tableOff = binary.LittleEndian.Uint64(b[24:])
+	h.homeSlots = binary.LittleEndian.Uint64(b[32:])
+	h.tableSlots = binary.LittleEndian.Uint64(b[40:])
+	h.maxProbe = binary.LittleEndian.Uint32(b[48:])
+
+	if h.tableOff < headerSize || h.tableOff > uint64(size) {
+		return fmt.Errorf("%w: table offset %d", ErrInvalidData, h.tableOff)
+	}
+	if h.tableSlots != (uint64(size)-h.tableOff)/slotSize || (uint64(size)-h.tableOff)%slotSize != 0 {
+		return fmt.Errorf("%w: table size", ErrInvalidData)
+	}
+	if h.homeSlots == 0 || h.homeSlots > h.tableSlots || h.numRecords > h.tableSlots {
+		return fmt.Errorf("%w: number of slots", ErrInvalidData)
+	}
+	if uint64(h.maxProbe) > h.tableSlots || (h.maxProbe == 0) != (h.numRecords == 0) {
+		return fmt.Errorf("%w: max probe length %d", ErrInvalidData, h.maxProbe)
+	}
+	if h.numRecords > (h.tableOff-headerSize)/(recordHeaderSize+checksumSize) {
+		return fmt.Errorf("%w: number of records %d", ErrInvalidData, h.numRecords)
+	}
+	return nil
+}
+
+// Reader reads a database.  It is safe for concurrent use.
+type Reader struct {
+	r      io.ReaderAt
+	h      header
+	closer io.Closer
+}
+
+// NewReader returns a Reader for database of size bytes in r.
+// For example, r can be a *bytes.Reader of memory-mapped file.
+func NewReader(r io.ReaderAt, size int64) (*Reader, error) {
+	if size < headerSize {
+		return nil, ErrInvalidData
+	}
+	var b [headerSize]byte
+	if err := readAt(r, b[:], 0); err != nil {
+		return nil, err
+	}
+
+	rd := &Reader{r: r}
+	if err := rd.h.unmarshal(b[:], size); err != nil {
+		return nil, err
+	}
+	return rd, nil
+}
+
+// readAt reads len(b) bytes at offset.  It returns io.ErrUnexpectedEOF
+// if r has fewer bytes.
+func readAt(r io.ReaderAt, b []byte, offset uint64) error {
+	n, err := r.ReadAt(b, int64(offset))
+	if n == len(b) {
+		return nil
+	}
+	if err == nil || err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// Open opens database file name for reading.
+func Open(name string) (*Reader, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	rd, err := NewReader(f, fi.Size())
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	rd.closer = f
+	return rd, nil
+}
+
+// Close closes file opened by Open.  It does nothing for Reader returned
+// by NewReader.
+func (rd *Reader) Close() error {
+	if rd.closer == nil {
+		return nil
+	}
+	return rd.closer.Close()
+}
+
+// Len returns number of records.
+func (rd *Reader) Len() int {
+	return int(rd.h.numRecords)
+}
+
+// Seed returns seed of database.
+func (rd *Reader) Seed() uint64 {
+	return rd.h.seed
+}
+
+// Get returns value of key.  It returns ErrNotFound if key isn't in database.
+func (rd *Reader) Get(key []byte) ([]byte, error) {
+	if rd.h.numRecords == 0 {
+		return nil, ErrNotFound
+	}
+
+	h := circlehash.Hash64(key, rd.h.seed)
+	home, _ := bits.Mul64(h, rd.h.homeSlots)
+
+	n := uint64(rd.h.maxProbe)
+	if n > rd.h.tableSlots-home {
+		n = rd.h.tableSlots - home
+	}
+	buf := make([]byte, n*slotSize)
+	if err := readAt(rd.r, buf, rd.h.tableOff+home*slotSize); err != nil {
+		return nil, err
+	}
+
+	for i := uint64(0); i < n; i++ {
+		b := buf[i*slotSize : (i+1)*slotSize]
+		if binary.LittleEndian.Uint64(b[24:]) != circlehash.Hash64(b[:24], rd.h.seed) {
+			return nil, fmt.Errorf("%w: slot %d", ErrChecksum, home+i)
+		}
+		s := slot{
+			hash:   binary.LittleEndian.Uint64(b),
+			offset: binary.LittleEndian.Uint64(b[8:]),
+			length: binary.LittleEndian.Uint64(b[16:]),
+		}
+		if s.offset == 0 {
+			break
+		}
+		if s.hash != h {
+			continue
+		}
+
+		k, v, err := rd.readRecord(s.offset, s.length)
+		if err != nil {
+			return nil, err
+		}
+		if string(k) == string(key) {
+			return v, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// GetString returns value of key.  It returns ErrNotFound if key isn't in
+// database.
+func (rd *Reader) GetString(key string) ([]byte, error) {
+	return rd.Get([]byte(key))
+}
+
+// readRecord reads and verifies record of length bytes at offset.
+func (rd *Reader) readRecord(offset, length uint64) ([]byte, []byte, error) {
+	if offset < headerSize || offset > rd.h.tableOff || length > rd.h.tableOff-offset ||
+		length < recordHeaderSize+checksumSize || length > math.MaxInt {
+		return nil, nil, fmt.Errorf("%w: record at offset %d", ErrInvalidData, offset)
+	}
+
+	b := make([]byte, length)
+	if err := readAt(rd.r, b, offset); err != nil {
+		return nil, nil, err
+	}
+
+	keyLen := uint64(binary.LittleEndian.Uint32(b))
+	valueLen := uint64(binary.LittleEndian.Uint32(b[4:]))
+	if recordHeaderSize+keyLen+valueLen+checksumSize != length {
+		return nil, nil, fmt.Errorf("%w: record at offset %d", ErrInvalidData, offset)
+	}
+
+	end := length - checksumSize
+	if binary.LittleEndian.Uint64(b[end:]) != circlehash.Hash64(b[:end], rd.h.seed) {
+		return nil, nil, fmt.Errorf("%w: record at offset %d", ErrChecksum, offset)
+	}
+
+	key := b[recordHeaderSize : recordHeaderSize+keyLen : recordHeaderSize+keyLen]
+	value := b[recordHeaderSize+keyLen : end : end]
+	return key, value, nil
+}
+
+// ForEach calls fn with key and value of each record in order records
+// were added.  If fn returns an error, ForEach stops and returns it.
+// Key and value passed to fn can be retained.
+func (rd *Reader) ForEach(fn func(key, value []byte) error) error {
+	offset := uint64(headerSize)
+	for i := uint64(0); i < rd.h.numRecords; i++ {
+		if rd.h.tableOff-offset < recordHeaderSize {
+			return fmt.Errorf("%w: record at offset %d", ErrInvalidData, offset)
+		}
+		var b [recordHeaderSize]byte
+		if err := readAt(rd.r, b[:], offset); err != nil {
+			return err
+		}
+		length := recordHeaderSize + uint64(binary.LittleEndian.Uint32(b[:])) +
+			uint64(binary.LittleEndian.Uint32(b[4:])) + checksumSize
+
+		key, value, err := rd.readRecord(offset, length)
+		if err != nil {
+			return err
+		}
+		if err := fn(key, value); err != nil {
+			return err
+		}
+		offset += length
+	}
+	if offset != rd.h.tableOff {
+		return fmt.Errorf("%w: records end at offset %d, not table offset %d", ErrInvalidData, offset, rd.h.tableOff)
+	}
+	return nil
+}
@@ -0,0 +1,421 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/fxamacker/circlehash"
+)
+
+const testSeed = uint64(0x9E3779B97F4A7C15)
+
+// memFile is an in-memory io.WriteSeeker.
+type memFile struct {
+	data []byte
+	off  int64
+}
+
+func (f *memFile) Write(b []byte) (int, error) {
+	if end := f.off + int64(len(b)); end > int64(len(f.data)) {
+		f.data = append(f.data, make([]byte, end-int64(len(f.data)))...)
+	}
+	n := copy(f.data[f.off:], b)
+	f.off += int64(n)
+	return n, nil
+}
+
+func (f *memFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += f.off
+	case io.SeekEnd:
+		offset += int64(len(f.data))
+	}
+	if offset < 0 {
+		return 0, errors.New("negative offset")
+	}
+	f.off = offset
+	return offset, nil
+}
+
+// countingReaderAt counts ReadAt calls.
+type countingReaderAt struct {
+	r     io.ReaderAt
+	reads int
+}
+
+func (c *countingReaderAt) ReadAt(b []byte, off int64) (int, error) {
+	c.reads++
+	return c.r.ReadAt(b, off)
+}
+
+func testRecords(n int) [][2]string {
+	records := make([][2]string, n)
+	for i := range records {
+		records[i] = [2]string{"key" + strconv.Itoa(i), "value" + strconv.Itoa(i*i)}
+	}
+	return records
+}
+
+func writeTestDB(t testing.TB, records [][2]string) []byte {
+	t.Helper()
+	f := &memFile{}
+	w, err := NewWriter(f, testSeed)
+	if err != nil {
+		t.Fatalf("NewWriter() returned error %v", err)
+	}
+	for _, r := range records {
+		if err := w.PutString(r[0], r[1]); err != nil {
+			t.Fatalf("PutString() returned error %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() returned error %v", err)
+	}
+	return f.data
+}
+
+func newTestReader(t testing.TB, data []byte) *Reader {
+	t.Helper()
+	rd, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("NewReader() returned error %v", err)
+	}
+	return rd
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 10, 10000} {
+		t.Run(strconv.Itoa(n), func(t *testing.T) {
+			records := testRecords(n)
+			rd := newTestReader(t, writeTestDB(t, records))
+
+			if rd.Len() != n || rd.Seed() != testSeed {
+				t.Errorf("Len(), Seed() = %d, 0x%x; want %d, 0x%x", rd.Len(), rd.Seed(), n, testSeed)
+			}
+
+			for _, r := range records {
+				v, err := rd.GetString(r[0])
+				if err != nil {
+					t.Fatalf("GetString(%q) returned error %v", r[0], err)
+				}
+				if string(v) != r[1] {
+					t.Fatalf("GetString(%q) = %q; want %q", r[0], v, r[1])
+				}
+			}
+
+			for i := 0; i < 100; i++ {
+				key := "missing" + strconv.Itoa(i)
+				if _, err := rd.GetString(key); !errors.Is(err, ErrNotFound) {
+					t.Fatalf("GetString(%q) returned error %v; want %v", key, err, ErrNotFound)
+				}
+			}
+
+			i := 0
+			err := rd.ForEach(func(key, value []byte) error {
+				if string(key) != records[i][0] || string(value) != records[i][1] {
+					t.Fatalf("ForEach() record %d = %q, %q; want %q, %q", i, key, value, records[i][0], records[i][1])
+				}
+				i++
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("ForEach() returned error %v", err)
+			}
+			if i != n {
+				t.Errorf("ForEach() returned %d records; want %d", i, n)
+			}
+		})
+	}
+}
+
+func TestEmptyKeyAndValue(t *testing.T) {
+	rd := newTestReader(t, writeTestDB(t, [][2]string{{"", "empty key"}, {"empty value", ""}}))
+
+	if v, err := rd.GetString(""); err != nil || string(v) != "empty key" {
+		t.Errorf("GetString(\"\") = %q, %v; want \"empty key\", nil", v, err)
+	}
+	if v, err := rd.GetString("empty value"); err != nil || len(v) != 0 {
+		t.Errorf("GetString(\"empty value\") = %q, %v; want \"\", nil", v, err)
+	}
+}
+
+func TestDuplicateKeys(t *testing.T) {
+	rd := newTestReader(t, writeTestDB(t, [][2]string{{"a", "1"}, {"b", "2"}, {"a", "3"}}))
+
+	if v, err := rd.GetString("a"); err != nil || string(v) != "1" {
+		t.Errorf("GetString(\"a\") = %q, %v; want \"1\", nil", v, err)
+	}
+
+	var values []string
+	err := rd.ForEach(func(key, value []byte) error {
+		if string(key) == "a" {
+			values = append(values, string(value))
+		}
+		return nil
+	})
+	if err != nil || len(values) != 2 || values[0] != "1" || values[1] != "3" {
+		t.Errorf("ForEach() returned values %q, %v; want [\"1\" \"3\"], nil", values, err)
+	}
+}
+
+func TestGetTwoReads(t *testing.T) {
+	data := writeTestDB(t, testRecords(10000))
+
+	cr := &countingReaderAt{r: bytes.NewReader(data)}
+	rd, err := NewReader(cr, int64(len(data)))
+	if err != nil {
+		t.Fatalf("NewReader() returned error %v", err)
+	}
+
+	for _, r := range testRecords(10000) {
+		cr.reads = 0
+		if _, err := rd.GetString(r[0]); err != nil {
+			t.Fatalf("GetString(%q) returned error %v", r[0], err)
+		}
+		if cr.reads != 2 {
+			t.Fatalf("GetString(%q) read %d times; want 2", r[0], cr.reads)
+		}
+	}
+}
+
+func TestForEachError(t *testing.T) {
+	rd := newTestReader(t, writeTestDB(t, testRecords(10)))
+
+	errStop := errors.New("stop")
+	count := 0
+	err := rd.ForEach(func(key, value []byte) error {
+		count++
+		if count == 3 {
+			return errStop
+		}
+		return nil
+	})
+	if !errors.Is(err, errStop) || count != 3 {
+		t.Errorf("ForEach() returned %v after %d records; want %v after 3", err, count, errStop)
+	}
+}
+
+func TestWriterClosed(t *testing.T) {
+	w, err := NewWriter(&memFile{}, testSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() returned error %v", err)
+	}
+	if err := w.PutString("a", "b"); !errors.Is(err, ErrClosed) {
+		t.Errorf("PutString() after Close() returned error %v; want %v", err, ErrClosed)
+	}
+	if err := w.Close(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Close() after Close() returned error %v; want %v", err, ErrClosed)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "test.cdb")
+
+	f, err := os.Create(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := NewWriter(f, testSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records := testRecords(100)
+	for _, r := range records {
+		if err := w.PutString(r[0], r[1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	rd, err := Open(name)
+	if err != nil {
+		t.Fatalf("Open() returned error %v", err)
+	}
+	defer rd.Close()
+
+	for _, r := range records {
+		if v, err := rd.GetString(r[0]); err != nil || string(v) != r[1] {
+			t.Fatalf("GetString(%q) = %q, %v; want %q, nil", r[0], v, err, r[1])
+		}
+	}
+
+	if _, err := Open(filepath.Join(t.TempDir(), "missing.cdb")); err == nil {
+		t.Error("Open() of missing file didn't return error")
+	}
+}
+
+func TestNewReaderInvalid(t *testing.T) {
+	valid := writeTestDB(t, testRecords(10))
+
+	modify := func(fn func(b []byte)) []byte {
+		b := append([]byte(nil), valid...)
+		fn(b)
+		return b
+	}
+
+	testCases := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{"empty", nil, ErrInvalidData},
+		{"short header", valid[:headerSize-1], ErrInvalidData},
+		{"bad magic", modify(func(b []byte) { b[0] = 'X' }), ErrInvalidData},
+		{"bad version", modify(func(b []byte) { b[4] = 2 }), nil},
+		{"bad algorithm", modify(func(b []byte) { b[5] = 0 }), nil},
+		{"bad seed", modify(func(b []byte) { b[8]++ }), ErrChecksum},
+		{"bad number of records", modify(func(b []byte) { b[16]++ }), ErrChecksum},
+		{"bad header checksum", modify(func(b []byte) { b[56]++ }), ErrChecksum},
+		{"truncated", valid[:len(valid)-1], ErrInvalidData},
+		{"extra data", append(append([]byte(nil), valid...), make([]byte, slotSize)...), ErrInvalidData},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewReader(bytes.NewReader(tc.data), int64(len(tc.data)))
+			if err == nil {
+				t.Fatal("NewReader() didn't return error")
+			}
+			if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+				t.Errorf("NewReader() returned error %v; want %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestCorruptedRecord(t *testing.T) {
+	records := testRecords(10)
+	data := writeTestDB(t, records)
+
+	// Corrupt value of first record.
+	data[headerSize+recordHeaderSize+len(records[0][0])]++
+
+	rd := newTestReader(t, data)
+	if _, err := rd.GetString(records[0][0]); !errors.Is(err, ErrChecksum) {
+		t.Errorf("GetString() of corrupted record returned error %v; want %v", err, ErrChecksum)
+	}
+	if v, err := rd.GetString(records[1][0]); err != nil || string(v) != records[1][1] {
+		t.Errorf("GetString() of valid record = %q, %v; want %q, nil", v, err, records[1][1])
+	}
+	if err := rd.ForEach(func(key, value []byte) error { return nil }); !errors.Is(err, ErrChecksum) {
+		t.Errorf("ForEach() returned error %v; want %v", err, ErrChecksum)
+	}
+}
+
+func TestCorruptedTable(t *testing.T) {
+	records := testRecords(10)
+	data := writeTestDB(t, records)
+	rd := newTestReader(t, data)
+
+	// Point every slot to an invalid offset, with valid slot checksum.
+	for off := rd.h.tableOff; off < uint64(len(data)); off += slotSize {
+		if data[off+8] != 0 || data[off+9] != 0 {
+			data[off+15] = 0xff
+			binary.LittleEndian.PutUint64(data[off+24:], circlehash.Hash64(data[off:off+24], rd.h.seed))
+		}
+	}
+	for _, r := range records {
+		if _, err := rd.GetString(r[0]); !errors.Is(err, ErrInvalidData) {
+			t.Fatalf("GetString(%q) with corrupted table returned error %v; want %v", r[0], err, ErrInvalidData)
+		}
+	}
+}
+
+func TestCorruptedSlot(t *testing.T) {
+	records := testRecords(10)
+	data := writeTestDB(t, records)
+	rd := newTestReader(t, data)
+
+	testCases := []struct {
+		name   string
+		offset uint64 // offset of modified byte in slot
+	}{
+		{"digest", 0},
+		{"record offset", 8},
+		{"record length", 16},
+		{"checksum", 24},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, r := range records {
+				// Find slot of key.
+				h := circlehash.Hash64([]byte(r[0]), rd.h.seed)
+				off := rd.h.tableOff
+				for binary.LittleEndian.Uint64(data[off:]) != h {
+					off += slotSize
+				}
+
+				data[off+tc.offset] ^= 1
+				_, err := rd.GetString(r[0])
+				data[off+tc.offset] ^= 1
+
+				if !errors.Is(err, ErrChecksum) {
+					t.Fatalf("GetString(%q) with corrupted slot returned error %v; want %v", r[0], err, ErrChecksum)
+				}
+			}
+		})
+	}
+
+	// Corrupted empty slots are detected by lookups of missing keys
+	// instead of ending the probe.
+	for off := rd.h.tableOff; off < uint64(len(data)); off += slotSize {
+		if binary.LittleEndian.Uint64(data[off+8:]) == 0 {
+			data[off] ^= 1
+		}
+	}
+	detected := 0
+	for i := 0; i < 100; i++ {
+		key := "missing" + strconv.Itoa(i)
+		_, err := rd.GetString(key)
+		switch {
+		case errors.Is(err, ErrChecksum):
+			detected++
+		case !errors.Is(err, ErrNotFound):
+			t.Fatalf("GetString(%q) with corrupted empty slots returned error %v; want %v or %v", key, err, ErrChecksum, ErrNotFound)
+		}
+	}
+	if detected == 0 {
+		t.Errorf("GetString() didn't detect corrupted empty slots")
+	}
+}
+
+func BenchmarkGet(b *testing.B) {
+	records := testRecords(100000)
+	data := writeTestDB(b, records)
+	rd := newTestReader(b, data)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = rd.GetString(records[i%len(records)][0])
+	}
+}
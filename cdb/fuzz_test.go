@@ -0,0 +1,82 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.18
+// +build go1.18
+
+package cdb
+
+import (
+	"bytes"
+	"testing"
+)
+
+// FuzzReader verifies that Reader returns errors, not panics, for
+// corrupted databases.
+func FuzzReader(f *testing.F) {
+	f.Add(writeTestDB(f, nil))
+	f.Add(writeTestDB(f, testRecords(1)))
+	f.Add(writeTestDB(f, testRecords(10)))
+	f.Add(writeTestDB(f, [][2]string{{"", ""}, {"a", "1"}, {"a", "2"}}))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		rd, err := NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return
+		}
+		for _, r := range testRecords(10) {
+			_, _ = rd.GetString(r[0])
+		}
+		_, _ = rd.GetString("")
+		_ = rd.ForEach(func(key, value []byte) error {
+			_, _ = rd.Get(key)
+			return nil
+		})
+	})
+}
+
+// FuzzRoundTrip verifies that records written by Writer are read by Reader.
+func FuzzRoundTrip(f *testing.F) {
+	f.Add([]byte("key"), []byte("value"), []byte("key2"), []byte(""))
+	f.Add([]byte(""), []byte(""), []byte(""), []byte("x"))
+
+	f.Fuzz(func(t *testing.T, k1, v1, k2, v2 []byte) {
+		w := &memFile{}
+		cw, err := NewWriter(w, testSeed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cw.Put(k1, v1); err != nil {
+			t.Fatal(err)
+		}
+		if err := cw.Put(k2, v2); err != nil {
+			t.Fatal(err)
+		}
+		if err := cw.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		rd := newTestReader(t, w.data)
+		if v, err := rd.Get(k1); err != nil || !bytes.Equal(v, v1) {
+			t.Errorf("Get(%q) = %q, %v; want %q, nil", k1, v, err, v1)
+		}
+		want := v2
+		if bytes.Equal(k1, k2) {
+			want = v1
+		}
+		if v, err := rd.Get(k2); err != nil || !bytes.Equal(v, want) {
+			t.Errorf("Get(%q) = %q, %v; want %q, nil", k2, v, err, want)
+		}
+	})
+}
@@ -0,0 +1,417 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// basicType describes how a predeclared type is hashed by a typed helper.
+type basicType struct {
+	helper   string // typed helper in circlehash package
+	convType string // type of helper parameter
+}
+
+var basicTypes = map[string]basicType{
+	"bool":       {"HashValueBool", "bool"},
+	"string":     {"HashValueString", "string"},
+	"int":        {"HashValueInt", "int64"},
+	"int8":       {"HashValueInt", "int64"},
+	"int16":      {"HashValueInt", "int64"},
+	"int32":      {"HashValueInt", "int64"},
+	"int64":      {"HashValueInt", "int64"},
+	"rune":       {"HashValueInt", "int64"},
+	"uint":       {"HashValueUint", "uint64"},
+	"uint8":      {"HashValueUint", "uint64"},
+	"uint16":     {"HashValueUint", "uint64"},
+	"uint32":     {"HashValueUint", "uint64"},
+	"uint64":     {"HashValueUint", "uint64"},
+	"uintptr":    {"HashValueUint", "uint64"},
+	"byte":       {"HashValueUint", "uint64"},
+	"float32":    {"HashValueFloat", "float64"},
+	"float64":    {"HashValueFloat", "float64"},
+	"complex64":  {"HashValueComplex", "complex128"},
+	"complex128": {"HashValueComplex", "complex128"},
+}
+
+// generator generates CircleHash64 methods for struct types declared
+// in one package.  Generated methods produce the same digests as
+// circlehash.HashValue.
+type generator struct {
+	pkgName    string
+	types      map[string]*ast.TypeSpec
+	marshalers map[string]string // type name to its marshal method name
+
+	pending []string        // struct types waiting to be generated
+	queued  map[string]bool // struct types generated or pending
+
+	current string              // struct type being generated
+	refs    map[string][]string // struct types referenced by each struct type
+}
+
+// generate returns formatted Go source containing CircleHash64 methods for
+// typeNames and for struct types they reference.  args is recorded in the
+// generated header.
+func generate(pkgName string, files []*ast.File, typeNames []string, args string) ([]byte, error) {
+	g := &generator{
+		pkgName:    pkgName,
+		types:      make(map[string]*ast.TypeSpec),
+		marshalers: make(map[string]string),
+		queued:     make(map[string]bool),
+		refs:       make(map[string][]string),
+	}
+
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			switch d := decl.(type) {
+			case *ast.GenDecl:
+				if d.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range d.Specs {
+					ts := spec.(*ast.TypeSpec)
+					g.types[ts.Name.Name] = ts
+				}
+
+			case *ast.FuncDecl:
+				if d.Recv == nil || len(d.Recv.List) == 0 {
+					continue
+				}
+				if name := d.Name.Name; name == "MarshalBinary" || name == "MarshalText" {
+					g.marshalers[embeddedName(d.Recv.List[0].Type)] = name
+				}
+			}
+		}
+	}
+
+	for _, name := range typeNames {
+		ts, ok := g.types[name]
+		if !ok {
+			return nil, fmt.Errorf("type %s not found in package %s", name, pkgName)
+		}
+		if _, ok := ts.Type.(*ast.StructType); !ok {
+			return nil, fmt.Errorf("type %s is not a struct type", name)
+		}
+		g.queue(name)
+	}
+
+	var names []string
+	exprs := make(map[string]string)
+	for len(g.pending) > 0 {
+		name := g.pending[0]
+		g.pending = g.pending[1:]
+
+		expr, err := g.methodExpr(name)
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		exprs[name] = expr
+	}
+
+	var body bytes.Buffer
+	for _, name := range names {
+		g.writeMethod(&body, name, exprs[name])
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by \"circlehash-gen %s\"; DO NOT EDIT.\n\n", args)
+	fmt.Fprintf(&buf, "package %s\n\n", pkgName)
+	fmt.Fprintf(&buf, "import \"github.com/fxamacker/circlehash\"\n")
+	buf.Write(body.Bytes())
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return src, nil
+}
+
+// queue adds struct type name to be generated if it isn't already, and
+// records that it's referenced by struct type being generated.
+func (g *generator) queue(name string) {
+	if g.current != "" {
+		g.refs[g.current] = append(g.refs[g.current], name)
+	}
+	if !g.queued[name] {
+		g.queued[name] = true
+		g.pending = append(g.pending, name)
+	}
+}
+
+// methodExpr returns expression for digest of struct type name.
+func (g *generator) methodExpr(name string) (string, error) {
+	if method, ok := g.marshalers[name]; ok {
+		// HashValue hashes structs with unexported fields by marshaled
+		// bytes, which can't be generated.
+		st := g.types[name].Type.(*ast.StructType)
+		for _, field := range st.Fields.List {
+			for _, n := range field.Names {
+				if !ast.IsExported(n.Name) {
+					return "", fmt.Errorf("type %s: unsupported type with unexported fields and %s method", name, method)
+				}
+			}
+		}
+	}
+
+	g.current = name
+	defer func() { g.current = "" }()
+
+	expr, err := g.structExpr("x", g.types[name].Type.(*ast.StructType), 0)
+	if err != nil {
+		return "", fmt.Errorf("type %s: %w", name, err)
+	}
+	return expr, nil
+}
+
+func (g *generator) writeMethod(w *bytes.Buffer, name, expr string) {
+	fmt.Fprintf(w, "\n// CircleHash64 returns a 64-bit digest of x.\n")
+	fmt.Fprintf(w, "// Digest is the same as circlehash.HashValue(x, seed).\n")
+	if g.recursive(name) {
+		fmt.Fprintf(w, "// x must not contain pointer cycles, which cause infinite recursion\n")
+		fmt.Fprintf(w, "// (circlehash.HashValue returns circlehash.ErrCycle for them).\n")
+	}
+	fmt.Fprintf(w, "func (x *%s) CircleHash64(seed uint64) uint64 {\n", name)
+	fmt.Fprintf(w, "if x == nil {\nreturn circlehash.HashValueNil(seed)\n}\n")
+	fmt.Fprintf(w, "return %s\n}\n", expr)
+}
+
+// recursive returns true if struct type name references itself directly
+// or through other struct types.
+func (g *generator) recursive(name string) bool {
+	seen := make(map[string]bool)
+	stack := append([]string(nil), g.refs[name]...)
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if n == name {
+			return true
+		}
+		if !seen[n] {
+			seen[n] = true
+			stack = append(stack, g.refs[n]...)
+		}
+	}
+	return false
+}
+
+// structExpr returns expression for digest of struct v.
+func (g *generator) structExpr(v string, st *ast.StructType, depth int) (string, error) {
+	var fields []string
+	unexported := false
+
+	for _, field := range st.Fields.List {
+		if field.Tag != nil {
+			tag, err := strconv.Unquote(field.Tag.Value)
+			if err != nil {
+				return "", fmt.Errorf("bad struct tag %s: %w", field.Tag.Value, err)
+			}
+			if reflect.StructTag(tag).Get("circlehash") == "-" {
+				continue
+			}
+		}
+
+		names := field.Names
+		if len(names) == 0 {
+			// Embedded field is named after its type.
+			names = []*ast.Ident{ast.NewIdent(embeddedName(field.Type))}
+		}
+
+		for _, n := range names {
+			if !ast.IsExported(n.Name) {
+				unexported = true
+				continue
+			}
+			expr, err := g.digestExpr(v+"."+n.Name, field.Type, depth, false)
+			if err != nil {
+				return "", fmt.Errorf("field %s: %w", n.Name, err)
+			}
+			fields = append(fields, expr)
+		}
+	}
+
+	if len(fields) == 0 {
+		if unexported {
+			// HashValue returns error for struct with only unexported fields.
+			return "", errors.New("unsupported type with only unexported fields")
+		}
+		return "circlehash.HashValueStruct(seed)", nil
+	}
+	return "circlehash.HashValueStruct(seed,\n" + strings.Join(fields, ",\n") + ",\n)", nil
+}
+
+// digestExpr returns expression for digest of v with type typ.
+// named is true if typ is the underlying type of a named type.
+func (g *generator) digestExpr(v string, typ ast.Expr, depth int, named bool) (string, error) {
+	switch t := typ.(type) {
+	case *ast.Ident:
+		return g.identExpr(v, t, depth, named)
+
+	case *ast.ParenExpr:
+		return g.digestExpr(v, t.X, depth, named)
+
+	case *ast.StructType:
+		return g.structExpr(v, t, depth)
+
+	case *ast.StarExpr:
+		if ident, ok := t.X.(*ast.Ident); ok && g.isStruct(ident.Name) {
+			// Generated methods handle nil receiver.
+			g.queue(ident.Name)
+			return v + ".CircleHash64(seed)", nil
+		}
+		elem, err := g.digestExpr("(*"+v+")", t.X, depth, false)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("func() uint64 {\nif %s == nil {\nreturn circlehash.HashValueNil(seed)\n}\nreturn %s\n}()", v, elem), nil
+
+	case *ast.ArrayType:
+		if t.Len == nil && g.isByte(t.Elt) {
+			return fmt.Sprintf("circlehash.HashValueBytes(%s, seed)", unparen(v)), nil
+		}
+		if t.Len == nil && g.isByteKind(t.Elt) {
+			// HashValue hashes this like []byte but it isn't assignable to []byte.
+			return "", fmt.Errorf("unsupported type %s", typeString(typ))
+		}
+		i := fmt.Sprintf("i%d", depth)
+		elem, err := g.digestExpr(v+"["+i+"]", t.Elt, depth+1, false)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("circlehash.HashValueSlice(seed, len(%s), func(%s int) uint64 {\nreturn %s\n})", v, i, elem), nil
+
+	case *ast.MapType:
+		k := fmt.Sprintf("k%d", depth)
+		e := fmt.Sprintf("v%d", depth)
+		sum := fmt.Sprintf("sum%d", depth)
+		keyExpr, err := g.digestExpr(k, t.Key, depth+1, false)
+		if err != nil {
+			return "", err
+		}
+		valueExpr, err := g.digestExpr(e, t.Value, depth+1, false)
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("func() uint64 {\nvar %s uint64\nfor %s, %s := range %s {\n%s += circlehash.Combine64(seed, %s, %s)\n}\nreturn circlehash.HashValueMap(seed, len(%s), %s)\n}()",
+			sum, k, e, v, sum, keyExpr, valueExpr, v, sum), nil
+	}
+
+	return "", fmt.Errorf("unsupported type %s", typeString(typ))
+}
+
+func (g *generator) identExpr(v string, t *ast.Ident, depth int, named bool) (string, error) {
+	ts, declared := g.types[t.Name]
+	if !declared {
+		basic, ok := basicTypes[t.Name]
+		if !ok {
+			return "", fmt.Errorf("unsupported type %s", t.Name)
+		}
+		v = unparen(v)
+		if named || basic.convType != t.Name {
+			v = basic.convType + "(" + v + ")"
+		}
+		return fmt.Sprintf("circlehash.%s(%s, seed)", basic.helper, v), nil
+	}
+
+	if ts.Assign.IsValid() {
+		// Type alias is the same type.
+		return g.digestExpr(v, ts.Type, depth, named)
+	}
+
+	if _, ok := ts.Type.(*ast.StructType); ok {
+		g.queue(t.Name)
+		return v + ".CircleHash64(seed)", nil
+	}
+
+	if at, ok := ts.Type.(*ast.ArrayType); ok && at.Len == nil && g.isByte(at.Elt) {
+		// Named byte slice is assignable to []byte.
+		return fmt.Sprintf("circlehash.HashValueBytes(%s, seed)", unparen(v)), nil
+	}
+
+	return g.digestExpr(v, ts.Type, depth, true)
+}
+
+// isStruct returns true if name is a struct type declared in package.
+func (g *generator) isStruct(name string) bool {
+	ts, ok := g.types[name]
+	if !ok || ts.Assign.IsValid() {
+		return false
+	}
+	_, ok = ts.Type.(*ast.StructType)
+	return ok
+}
+
+// isByte returns true if typ is byte or uint8.
+func (g *generator) isByte(typ ast.Expr) bool {
+	ident, ok := typ.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	if ts, ok := g.types[ident.Name]; ok {
+		if ts.Assign.IsValid() {
+			return g.isByte(ts.Type)
+		}
+		return false
+	}
+	return ident.Name == "byte" || ident.Name == "uint8"
+}
+
+// isByteKind returns true if typ is byte, uint8, or a named type with underlying type uint8.
+func (g *generator) isByteKind(typ ast.Expr) bool {
+	ident, ok := typ.(*ast.Ident)
+	if !ok {
+		return false
+	}
+	if ts, ok := g.types[ident.Name]; ok {
+		return g.isByteKind(ts.Type)
+	}
+	return ident.Name == "byte" || ident.Name == "uint8"
+}
+
+// unparen removes parentheses around dereferenced pointer v.
+func unparen(v string) string {
+	if strings.HasPrefix(v, "(*") && strings.HasSuffix(v, ")") && strings.Count(v, "(") == 1 {
+		return v[1 : len(v)-1]
+	}
+	return v
+}
+
+func embeddedName(typ ast.Expr) string {
+	switch t := typ.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	}
+	return ""
+}
+
+func typeString(typ ast.Expr) string {
+	var buf bytes.Buffer
+	if err := format.Node(&buf, token.NewFileSet(), typ); err != nil {
+		return fmt.Sprintf("%T", typ)
+	}
+	return buf.String()
+}
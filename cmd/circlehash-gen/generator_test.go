@@ -0,0 +1,220 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// TestGenerateGolden verifies generated code in internal/example is up to date.
+// Tests in internal/example verify generated code matches circlehash.HashValue.
+func TestGenerateGolden(t *testing.T) {
+	dir := filepath.Join("internal", "example")
+	goldenFile := filepath.Join(dir, "config_circlehash.go")
+
+	pkgName, files, err := parsePackage(dir)
+	if err != nil {
+		t.Fatalf("parsePackage(%s) returned error %v", dir, err)
+	}
+
+	got, err := generate(pkgName, files, []string{"Config", "Empty", "List"}, "-type=Config,Empty,List")
+	if err != nil {
+		t.Fatalf("generate() returned error %v", err)
+	}
+
+	if *update {
+		if err := os.WriteFile(goldenFile, got, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(goldenFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("generate() doesn't match %s (run go test -update to update it):\n%s", goldenFile, got)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		src     string
+		types   []string
+		wantErr string
+	}{
+		{
+			"type not found",
+			"type T struct{}",
+			[]string{"Missing"},
+			"type Missing not found",
+		},
+		{
+			"not a struct",
+			"type T int",
+			[]string{"T"},
+			"type T is not a struct type",
+		},
+		{
+			"interface field",
+			"type T struct{ F interface{} }",
+			[]string{"T"},
+			"field F: unsupported type interface{}",
+		},
+		{
+			"func field",
+			"type T struct{ F func() }",
+			[]string{"T"},
+			"field F: unsupported type func()",
+		},
+		{
+			"chan field",
+			"type T struct{ F []chan int }",
+			[]string{"T"},
+			"field F: unsupported type chan int",
+		},
+		{
+			"imported type",
+			"import \"time\"\ntype T struct{ F time.Time }",
+			[]string{"T"},
+			"field F: unsupported type time.Time",
+		},
+		{
+			"named byte slice element",
+			"type B uint8\ntype T struct{ F []B }",
+			[]string{"T"},
+			"field F: unsupported type []B",
+		},
+		{
+			"nested struct with unsupported field",
+			"type Inner struct{ F chan int }\ntype T struct{ I Inner }",
+			[]string{"T"},
+			"type Inner: field F: unsupported type chan int",
+		},
+		{
+			"only unexported fields",
+			"type T struct{ a, b int }",
+			[]string{"T"},
+			"type T: unsupported type with only unexported fields",
+		},
+		{
+			"nested struct with only unexported fields",
+			"type T struct{ F struct{ a int } }",
+			[]string{"T"},
+			"field F: unsupported type with only unexported fields",
+		},
+		{
+			"unexported fields and MarshalBinary",
+			"type T struct{ A int; b int }\nfunc (T) MarshalBinary() ([]byte, error) { return nil, nil }",
+			[]string{"T"},
+			"type T: unsupported type with unexported fields and MarshalBinary method",
+		},
+		{
+			"unexported fields and MarshalText",
+			"type Inner struct{ a int }\nfunc (*Inner) MarshalText() ([]byte, error) { return nil, nil }\ntype T struct{ I *Inner }",
+			[]string{"T"},
+			"type Inner: unsupported type with unexported fields and MarshalText method",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			files := parseSource(t, "package p\n"+tc.src)
+
+			_, err := generate("p", files, tc.types, "")
+			if err == nil {
+				t.Fatalf("generate() didn't return error")
+			}
+			if !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("generate() returned error %q; want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateSkippedFields(t *testing.T) {
+	files := parseSource(t, "package p\ntype T struct{\nA int\nb int\nC func() `circlehash:\"-\"`\n}")
+
+	got, err := generate("p", files, []string{"T"}, "-type=T")
+	if err != nil {
+		t.Fatalf("generate() returned error %v", err)
+	}
+
+	src := string(got)
+	if !strings.Contains(src, "x.A") {
+		t.Errorf("generate() doesn't hash exported field A:\n%s", src)
+	}
+	if strings.Contains(src, "x.b") || strings.Contains(src, "x.C") {
+		t.Errorf("generate() hashes skipped fields:\n%s", src)
+	}
+}
+
+func TestGenerateRecursive(t *testing.T) {
+	const note = "x must not contain pointer cycles"
+
+	files := parseSource(t, `package p
+type Node struct{ Next *Node }
+type A struct{ B []B }
+type B struct{ A *A }
+type Leaf struct{ N int }
+type Tree struct{ Left, Right *Tree; Leaf Leaf }`)
+
+	testCases := []struct {
+		name      string
+		recursive bool
+	}{
+		{"Node", true},
+		{"A", true},
+		{"B", true},
+		{"Leaf", false},
+		{"Tree", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := generate("p", files, []string{tc.name}, "-type="+tc.name)
+			if err != nil {
+				t.Fatalf("generate() returned error %v", err)
+			}
+
+			// Method of tc.name is generated first.
+			src := string(got)
+			method := src[:strings.Index(src, "func (x *"+tc.name+")")]
+			if has := strings.Contains(method, note); has != tc.recursive {
+				t.Errorf("generate() documents pointer cycles = %t; want %t:\n%s", has, tc.recursive, src)
+			}
+		})
+	}
+}
+
+func parseSource(t *testing.T, src string) []*ast.File {
+	f, err := parser.ParseFile(token.NewFileSet(), "src.go", src, 0)
+	if err != nil {
+		t.Fatalf("failed to parse %q: %v", src, err)
+	}
+	return []*ast.File{f}
+}
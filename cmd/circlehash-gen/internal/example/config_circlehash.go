@@ -0,0 +1,100 @@
+// Code generated by "circlehash-gen -type=Config,Empty,List"; DO NOT EDIT.
+
+package example
+
+import "github.com/fxamacker/circlehash"
+
+// CircleHash64 returns a 64-bit digest of x.
+// Digest is the same as circlehash.HashValue(x, seed).
+func (x *Config) CircleHash64(seed uint64) uint64 {
+	if x == nil {
+		return circlehash.HashValueNil(seed)
+	}
+	return circlehash.HashValueStruct(seed,
+		circlehash.HashValueString(x.Name, seed),
+		circlehash.HashValueBool(x.Enabled, seed),
+		circlehash.HashValueInt(int64(x.Level), seed),
+		circlehash.HashValueFloat(float64(x.Ratio), seed),
+		circlehash.HashValueComplex(x.Point, seed),
+		circlehash.HashValueBytes(x.Data, seed),
+		circlehash.HashValueBytes(x.Blob, seed),
+		circlehash.HashValueSlice(seed, len(x.Digest), func(i0 int) uint64 {
+			return circlehash.HashValueUint(uint64(x.Digest[i0]), seed)
+		}),
+		circlehash.HashValueSlice(seed, len(x.Tags), func(i0 int) uint64 {
+			return circlehash.HashValueString(x.Tags[i0], seed)
+		}),
+		circlehash.HashValueSlice(seed, len(x.Matrix), func(i0 int) uint64 {
+			return circlehash.HashValueSlice(seed, len(x.Matrix[i0]), func(i1 int) uint64 {
+				return circlehash.HashValueInt(int64(x.Matrix[i0][i1]), seed)
+			})
+		}),
+		x.Primary.CircleHash64(seed),
+		x.Backup.CircleHash64(seed),
+		circlehash.HashValueSlice(seed, len(x.Replicas), func(i0 int) uint64 {
+			return x.Replicas[i0].CircleHash64(seed)
+		}),
+		func() uint64 {
+			if x.Limit == nil {
+				return circlehash.HashValueNil(seed)
+			}
+			return circlehash.HashValueInt(int64(*x.Limit), seed)
+		}(),
+		func() uint64 {
+			var sum0 uint64
+			for k0, v0 := range x.Labels {
+				sum0 += circlehash.Combine64(seed, circlehash.HashValueString(k0, seed), circlehash.HashValueString(v0, seed))
+			}
+			return circlehash.HashValueMap(seed, len(x.Labels), sum0)
+		}(),
+		func() uint64 {
+			var sum0 uint64
+			for k0, v0 := range x.Weights {
+				sum0 += circlehash.Combine64(seed, k0.CircleHash64(seed), circlehash.HashValueSlice(seed, len(v0), func(i1 int) uint64 {
+					return circlehash.HashValueFloat(v0[i1], seed)
+				}))
+			}
+			return circlehash.HashValueMap(seed, len(x.Weights), sum0)
+		}(),
+		circlehash.HashValueStruct(seed,
+			circlehash.HashValueInt(int64(x.Nested.A), seed),
+			circlehash.HashValueInt(int64(x.Nested.B), seed),
+		),
+		x.Endpoint.CircleHash64(seed),
+	)
+}
+
+// CircleHash64 returns a 64-bit digest of x.
+// Digest is the same as circlehash.HashValue(x, seed).
+func (x *Empty) CircleHash64(seed uint64) uint64 {
+	if x == nil {
+		return circlehash.HashValueNil(seed)
+	}
+	return circlehash.HashValueStruct(seed)
+}
+
+// CircleHash64 returns a 64-bit digest of x.
+// Digest is the same as circlehash.HashValue(x, seed).
+// x must not contain pointer cycles, which cause infinite recursion
+// (circlehash.HashValue returns circlehash.ErrCycle for them).
+func (x *List) CircleHash64(seed uint64) uint64 {
+	if x == nil {
+		return circlehash.HashValueNil(seed)
+	}
+	return circlehash.HashValueStruct(seed,
+		circlehash.HashValueInt(int64(x.Value), seed),
+		x.Next.CircleHash64(seed),
+	)
+}
+
+// CircleHash64 returns a 64-bit digest of x.
+// Digest is the same as circlehash.HashValue(x, seed).
+func (x *Endpoint) CircleHash64(seed uint64) uint64 {
+	if x == nil {
+		return circlehash.HashValueNil(seed)
+	}
+	return circlehash.HashValueStruct(seed,
+		circlehash.HashValueString(x.Host, seed),
+		circlehash.HashValueUint(uint64(x.Port), seed),
+	)
+}
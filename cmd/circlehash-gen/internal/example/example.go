@@ -0,0 +1,74 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package example contains struct types with CircleHash64 methods generated
+// by circlehash-gen.  Generated code is verified to match circlehash.HashValue
+// and is used as golden file by circlehash-gen tests.
+package example
+
+//go:generate go run ../.. -type=Config,Empty,List
+
+// Level is a named integer type.
+type Level int8
+
+// Labels is a named map type.
+type Labels map[string]string
+
+// Blob is a named byte slice type.
+type Blob []byte
+
+// Endpoint is referenced by Config so it also gets a CircleHash64 method.
+type Endpoint struct {
+	Host string
+	Port uint16
+}
+
+// Config contains fields of supported types.
+type Config struct {
+	Name     string
+	Enabled  bool
+	Level    Level
+	Ratio    float32
+	Point    complex128
+	Data     []byte
+	Blob     Blob
+	Digest   [4]byte
+	Tags     []string
+	Matrix   [][]int
+	Primary  Endpoint
+	Backup   *Endpoint
+	Replicas []Endpoint
+	Limit    *int
+	Labels   Labels
+	Weights  map[Endpoint][]float64
+	Nested   struct {
+		A, B int
+	}
+
+	Endpoint // embedded
+
+	Comment string `circlehash:"-"`
+	secret  string
+}
+
+// Empty has no hashed fields.
+type Empty struct {
+	Ignored int `circlehash:"-"`
+}
+
+// List is a recursive type.
+type List struct {
+	Value int
+	Next  *List
+}
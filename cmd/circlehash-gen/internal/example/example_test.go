@@ -0,0 +1,114 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package example
+
+import (
+	"math"
+	"testing"
+
+	"github.com/fxamacker/circlehash"
+)
+
+func TestGeneratedMatchesHashValue(t *testing.T) {
+	limit := 42
+
+	full := Config{
+		Name:     "service",
+		Enabled:  true,
+		Level:    -3,
+		Ratio:    0.25,
+		Point:    complex(1, -1),
+		Data:     []byte("data"),
+		Blob:     Blob("blob"),
+		Digest:   [4]byte{1, 2, 3, 4},
+		Tags:     []string{"a", "b"},
+		Matrix:   [][]int{{1, 2}, {3}, nil},
+		Primary:  Endpoint{"primary", 80},
+		Backup:   &Endpoint{"backup", 8080},
+		Replicas: []Endpoint{{"r1", 1}, {"r2", 2}},
+		Limit:    &limit,
+		Labels:   Labels{"env": "prod", "team": "core"},
+		Weights: map[Endpoint][]float64{
+			{"w1", 1}: {0.5, math.NaN()},
+			{"w2", 2}: {math.Copysign(0, -1)},
+		},
+		Endpoint: Endpoint{"embedded", 443},
+		Comment:  "skipped",
+		secret:   "skipped",
+	}
+	full.Nested.A = 1
+	full.Nested.B = 2
+
+	testCases := []struct {
+		name   string
+		config Config
+	}{
+		{"zero", Config{}},
+		{"full", full},
+	}
+
+	for _, seed := range []uint64{0, 0x9E3779B97F4A7C15} {
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				want, err := circlehash.HashValue(tc.config, seed)
+				if err != nil {
+					t.Fatalf("HashValue() returned error %v", err)
+				}
+
+				if got := tc.config.CircleHash64(seed); got != want {
+					t.Errorf("Config.CircleHash64(0x%x) = 0x%016x; want 0x%016x", seed, got, want)
+				}
+			})
+		}
+
+		if got, want := (*Config)(nil).CircleHash64(seed), circlehash.HashValueNil(seed); got != want {
+			t.Errorf("(*Config)(nil).CircleHash64(0x%x) = 0x%016x; want 0x%016x", seed, got, want)
+		}
+
+		empty := Empty{Ignored: 1}
+		want, err := circlehash.HashValue(empty, seed)
+		if err != nil {
+			t.Fatalf("HashValue() returned error %v", err)
+		}
+		if got := empty.CircleHash64(seed); got != want {
+			t.Errorf("Empty.CircleHash64(0x%x) = 0x%016x; want 0x%016x", seed, got, want)
+		}
+
+		list := &List{Value: 1, Next: &List{Value: 2, Next: &List{Value: 3}}}
+		want, err = circlehash.HashValue(list, seed)
+		if err != nil {
+			t.Fatalf("HashValue() returned error %v", err)
+		}
+		if got := list.CircleHash64(seed); got != want {
+			t.Errorf("List.CircleHash64(0x%x) = 0x%016x; want 0x%016x", seed, got, want)
+		}
+	}
+}
+
+func BenchmarkGenerated(b *testing.B) {
+	c := Config{Name: "service", Tags: []string{"a", "b"}, Primary: Endpoint{"primary", 80}}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = c.CircleHash64(0)
+	}
+}
+
+func BenchmarkHashValue(b *testing.B) {
+	c := Config{Name: "service", Tags: []string{"a", "b"}, Primary: Endpoint{"primary", 80}}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = circlehash.HashValue(c, 0)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Circlehash-gen generates CircleHash64 methods for struct types so they
+// can be hashed without reflection.  Generated methods produce the same
+// digests as circlehash.HashValue.
+//
+// Usage:
+//
+//	circlehash-gen -type=T[,T...] [-output=file] [dir]
+//
+// For example, add this to a file in package with struct type Config:
+//
+//	//go:generate circlehash-gen -type=Config
+//
+// Running "go generate" creates config_circlehash.go containing
+//
+//	func (x *Config) CircleHash64(seed uint64) uint64
+//
+// Struct types referenced by fields of Config (and declared in the same
+// package) also get CircleHash64 methods.  Fields are hashed in declaration
+// order using the typed helpers in circlehash package, such as HashValueString.
+// Unexported fields and fields with struct tag `circlehash:"-"` are skipped.
+//
+// Fields of interface, func, chan, and types declared in other packages
+// aren't supported.  Struct types with only unexported fields, and struct
+// types with unexported fields and MarshalBinary or MarshalText methods,
+// aren't supported either because circlehash.HashValue can't hash them by
+// fields.
+//
+// Generated methods don't detect pointer cycles.  Hashing a value of
+// recursive type, such as
+//
+//	type Node struct{ Next *Node }
+//
+// recurses until the stack overflows if the value contains a cycle, while
+// circlehash.HashValue returns circlehash.ErrCycle.  Methods of recursive
+// types document this.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	log := func(format string, a ...interface{}) {
+		fmt.Fprintf(os.Stderr, "circlehash-gen: "+format+"\n", a...)
+		os.Exit(1)
+	}
+
+	typeNames := flag.String("type", "", "comma-separated list of struct type names; must be set")
+	output := flag.String("output", "", "output file name; default <dir>/<type>_circlehash.go")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: circlehash-gen -type=T[,T...] [-output=file] [dir]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *typeNames == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	dir := "."
+	switch flag.NArg() {
+	case 0:
+	case 1:
+		dir = flag.Arg(0)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	types := strings.Split(*typeNames, ",")
+
+	pkgName, files, err := parsePackage(dir)
+	if err != nil {
+		log("%v", err)
+	}
+
+	src, err := generate(pkgName, files, types, strings.Join(os.Args[1:], " "))
+	if err != nil {
+		log("%v", err)
+	}
+
+	outputName := *output
+	if outputName == "" {
+		outputName = filepath.Join(dir, strings.ToLower(types[0])+"_circlehash.go")
+	}
+
+	if err := os.WriteFile(outputName, src, 0o644); err != nil { //nolint:gosec
+		log("%v", err)
+	}
+}
+
+// parsePackage parses non-test Go files in dir that match build constraints.
+func parsePackage(dir string) (string, []*ast.File, error) {
+	pkg, err := build.Default.ImportDir(dir, 0)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to load package in %s: %w", dir, err)
+	}
+
+	fset := token.NewFileSet()
+
+	var files []*ast.File
+	for _, name := range pkg.GoFiles {
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, 0)
+		if err != nil {
+			return "", nil, err
+		}
+		files = append(files, f)
+	}
+
+	return pkg.Name, files, nil
+}
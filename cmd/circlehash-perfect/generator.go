@@ -0,0 +1,220 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/format"
+	"go/token"
+	"sort"
+	"strings"
+
+	"github.com/fxamacker/circlehash"
+)
+
+const (
+	// maxAttempts is maximum number of seeds tried by buildTable.
+	maxAttempts = 100
+
+	// maxDisplacementTries is maximum number of displacements tried for
+	// a bucket before trying next seed.
+	maxDisplacementTries = 1 << 20
+
+	// maxStrings is maximum number of strings.
+	maxStrings = 1 << 24
+
+	// stringsPerBucket is average number of strings per bucket.
+	stringsPerBucket = 3
+
+	// displacementMultiplier spreads displacement over 64 bits, and
+	// slotMultiplier mixes all bits of digest into high 32 bits used
+	// to select slot.
+	displacementMultiplier = 0x9e3779b97f4a7c15
+	slotMultiplier         = 0xbf58476d1ce4e5b9
+)
+
+// table is a perfect hash table for strings.
+type table struct {
+	seed          uint64
+	displacements []uint32
+	slots         []int // index of string in each slot
+}
+
+func (t *table) bucket(h uint64) uint64 {
+	return ((h >> 32) * uint64(len(t.displacements))) >> 32
+}
+
+func (t *table) slot(h uint64, d uint32) uint64 {
+	x := (h ^ uint64(d)*displacementMultiplier) * slotMultiplier
+	return ((x >> 32) * uint64(len(t.slots))) >> 32
+}
+
+// lookup returns index of s in strs, and true if s is in strs.
+// It is the same as generated lookup function.
+func (t *table) lookup(strs []string, s string) (int, bool) {
+	h := circlehash.Hash64String(s, t.seed)
+	i := t.slots[t.slot(h, t.displacements[t.bucket(h)])]
+	if strs[i] != s {
+		return -1, false
+	}
+	return i, true
+}
+
+// buildTable returns perfect hash table for distinct strs.
+func buildTable(strs []string, baseSeed uint64) (*table, error) {
+	if len(strs) == 0 {
+		return nil, errors.New("no strings")
+	}
+	if len(strs) > maxStrings {
+		return nil, fmt.Errorf("number of strings %d exceeds %d", len(strs), maxStrings)
+	}
+	seen := make(map[string]struct{}, len(strs))
+	for _, s := range strs {
+		if _, ok := seen[s]; ok {
+			return nil, fmt.Errorf("duplicate string %q", s)
+		}
+		seen[s] = struct{}{}
+	}
+
+	for attempt := uint64(0); attempt < maxAttempts; attempt++ {
+		seed := circlehash.Hash64Uint64x2(baseSeed, attempt, baseSeed)
+		if t, ok := tryBuildTable(strs, seed); ok {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("failed to find perfect hash after %d attempts", maxAttempts)
+}
+
+func tryBuildTable(strs []string, seed uint64) (*table, bool) {
+	t := &table{
+		seed:          seed,
+		displacements: make([]uint32, (len(strs)+stringsPerBucket-1)/stringsPerBucket),
+		slots:         make([]int, len(strs)),
+	}
+
+	hashes := make([]uint64, len(strs))
+	buckets := make([][]int, len(t.displacements))
+	for i, s := range strs {
+		hashes[i] = circlehash.Hash64String(s, seed)
+		b := t.bucket(hashes[i])
+		buckets[b] = append(buckets[b], i)
+	}
+
+	order := make([]int, len(buckets))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return len(buckets[order[i]]) > len(buckets[order[j]])
+	})
+
+	used := make([]bool, len(strs))
+	bucketSlots := make([]uint64, 0, 16)
+	for _, b := range order {
+		if len(buckets[b]) == 0 {
+			break
+		}
+
+		found := false
+		for d := uint32(0); d < maxDisplacementTries; d++ {
+			bucketSlots = bucketSlots[:0]
+			ok := true
+			for _, i := range buckets[b] {
+				slot := t.slot(hashes[i], d)
+				if used[slot] || containsUint64(bucketSlots, slot) {
+					ok = false
+					break
+				}
+				bucketSlots = append(bucketSlots, slot)
+			}
+			if ok {
+				t.displacements[b] = d
+				for j, i := range buckets[b] {
+					used[bucketSlots[j]] = true
+					t.slots[bucketSlots[j]] = i
+				}
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+	return t, true
+}
+
+func containsUint64(a []uint64, v uint64) bool {
+	for _, x := range a {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+// generate returns formatted Go source containing lookup function funcName
+// for strs.  args is recorded in the generated header.
+func generate(pkgName, funcName string, strs []string, t *table, args string) ([]byte, error) {
+	if !token.IsIdentifier(funcName) {
+		return nil, fmt.Errorf("invalid function name %q", funcName)
+	}
+	if !token.IsIdentifier(pkgName) {
+		return nil, fmt.Errorf("invalid package name %q", pkgName)
+	}
+
+	// Tables are unexported even if lookup function is exported.
+	prefix := strings.ToLower(funcName[:1]) + funcName[1:]
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by \"circlehash-perfect %s\"; DO NOT EDIT.\n\n", args)
+	fmt.Fprintf(&buf, "package %s\n\n", pkgName)
+	fmt.Fprintf(&buf, "import \"github.com/fxamacker/circlehash\"\n\n")
+
+	fmt.Fprintf(&buf, "const %sSeed = 0x%016x\n\n", prefix, t.seed)
+
+	fmt.Fprintf(&buf, "var %sDisplacements = [%d]uint32{", prefix, len(t.displacements))
+	for i, d := range t.displacements {
+		if i%8 == 0 {
+			buf.WriteString("\n")
+		}
+		fmt.Fprintf(&buf, "%d, ", d)
+	}
+	buf.WriteString("\n}\n\n")
+
+	fmt.Fprintf(&buf, "var %sSlots = [%d]struct {\ns string\ni int\n}{\n", prefix, len(t.slots))
+	for _, i := range t.slots {
+		fmt.Fprintf(&buf, "{%q, %d},\n", strs[i], i)
+	}
+	buf.WriteString("}\n\n")
+
+	fmt.Fprintf(&buf, "// %s returns index of s in input strings and true if s is in set,\n", funcName)
+	fmt.Fprintf(&buf, "// or -1 and false otherwise.\n")
+	fmt.Fprintf(&buf, "func %s(s string) (int, bool) {\n", funcName)
+	fmt.Fprintf(&buf, "h := circlehash.Hash64String(s, %sSeed)\n", prefix)
+	fmt.Fprintf(&buf, "d := %sDisplacements[((h>>32)*%d)>>32]\n", prefix, len(t.displacements))
+	fmt.Fprintf(&buf, "x := (h ^ uint64(d)*0x%x) * 0x%x\n", uint64(displacementMultiplier), uint64(slotMultiplier))
+	fmt.Fprintf(&buf, "e := &%sSlots[((x>>32)*%d)>>32]\n", prefix, len(t.slots))
+	fmt.Fprintf(&buf, "if e.s != s {\nreturn -1, false\n}\n")
+	fmt.Fprintf(&buf, "return e.i, true\n}\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return src, nil
+}
@@ -0,0 +1,187 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// TestGenerateGolden verifies generated code in internal/example is up to date.
+// Tests in internal/example verify generated code finds every keyword and
+// rejects other strings.
+func TestGenerateGolden(t *testing.T) {
+	dir := filepath.Join("internal", "example")
+	goldenFile := filepath.Join(dir, "keyword_perfect.go")
+
+	f, err := os.Open(filepath.Join(dir, "keywords.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	strs, err := readStrings(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tbl, err := buildTable(strs, 0)
+	if err != nil {
+		t.Fatalf("buildTable() returned error %v", err)
+	}
+
+	got, err := generate("example", "lookupKeyword", strs, tbl, "-func=lookupKeyword -output=keyword_perfect.go keywords.txt")
+	if err != nil {
+		t.Fatalf("generate() returned error %v", err)
+	}
+
+	if *update {
+		if err := os.WriteFile(goldenFile, got, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want, err := os.ReadFile(goldenFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("generate() doesn't match %s (run go test -update to update it):\n%s", goldenFile, got)
+	}
+}
+
+func testStrings(n int) []string {
+	strs := make([]string, n)
+	for i := range strs {
+		strs[i] = "s" + strconv.Itoa(i)
+	}
+	return strs
+}
+
+func TestBuildTable(t *testing.T) {
+	for _, n := range []int{1, 2, 3, 16, 64, 100, 1000, 100000} {
+		strs := testStrings(n)
+		tbl, err := buildTable(strs, 0)
+		if err != nil {
+			t.Fatalf("buildTable() with %d strings returned error %v", n, err)
+		}
+		if len(tbl.slots) != n {
+			t.Errorf("buildTable() with %d strings has %d slots", n, len(tbl.slots))
+		}
+
+		for i, s := range strs {
+			if got, ok := tbl.lookup(strs, s); !ok || got != i {
+				t.Fatalf("lookup(%q) = %d, %t; want %d, true", s, got, ok, i)
+			}
+		}
+		for i := 0; i < 1000; i++ {
+			s := "x" + strconv.Itoa(i)
+			if got, ok := tbl.lookup(strs, s); ok || got != -1 {
+				t.Fatalf("lookup(%q) = %d, %t; want -1, false", s, got, ok)
+			}
+		}
+	}
+}
+
+func TestBuildTableDeterministic(t *testing.T) {
+	strs := testStrings(1000)
+	t1, _ := buildTable(strs, 42)
+	t2, _ := buildTable(strs, 42)
+	t3, _ := buildTable(strs, 43)
+
+	src1, _ := generate("p", "f", strs, t1, "")
+	src2, _ := generate("p", "f", strs, t2, "")
+	src3, _ := generate("p", "f", strs, t3, "")
+	if !bytes.Equal(src1, src2) {
+		t.Error("generate() with the same seed returned different code")
+	}
+	if bytes.Equal(src1, src3) {
+		t.Error("generate() with different seeds returned the same code")
+	}
+}
+
+func TestBuildTableErrors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		strs    []string
+		wantErr string
+	}{
+		{"no strings", nil, "no strings"},
+		{"duplicates", []string{"a", "b", "a"}, "duplicate string \"a\""},
+	}
+	for _, tc := range testCases {
+		_, err := buildTable(tc.strs, 0)
+		if err == nil || err.Error() != tc.wantErr {
+			t.Errorf("%s: buildTable() returned error %v; want %q", tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	strs := []string{"a", "b"}
+	tbl, err := buildTable(strs, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name     string
+		pkgName  string
+		funcName string
+	}{
+		{"bad function name", "p", "1f"},
+		{"keyword function name", "p", "func"},
+		{"bad package name", "p-q", "f"},
+	}
+	for _, tc := range testCases {
+		if _, err := generate(tc.pkgName, tc.funcName, strs, tbl, ""); err == nil {
+			t.Errorf("%s: generate() didn't return error", tc.name)
+		}
+	}
+}
+
+func TestGenerateExportedFunc(t *testing.T) {
+	strs := []string{"a", "b"}
+	tbl, _ := buildTable(strs, 0)
+
+	src, err := generate("p", "LookupName", strs, tbl, "")
+	if err != nil {
+		t.Fatalf("generate() returned error %v", err)
+	}
+	for _, want := range []string{"func LookupName(s string)", "const lookupNameSeed", "var lookupNameSlots"} {
+		if !bytes.Contains(src, []byte(want)) {
+			t.Errorf("generate() doesn't contain %q:\n%s", want, src)
+		}
+	}
+}
+
+func TestReadStrings(t *testing.T) {
+	strs, err := readStrings(strings.NewReader("a\r\n\nb c\n\r\nd"))
+	if err != nil {
+		t.Fatalf("readStrings() returned error %v", err)
+	}
+	want := []string{"a", "b c", "d"}
+	if strings.Join(strs, "|") != strings.Join(want, "|") || len(strs) != len(want) {
+		t.Errorf("readStrings() = %q; want %q", strs, want)
+	}
+}
@@ -0,0 +1,26 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package example contains a Go keyword lookup function generated by
+// circlehash-perfect.  Generated code is verified to find every keyword and
+// reject other strings, and is used as golden file by circlehash-perfect tests.
+package example
+
+//go:generate go run ../.. -func=lookupKeyword -output=keyword_perfect.go keywords.txt
+
+// IsKeyword returns true if s is a Go keyword.
+func IsKeyword(s string) bool {
+	_, ok := lookupKeyword(s)
+	return ok
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package example
+
+import (
+	"bufio"
+	"go/token"
+	"os"
+	"strings"
+	"testing"
+)
+
+func readKeywords(t *testing.T) []string {
+	f, err := os.Open("keywords.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var keywords []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if s.Text() != "" {
+			keywords = append(keywords, s.Text())
+		}
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return keywords
+}
+
+func TestLookupKeywordMembers(t *testing.T) {
+	for i, kw := range readKeywords(t) {
+		got, ok := lookupKeyword(kw)
+		if !ok || got != i {
+			t.Errorf("lookupKeyword(%q) = %d, %t; want %d, true", kw, got, ok, i)
+		}
+		if !token.IsKeyword(kw) {
+			t.Errorf("keywords.txt contains %q, which isn't a Go keyword", kw)
+		}
+	}
+}
+
+func TestLookupKeywordNonMembers(t *testing.T) {
+	candidates := []string{
+		"", " ", "x", "true", "false", "nil", "iota", "int", "string", "any",
+		"append", "len", "main", "Func", "FUNC", "fun", "funcs", " func", "func ",
+		"func\x00", "interfac", "interfaces", "fallthroug", "go\n", "ifelse",
+	}
+	for _, kw := range readKeywords(t) {
+		candidates = append(candidates,
+			strings.ToUpper(kw),
+			kw[:len(kw)-1],
+			kw+"x",
+			"x"+kw,
+			kw+kw,
+		)
+	}
+
+	for _, s := range candidates {
+		got, ok := lookupKeyword(s)
+		if want := token.IsKeyword(s); ok != want {
+			t.Errorf("lookupKeyword(%q) = %d, %t; want %t", s, got, ok, want)
+		}
+		if !ok && got != -1 {
+			t.Errorf("lookupKeyword(%q) = %d, false; want -1, false", s, got)
+		}
+		if IsKeyword(s) != ok {
+			t.Errorf("IsKeyword(%q) = %t; want %t", s, IsKeyword(s), ok)
+		}
+	}
+}
+
+func BenchmarkLookupKeyword(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_, _ = lookupKeyword("interface")
+	}
+}
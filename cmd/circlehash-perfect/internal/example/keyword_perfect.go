@@ -0,0 +1,56 @@
+// Code generated by "circlehash-perfect -func=lookupKeyword -output=keyword_perfect.go keywords.txt"; DO NOT EDIT.
+
+package example
+
+import "github.com/fxamacker/circlehash"
+
+const lookupKeywordSeed = 0x92591aabe023d792
+
+var lookupKeywordDisplacements = [9]uint32{
+	33, 26, 0, 4, 0, 22, 0, 6,
+	62,
+}
+
+var lookupKeywordSlots = [25]struct {
+	s string
+	i int
+}{
+	{"defer", 6},
+	{"default", 5},
+	{"var", 24},
+	{"else", 7},
+	{"range", 18},
+	{"map", 16},
+	{"fallthrough", 8},
+	{"case", 1},
+	{"for", 9},
+	{"chan", 2},
+	{"type", 23},
+	{"switch", 22},
+	{"interface", 15},
+	{"func", 10},
+	{"package", 17},
+	{"struct", 21},
+	{"goto", 12},
+	{"return", 19},
+	{"const", 3},
+	{"go", 11},
+	{"continue", 4},
+	{"if", 13},
+	{"select", 20},
+	{"break", 0},
+	{"import", 14},
+}
+
+// lookupKeyword returns index of s in input strings and true if s is in set,
+// or -1 and false otherwise.
+func lookupKeyword(s string) (int, bool) {
+	h := circlehash.Hash64String(s, lookupKeywordSeed)
+	d := lookupKeywordDisplacements[((h>>32)*9)>>32]
+	x := (h ^ uint64(d)*0x9e3779b97f4a7c15) * 0xbf58476d1ce4e5b9
+	e := &lookupKeywordSlots[((x>>32)*25)>>32]
+	if e.s != s {
+		return -1, false
+	}
+	return e.i, true
+}
@@ -0,0 +1,137 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Circlehash-perfect generates Go source for perfect hash lookup of a static
+// set of strings, such as keywords and enum names, similar to gperf.
+//
+// Usage:
+//
+//	circlehash-perfect -func=name [-pkg=name] [-seed=n] [-output=file] [file]
+//
+// Strings are read one per line from file, or from standard input if file
+// isn't specified.  Empty lines are ignored and strings must be distinct.
+// For example, add this to a file in package that parses keywords:
+//
+//	//go:generate circlehash-perfect -func=lookupKeyword -output=keyword_perfect.go keywords.txt
+//
+// Running "go generate" creates keyword_perfect.go containing
+//
+//	func lookupKeyword(s string) (int, bool)
+//
+// which returns index of s in keywords.txt (counting non-empty lines) and
+// true if s is in set, or -1 and false otherwise.  Lookup computes one
+// circlehash.Hash64String digest and does one string comparison.
+//
+// Lookup uses hash-and-displace with a table of one slot per string.
+// Digest h selects a bucket using its high 32 bits, and bucket's
+// displacement d selects a slot:
+//
+//	h      = circlehash.Hash64String(s, seed)
+//	bucket = ((h >> 32) * numBuckets) >> 32
+//	x      = (h ^ d[bucket] * 0x9e3779b97f4a7c15) * 0xbf58476d1ce4e5b9
+//	slot   = ((x >> 32) * numSlots) >> 32
+//
+// Circlehash-perfect searches displacements for buckets in decreasing
+// order of size.  If search fails, it retries with seed i derived from
+// base seed:
+//
+//	seed_i = circlehash.Hash64Uint64x2(baseSeed, i, baseSeed)
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+func main() {
+	log := func(format string, a ...interface{}) {
+		fmt.Fprintf(os.Stderr, "circlehash-perfect: "+format+"\n", a...)
+		os.Exit(1)
+	}
+
+	funcName := flag.String("func", "", "name of generated lookup function; must be set")
+	pkgName := flag.String("pkg", "", "package name; default $GOPACKAGE or main")
+	seed := flag.Uint64("seed", 0, "base seed")
+	output := flag.String("output", "", "output file name; default standard output")
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: circlehash-perfect -func=name [-pkg=name] [-seed=n] [-output=file] [file]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if *funcName == "" || flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *pkgName == "" {
+		*pkgName = os.Getenv("GOPACKAGE")
+		if *pkgName == "" {
+			*pkgName = "main"
+		}
+	}
+
+	in := os.Stdin
+	if flag.NArg() == 1 {
+		f, err := os.Open(flag.Arg(0))
+		if err != nil {
+			log("%v", err)
+		}
+		defer f.Close()
+		in = f
+	}
+
+	strs, err := readStrings(in)
+	if err != nil {
+		log("%v", err)
+	}
+
+	t, err := buildTable(strs, *seed)
+	if err != nil {
+		log("%v", err)
+	}
+
+	src, err := generate(*pkgName, *funcName, strs, t, strings.Join(os.Args[1:], " "))
+	if err != nil {
+		log("%v", err)
+	}
+
+	if *output == "" {
+		if _, err := os.Stdout.Write(src); err != nil {
+			log("%v", err)
+		}
+		return
+	}
+	if err := os.WriteFile(*output, src, 0o644); err != nil { //nolint:gosec
+		log("%v", err)
+	}
+}
+
+// readStrings returns non-empty lines of r without trailing "\r".
+func readStrings(r io.Reader) ([]string, error) {
+	var strs []string
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		line := strings.TrimSuffix(s.Text(), "\r")
+		if line != "" {
+			strs = append(strs, line)
+		}
+	}
+	return strs, s.Err()
+}
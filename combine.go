@@ -0,0 +1,92 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package circlehash
+
+// Combine64 returns a 64-bit digest of digests h1 and h2.
+// Digest is compatible with Hash64Uint64x2(h1, h2, seed).
+//
+// Unlike h1 ^ h2, the result depends on the order of h1 and h2,
+// and combining a digest with itself doesn't cancel to a constant.
+func Combine64(seed uint64, h1 uint64, h2 uint64) uint64 {
+	return circle64fUint64x2(h1, h2, seed)
+}
+
+// CombineOrdered64 returns a 64-bit digest of digests hs in order.
+// Digest is compatible with Hash64 with byte slice containing hs
+// encoded in little-endian order (8 bytes per digest).  So
+// CombineOrdered64(seed, h1, h2) == Combine64(seed, h1, h2).
+//
+// The result depends on the order and number of digests, and
+// repeated digests don't cancel each other.
+func CombineOrdered64(seed uint64, hs ...uint64) uint64 {
+	if len(hs) == 2 {
+		return circle64fUint64x2(hs[0], hs[1], seed)
+	}
+	return circle64fUint64s(hs, seed)
+}
+
+// circle64fUint64s produces a 64-bit digest from hs and seed.
+// Digest is compatible with circle64f with byte slice containing
+// hs encoded in little-endian order.
+func circle64fUint64s(hs []uint64, seed uint64) uint64 {
+
+	startingLength := uint64(len(hs)) * 8
+	currentState := seed ^ pi0
+
+	if len(hs) > 8 {
+		// Process chunks of 64 bytes (8 words).
+		duplicatedState := currentState
+
+		for ; len(hs) > 8; hs = hs[8:] {
+			cs0 := mix64(hs[0]^pi1, hs[1]^currentState)
+			cs1 := mix64(hs[2]^pi2, hs[3]^currentState)
+			currentState = (cs0 ^ cs1)
+
+			ds0 := mix64(hs[4]^pi3, hs[5]^duplicatedState)
+			ds1 := mix64(hs[6]^pi4, hs[7]^duplicatedState)
+			duplicatedState = (ds0 ^ ds1)
+		}
+
+		currentState ^= duplicatedState
+	}
+
+	// We have at most 8 words to process.
+	// Process chunks of 2 words.
+	for ; len(hs) > 2; hs = hs[2:] {
+		currentState = mix64(hs[0]^pi1, hs[1]^currentState)
+	}
+
+	// We have at most 2 words to process.
+
+	// a and b are 0 for default case of no words
+	a := uint64(0)
+	b := uint64(0)
+
+	switch len(hs) {
+	case 2:
+		a = hs[0]
+		b = hs[1]
+
+	case 1:
+		// Same as circle64f reading 8 bytes as two 4-byte halves.
+		a = hs[0] & 0xFFFFFFFF
+		b = hs[0] >> 32
+	}
+
+	// We use pi1 and pi4 during finalization (abseil and wyhash reuses same const)
+	w := mix64(a^pi1, b^currentState)
+	z := pi4 ^ startingLength
+	return mix64(w, z)
+}
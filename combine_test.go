@@ -0,0 +1,116 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package circlehash
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+func TestCombine64(t *testing.T) {
+	seeds := []uint64{numsAllZeros, numsAll55s, numsAllAAs, numsAllFFs, numsGoldenRatio, numsGoldenRatioInv}
+	digests := []uint64{numsAllZeros, numsAll55s, numsAllFFs, numsGoldenRatio, 1, 1 << 63}
+
+	for _, seed := range seeds {
+		for _, h1 := range digests {
+			for _, h2 := range digests {
+				got := Combine64(seed, h1, h2)
+
+				want := Hash64Uint64x2(h1, h2, seed)
+				if got != want {
+					t.Errorf("Combine64(0x%016x, 0x%016x, 0x%016x) = 0x%016x; want 0x%016x", seed, h1, h2, got, want)
+				}
+
+				if h1 != h2 && got == Combine64(seed, h2, h1) {
+					t.Errorf("Combine64(0x%016x, 0x%016x, 0x%016x) isn't order sensitive", seed, h1, h2)
+				}
+			}
+		}
+	}
+}
+
+func TestCombine64NoCancellation(t *testing.T) {
+	seed := numsGoldenRatio
+	digests := []uint64{numsAllZeros, numsAll55s, numsAllAAs, numsAllFFs, numsGoldenRatio, numsGoldenRatioInv}
+
+	seen := make(map[uint64]uint64)
+	for _, h := range digests {
+		got := Combine64(seed, h, h)
+		if prev, ok := seen[got]; ok {
+			t.Errorf("Combine64(seed, 0x%016x, 0x%016x) == Combine64(seed, 0x%016x, 0x%016x) = 0x%016x", h, h, prev, prev, got)
+		}
+		seen[got] = h
+	}
+}
+
+func TestCombineOrdered64(t *testing.T) {
+	data := nonUniformBytes16KiB()
+
+	for _, seed := range []uint64{numsAllZeros, numsAll55s, numsAllFFs, numsGoldenRatio} {
+		// Test up to 40 digests (320 bytes) to cover all code paths.
+		for n := 0; n <= 40; n++ {
+			hs := make([]uint64, n)
+			for i := range hs {
+				hs[i] = binary.LittleEndian.Uint64(data[i*8:])
+			}
+
+			got := CombineOrdered64(seed, hs...)
+
+			want := Hash64(data[:n*8], seed)
+			if got != want {
+				t.Errorf("CombineOrdered64(0x%016x, %d digests) = 0x%016x; want 0x%016x", seed, n, got, want)
+			}
+
+			// Verify generic code path separately since 2 digests use a shortcut.
+			got = circle64fUint64s(hs, seed)
+			if got != want {
+				t.Errorf("circle64fUint64s(%d digests, 0x%016x) = 0x%016x; want 0x%016x", n, seed, got, want)
+			}
+		}
+	}
+}
+
+func TestCombineOrdered64OrderSensitive(t *testing.T) {
+	seed := numsGoldenRatio
+
+	testCases := []struct {
+		name string
+		hs1  []uint64
+		hs2  []uint64
+	}{
+		{"swapped", []uint64{1, 2, 3}, []uint64{1, 3, 2}},
+		{"reversed", []uint64{1, 2, 3, 4, 5, 6, 7, 8, 9}, []uint64{9, 8, 7, 6, 5, 4, 3, 2, 1}},
+		{"repeated", []uint64{numsAll55s, numsAll55s}, []uint64{numsAllAAs, numsAllAAs}},
+		{"appended zero", []uint64{1}, []uint64{1, 0}},
+		{"empty", []uint64{}, []uint64{0}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h1 := CombineOrdered64(seed, tc.hs1...)
+			h2 := CombineOrdered64(seed, tc.hs2...)
+			if h1 == h2 {
+				t.Errorf("CombineOrdered64(seed, %v) == CombineOrdered64(seed, %v) = 0x%016x", tc.hs1, tc.hs2, h1)
+			}
+		})
+	}
+}
+
+func BenchmarkCombineOrdered64(b *testing.B) {
+	hs := []uint64{numsAll55s, numsAllAAs, numsGoldenRatio, numsGoldenRatioInv}
+	for i := 0; i < b.N; i++ {
+		_ = CombineOrdered64(numsGoldenRatio, hs...)
+	}
+}
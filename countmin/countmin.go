@@ -0,0 +1,305 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package countmin implements Count-Min sketches and top-k heavy hitter
+// tracking using CircleHash64f.
+//
+// Sketch derives column index of each row from one Hash64 digest
+// using double hashing:
+//
+//	h1 = circlehash.Hash64(data, seed)
+//	h2 = circlehash.Hash64Uint64x2(h1, 0, seed)
+//	column of row i = high 64 bits of (h1 + i*h2) * width
+//
+// where arithmetic is modulo 2^64.  Serialized sketches record the seed
+// and this algorithm, so sketches from different processes can be merged.
+package countmin
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/fxamacker/circlehash"
+)
+
+const (
+	formatVersion = 1
+
+	// algorithmCircleHash64fDoubleHashing is the index derivation described
+	// in package documentation.
+	algorithmCircleHash64fDoubleHashing = 1
+
+	flagConservative = 1
+
+	// headerSize is the size of serialized sketch header:
+	// magic (4), version (1), algorithm (1), flags (1), reserved (1),
+	// width (4), depth (4), seed (8), total (8).
+	headerSize = 32
+
+	maxDepth = 64
+	maxSize  = 1 << 28
+)
+
+var magic = [4]byte{'C', 'H', 'C', 'M'}
+
+var (
+	// ErrIncompatible is returned when merging sketches with different
+	// width, depth, or seed.
+	ErrIncompatible = errors.New("countmin: sketches have different parameters")
+
+	// ErrInvalidData is returned by UnmarshalBinary when data isn't valid serialized data.
+	ErrInvalidData = errors.New("countmin: invalid serialized data")
+)
+
+// Sketch is a Count-Min sketch.  Count never underestimates, and with
+// probability 1-delta it overestimates by at most epsilon*Total, where
+// width is ceil(e/epsilon) and depth is ceil(ln(1/delta)).
+// Sketch is not safe for concurrent use.
+type Sketch struct {
+	width        uint32
+	depth        uint32
+	seed         uint64
+	conservative bool
+	total        uint64
+	counts       []uint64 // depth rows of width counters
+}
+
+// New returns a Sketch with depth rows of width counters.
+func New(width, depth int, seed uint64) (*Sketch, error) {
+	if width < 1 || depth < 1 || depth > maxDepth || width > maxSize/depth {
+		return nil, fmt.Errorf("countmin: width %d and depth %d are invalid", width, depth)
+	}
+	return &Sketch{
+		width:  uint32(width),
+		depth:  uint32(depth),
+		seed:   seed,
+		counts: make([]uint64, width*depth),
+	}, nil
+}
+
+// NewWithEstimates returns a Sketch that overestimates counts by at most
+// epsilon*Total with probability 1-delta.
+func NewWithEstimates(epsilon, delta float64, seed uint64) (*Sketch, error) {
+	if !(epsilon > 0 && epsilon < 1) || !(delta > 0 && delta < 1) {
+		return nil, fmt.Errorf("countmin: epsilon %v and delta %v are not in range (0, 1)", epsilon, delta)
+	}
+	width := math.Ceil(math.E / epsilon)
+	depth := math.Ceil(math.Log(1 / delta))
+	if width > maxSize {
+		return nil, fmt.Errorf("countmin: epsilon %v is too small", epsilon)
+	}
+	return New(int(width), int(depth), seed)
+}
+
+// NewConservative returns a Sketch that uses conservative update: Add only
+// increases counters that are below the new estimate.  Conservative update
+// reduces overestimation, but Sketch can't be used with negative updates.
+func NewConservative(width, depth int, seed uint64) (*Sketch, error) {
+	s, err := New(width, depth, seed)
+	if err != nil {
+		return nil, err
+	}
+	s.conservative = true
+	return s, nil
+}
+
+// Width returns number of counters per row.
+func (s *Sketch) Width() int {
+	return int(s.width)
+}
+
+// Depth returns number of rows.
+func (s *Sketch) Depth() int {
+	return int(s.depth)
+}
+
+// Seed returns the seed used by s.
+func (s *Sketch) Seed() uint64 {
+	return s.seed
+}
+
+// Conservative returns true if s uses conservative update.
+func (s *Sketch) Conservative() bool {
+	return s.conservative
+}
+
+// Total returns sum of all counts added to s.
+func (s *Sketch) Total() uint64 {
+	return s.total
+}
+
+// Add adds n to count of b and returns estimated count of b after adding.
+func (s *Sketch) Add(b []byte, n uint64) uint64 {
+	return s.add(circlehash.Hash64(b, s.seed), n)
+}
+
+// AddString adds n to count of str and returns estimated count of str after adding.
+func (s *Sketch) AddString(str string, n uint64) uint64 {
+	return s.add(circlehash.Hash64String(str, s.seed), n)
+}
+
+// Count returns estimated count of b.
+func (s *Sketch) Count(b []byte) uint64 {
+	return s.count(circlehash.Hash64(b, s.seed))
+}
+
+// CountString returns estimated count of str.
+func (s *Sketch) CountString(str string) uint64 {
+	return s.count(circlehash.Hash64String(str, s.seed))
+}
+
+// Reset sets all counts in s to 0.
+func (s *Sketch) Reset() {
+	for i := range s.counts {
+		s.counts[i] = 0
+	}
+	s.total = 0
+}
+
+func (s *Sketch) add(h1, n uint64) uint64 {
+	s.total = addSaturated(s.total, n)
+
+	h2 := circlehash.Hash64Uint64x2(h1, 0, s.seed)
+
+	if s.conservative {
+		// Compute estimate first, then raise counters only up to new estimate.
+		est := addSaturated(s.countWithHashes(h1, h2), n)
+		for i := uint32(0); i < s.depth; i++ {
+			c := &s.counts[s.index(i, h1)]
+			if *c < est {
+				*c = est
+			}
+			h1 += h2
+		}
+		return est
+	}
+
+	est := uint64(math.MaxUint64)
+	for i := uint32(0); i < s.depth; i++ {
+		c := &s.counts[s.index(i, h1)]
+		*c = addSaturated(*c, n)
+		if *c < est {
+			est = *c
+		}
+		h1 += h2
+	}
+	return est
+}
+
+func (s *Sketch) count(h1 uint64) uint64 {
+	return s.countWithHashes(h1, circlehash.Hash64Uint64x2(h1, 0, s.seed))
+}
+
+func (s *Sketch) countWithHashes(h1, h2 uint64) uint64 {
+	est := uint64(math.MaxUint64)
+	for i := uint32(0); i < s.depth; i++ {
+		if c := s.counts[s.index(i, h1)]; c < est {
+			est = c
+		}
+		h1 += h2
+	}
+	return est
+}
+
+// index returns index of counter in row i for hash h.
+func (s *Sketch) index(row uint32, h uint64) int {
+	col, _ := bits.Mul64(h, uint64(s.width))
+	return int(row*s.width) + int(col)
+}
+
+func addSaturated(a, b uint64) uint64 {
+	sum, carry := bits.Add64(a, b, 0)
+	if carry != 0 {
+		return math.MaxUint64
+	}
+	return sum
+}
+
+// Merge adds counts of o to s.  It returns ErrIncompatible if o has
+// different width, depth, or seed.
+func (s *Sketch) Merge(o *Sketch) error {
+	if s.width != o.width || s.depth != o.depth || s.seed != o.seed {
+		return ErrIncompatible
+	}
+	for i, c := range o.counts {
+		s.counts[i] = addSaturated(s.counts[i], c)
+	}
+	s.total = addSaturated(s.total, o.total)
+	return nil
+}
+
+// MarshalBinary returns serialized s.  Serialized sketch records format
+// version, index algorithm, dimensions, and seed.
+func (s *Sketch) MarshalBinary() ([]byte, error) {
+	data := make([]byte, headerSize+8*len(s.counts))
+	copy(data, magic[:])
+	data[4] = formatVersion
+	data[5] = algorithmCircleHash64fDoubleHashing
+	if s.conservative {
+		data[6] = flagConservative
+	}
+	binary.LittleEndian.PutUint32(data[8:], s.width)
+	binary.LittleEndian.PutUint32(data[12:], s.depth)
+	binary.LittleEndian.PutUint64(data[16:], s.seed)
+	binary.LittleEndian.PutUint64(data[24:], s.total)
+	for i, c := range s.counts {
+		binary.LittleEndian.PutUint64(data[headerSize+8*i:], c)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary sets s to sketch serialized by MarshalBinary.
+func (s *Sketch) UnmarshalBinary(data []byte) error {
+	if len(data) < headerSize || [4]byte{data[0], data[1], data[2], data[3]} != magic {
+		return ErrInvalidData
+	}
+	if data[4] != formatVersion {
+		return fmt.Errorf("countmin: unsupported format version %d", data[4])
+	}
+	if data[5] != algorithmCircleHash64fDoubleHashing {
+		return fmt.Errorf("countmin: unsupported algorithm %d", data[5])
+	}
+	if data[6]&^flagConservative != 0 {
+		return ErrInvalidData
+	}
+
+	width := binary.LittleEndian.Uint32(data[8:])
+	depth := binary.LittleEndian.Uint32(data[12:])
+	if width > maxSize || depth > maxDepth {
+		return ErrInvalidData
+	}
+
+	// Check data size before allocating counters, so untrusted width and
+	// depth can't allocate more memory than data size.
+	if uint64(len(data)-headerSize) != 8*uint64(width)*uint64(depth) {
+		return ErrInvalidData
+	}
+
+	t, err := New(int(width), int(depth), binary.LittleEndian.Uint64(data[16:]))
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidData, err)
+	}
+
+	t.conservative = data[6]&flagConservative != 0
+	t.total = binary.LittleEndian.Uint64(data[24:])
+	for i := range t.counts {
+		t.counts[i] = binary.LittleEndian.Uint64(data[headerSize+8*i:])
+	}
+
+	*s = *t
+	return nil
+}
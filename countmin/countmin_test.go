@@ -0,0 +1,297 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package countmin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+)
+
+const testSeed = uint64(0x9E3779B97F4A7C15)
+
+// zipfCounts returns counts of n keys following Zipf's law.
+func zipfCounts(n int) []uint64 {
+	counts := make([]uint64, n)
+	for i := range counts {
+		counts[i] = uint64(100000 / (i + 1))
+	}
+	return counts
+}
+
+func TestNewInvalid(t *testing.T) {
+	testCases := []struct {
+		width, depth int
+	}{
+		{0, 4},
+		{100, 0},
+		{100, maxDepth + 1},
+		{maxSize, 2},
+	}
+	for _, tc := range testCases {
+		if _, err := New(tc.width, tc.depth, testSeed); err == nil {
+			t.Errorf("New(%d, %d) didn't return error", tc.width, tc.depth)
+		}
+	}
+	for _, v := range []float64{0, 1, -1, math.NaN()} {
+		if _, err := NewWithEstimates(v, 0.01, testSeed); err == nil {
+			t.Errorf("NewWithEstimates(%v, 0.01) didn't return error", v)
+		}
+		if _, err := NewWithEstimates(0.01, v, testSeed); err == nil {
+			t.Errorf("NewWithEstimates(0.01, %v) didn't return error", v)
+		}
+	}
+}
+
+func TestNewWithEstimates(t *testing.T) {
+	s, err := NewWithEstimates(0.001, 0.01, testSeed)
+	if err != nil {
+		t.Fatalf("NewWithEstimates() returned error %v", err)
+	}
+	if s.Width() != 2719 || s.Depth() != 5 {
+		t.Errorf("NewWithEstimates(0.001, 0.01) returned sketch with width %d, depth %d; want 2719, 5", s.Width(), s.Depth())
+	}
+}
+
+func TestErrorBounds(t *testing.T) {
+	const epsilon = 0.001
+	const delta = 0.01
+
+	counts := zipfCounts(20000)
+
+	for _, conservative := range []bool{false, true} {
+		s, _ := NewWithEstimates(epsilon, delta, testSeed)
+		s.conservative = conservative
+
+		var total uint64
+		for i, c := range counts {
+			s.AddString(strconv.Itoa(i), c)
+			total += c
+		}
+		if s.Total() != total {
+			t.Errorf("conservative=%t: Total() = %d; want %d", conservative, s.Total(), total)
+		}
+
+		bound := uint64(epsilon * float64(total))
+		exceeded := 0
+		for i, c := range counts {
+			got := s.Count([]byte(strconv.Itoa(i)))
+			if got < c {
+				t.Fatalf("conservative=%t: Count(%d) = %d; want at least %d", conservative, i, got, c)
+			}
+			if got-c > bound {
+				exceeded++
+			}
+		}
+		if float64(exceeded) > delta*float64(len(counts)) {
+			t.Errorf("conservative=%t: %d of %d counts exceed error bound %d", conservative, exceeded, len(counts), bound)
+		}
+	}
+}
+
+func TestConservativeUpdate(t *testing.T) {
+	s1, _ := New(200, 4, testSeed)
+	s2, _ := NewConservative(200, 4, testSeed)
+	if !s2.Conservative() {
+		t.Fatal("Conservative() = false for sketch from NewConservative()")
+	}
+
+	counts := zipfCounts(2000)
+	for i, c := range counts {
+		s1.AddString(strconv.Itoa(i), c)
+		s2.AddString(strconv.Itoa(i), c)
+	}
+
+	var err1, err2 uint64
+	for i, c := range counts {
+		err1 += s1.CountString(strconv.Itoa(i)) - c
+		err2 += s2.CountString(strconv.Itoa(i)) - c
+	}
+	if err2 >= err1 {
+		t.Errorf("total overestimate with conservative update = %d; want less than %d", err2, err1)
+	}
+}
+
+func TestAddReturnsCount(t *testing.T) {
+	for _, conservative := range []bool{false, true} {
+		s, _ := New(100, 4, testSeed)
+		s.conservative = conservative
+		for i := 0; i < 1000; i++ {
+			key := strconv.Itoa(i % 37)
+			got := s.AddString(key, 1)
+			if want := s.CountString(key); got != want {
+				t.Fatalf("conservative=%t: AddString(%q) = %d; want %d", conservative, key, got, want)
+			}
+		}
+	}
+}
+
+func TestAddSaturates(t *testing.T) {
+	s, _ := New(10, 2, testSeed)
+	s.AddString("a", math.MaxUint64-1)
+	if got := s.AddString("a", 10); got != math.MaxUint64 {
+		t.Errorf("AddString() = %d; want %d", got, uint64(math.MaxUint64))
+	}
+}
+
+func TestMerge(t *testing.T) {
+	s1, _ := New(500, 4, testSeed)
+	s2, _ := New(500, 4, testSeed)
+	all, _ := New(500, 4, testSeed)
+
+	for i := 0; i < 5000; i++ {
+		key := strconv.Itoa(i % 300)
+		if i%3 == 0 {
+			s1.AddString(key, uint64(i))
+		} else {
+			s2.AddString(key, uint64(i))
+		}
+		all.AddString(key, uint64(i))
+	}
+
+	if err := s1.Merge(s2); err != nil {
+		t.Fatalf("Merge() returned error %v", err)
+	}
+	if s1.Total() != all.Total() {
+		t.Errorf("Total() of merged sketch = %d; want %d", s1.Total(), all.Total())
+	}
+	for i := 0; i < 300; i++ {
+		key := strconv.Itoa(i)
+		if got, want := s1.CountString(key), all.CountString(key); got != want {
+			t.Errorf("CountString(%q) of merged sketch = %d; want %d", key, got, want)
+		}
+	}
+}
+
+func TestMergeIncompatible(t *testing.T) {
+	s, _ := New(100, 4, testSeed)
+	for _, o := range []*Sketch{
+		{width: 101, depth: 4, seed: testSeed, counts: make([]uint64, 404)},
+		{width: 100, depth: 5, seed: testSeed, counts: make([]uint64, 500)},
+		{width: 100, depth: 4, seed: 1, counts: make([]uint64, 400)},
+	} {
+		if err := s.Merge(o); !errors.Is(err, ErrIncompatible) {
+			t.Errorf("Merge(width=%d, depth=%d, seed=0x%x) returned error %v; want %v", o.width, o.depth, o.seed, err, ErrIncompatible)
+		}
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	s, _ := NewConservative(100, 3, testSeed)
+	for i := 0; i < 1000; i++ {
+		s.AddString(strconv.Itoa(i%50), uint64(i))
+	}
+
+	data, err := s.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() returned error %v", err)
+	}
+
+	var s2 Sketch
+	if err := s2.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary() returned error %v", err)
+	}
+	if s2.Width() != 100 || s2.Depth() != 3 || s2.Seed() != testSeed || !s2.Conservative() || s2.Total() != s.Total() {
+		t.Errorf("UnmarshalBinary() returned sketch with width %d, depth %d, seed 0x%x, conservative %t, total %d",
+			s2.Width(), s2.Depth(), s2.Seed(), s2.Conservative(), s2.Total())
+	}
+	for i := 0; i < 50; i++ {
+		if got, want := s2.CountString(strconv.Itoa(i)), s.CountString(strconv.Itoa(i)); got != want {
+			t.Errorf("CountString(%d) of unmarshaled sketch = %d; want %d", i, got, want)
+		}
+	}
+
+	data2, _ := s2.MarshalBinary()
+	if !bytes.Equal(data, data2) {
+		t.Error("MarshalBinary() of unmarshaled sketch returned different data")
+	}
+}
+
+func TestUnmarshalBinaryInvalid(t *testing.T) {
+	s, _ := New(10, 2, testSeed)
+	s.AddString("a", 1)
+	valid, _ := s.MarshalBinary()
+
+	modify := func(fn func(b []byte)) []byte {
+		b := append([]byte(nil), valid...)
+		fn(b)
+		return b
+	}
+
+	testCases := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"short header", valid[:headerSize-1]},
+		{"bad magic", modify(func(b []byte) { b[0] = 'X' })},
+		{"bad version", modify(func(b []byte) { b[4] = 2 })},
+		{"bad algorithm", modify(func(b []byte) { b[5] = 0 })},
+		{"bad flags", modify(func(b []byte) { b[6] = 2 })},
+		{"zero width", modify(func(b []byte) { b[8] = 0 })},
+		{"bad depth", modify(func(b []byte) { b[12] = maxDepth + 1 })},
+		{"truncated", valid[:len(valid)-1]},
+		{"extra data", append(append([]byte(nil), valid...), 0)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var s Sketch
+			if err := s.UnmarshalBinary(tc.data); err == nil {
+				t.Error("UnmarshalBinary() didn't return error")
+			}
+		})
+	}
+}
+
+func TestUnmarshalBinaryHugeSize(t *testing.T) {
+	s, _ := New(10, 2, testSeed)
+	data, _ := s.MarshalBinary()
+	data = data[:headerSize]
+
+	// Header declares up to 2^28 counters (2 GiB) without counters.
+	binary.LittleEndian.PutUint32(data[8:], maxSize/maxDepth)
+	binary.LittleEndian.PutUint32(data[12:], maxDepth)
+
+	var g Sketch
+	allocs := testing.AllocsPerRun(10, func() {
+		if err := g.UnmarshalBinary(data); err == nil {
+			t.Error("UnmarshalBinary() with huge size and no counters didn't return error")
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("UnmarshalBinary() with huge size and no counters allocated %v times; want 0", allocs)
+	}
+}
+
+func BenchmarkAdd(b *testing.B) {
+	s, _ := NewWithEstimates(0.0001, 0.001, testSeed)
+	key := []byte("client-10.0.0.1")
+	for i := 0; i < b.N; i++ {
+		s.Add(key, 1)
+	}
+}
+
+func BenchmarkAddConservative(b *testing.B) {
+	s, _ := NewWithEstimates(0.0001, 0.001, testSeed)
+	s.conservative = true
+	key := []byte("client-10.0.0.1")
+	for i := 0; i < b.N; i++ {
+		s.Add(key, 1)
+	}
+}
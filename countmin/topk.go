@@ -0,0 +1,290 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package countmin
+
+import (
+	"container/heap"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// topKMagic identifies serialized TopK.
+var topKMagic = [4]byte{'C', 'H', 'T', 'K'}
+
+// topKHeaderSize is the size of serialized TopK header:
+// magic (4), version (1), reserved (3), k (4), number of items (4), sketch size (4).
+const topKHeaderSize = 20
+
+// Item is a key and its estimated count.
+type Item struct {
+	Key   string
+	Count uint64
+}
+
+// TopK tracks k keys with highest estimated counts (heavy hitters) using
+// a Sketch and a min-heap of candidates.  It is not safe for concurrent use.
+type TopK struct {
+	k      int
+	sketch *Sketch
+	heap   topKHeap
+	index  map[string]int // key to position in heap
+}
+
+// NewTopK returns a TopK tracking k keys, using sketch to estimate counts.
+// TopK takes ownership of sketch.
+func NewTopK(k int, sketch *Sketch) (*TopK, error) {
+	if k < 1 {
+		return nil, fmt.Errorf("countmin: k %d is less than 1", k)
+	}
+	if sketch == nil {
+		return nil, errors.New("countmin: sketch is nil")
+	}
+	return newTopK(k, sketch, k), nil
+}
+
+// newTopK returns a TopK with index sized for hint keys.
+func newTopK(k int, sketch *Sketch, hint int) *TopK {
+	t := &TopK{
+		k:      k,
+		sketch: sketch,
+		index:  make(map[string]int, hint),
+	}
+	t.heap.index = t.index
+	return t
+}
+
+// K returns maximum number of tracked keys.
+func (t *TopK) K() int {
+	return t.k
+}
+
+// Sketch returns sketch used by t.
+func (t *TopK) Sketch() *Sketch {
+	return t.sketch
+}
+
+// Add adds n to count of b and returns estimated count of b after adding.
+func (t *TopK) Add(b []byte, n uint64) uint64 {
+	est := t.sketch.Add(b, n)
+	if i, ok := t.index[string(b)]; ok {
+		t.update(i, est)
+	} else if t.admits(est) {
+		t.insert(string(b), est)
+	}
+	return est
+}
+
+// AddString adds n to count of s and returns estimated count of s after adding.
+func (t *TopK) AddString(s string, n uint64) uint64 {
+	est := t.sketch.AddString(s, n)
+	if i, ok := t.index[s]; ok {
+		t.update(i, est)
+	} else if t.admits(est) {
+		t.insert(s, est)
+	}
+	return est
+}
+
+// List returns tracked keys sorted by estimated count in descending order.
+// Keys with the same count are sorted by key.
+func (t *TopK) List() []Item {
+	items := make([]Item, len(t.heap.items))
+	copy(items, t.heap.items)
+	sortItems(items)
+	return items
+}
+
+func sortItems(items []Item) {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Count != items[j].Count {
+			return items[i].Count > items[j].Count
+		}
+		return items[i].Key < items[j].Key
+	})
+}
+
+func (t *TopK) admits(count uint64) bool {
+	return len(t.heap.items) < t.k || count > t.heap.items[0].Count
+}
+
+func (t *TopK) update(i int, count uint64) {
+	t.heap.items[i].Count = count
+	heap.Fix(&t.heap, i)
+}
+
+func (t *TopK) insert(key string, count uint64) {
+	if len(t.heap.items) < t.k {
+		heap.Push(&t.heap, Item{Key: key, Count: count})
+		return
+	}
+	// Replace key with the lowest count.
+	delete(t.index, t.heap.items[0].Key)
+	t.heap.items[0] = Item{Key: key, Count: count}
+	t.index[key] = 0
+	heap.Fix(&t.heap, 0)
+}
+
+// Merge merges o into t.  Sketch of o is merged into sketch of t, and
+// tracked keys of both are re-estimated with merged sketch.
+// It returns ErrIncompatible if sketches can't be merged.
+func (t *TopK) Merge(o *TopK) error {
+	if err := t.sketch.Merge(o.sketch); err != nil {
+		return err
+	}
+
+	candidates := make([]Item, 0, len(t.heap.items)+len(o.heap.items))
+	for _, items := range [][]Item{t.heap.items, o.heap.items} {
+		for _, it := range items {
+			candidates = append(candidates, Item{Key: it.Key, Count: t.sketch.CountString(it.Key)})
+		}
+	}
+	sortItems(candidates)
+
+	t.heap.items = t.heap.items[:0]
+	for k := range t.index {
+		delete(t.index, k)
+	}
+	for _, it := range candidates {
+		if len(t.heap.items) == t.k {
+			break
+		}
+		if _, ok := t.index[it.Key]; !ok {
+			heap.Push(&t.heap, it)
+		}
+	}
+	return nil
+}
+
+// MarshalBinary returns serialized t, including its sketch.
+func (t *TopK) MarshalBinary() ([]byte, error) {
+	sketchData, err := t.sketch.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	size := topKHeaderSize + len(sketchData)
+	for _, it := range t.heap.items {
+		size += 12 + len(it.Key)
+	}
+
+	data := make([]byte, topKHeaderSize, size)
+	copy(data, topKMagic[:])
+	data[4] = formatVersion
+	binary.LittleEndian.PutUint32(data[8:], uint32(t.k))
+	binary.LittleEndian.PutUint32(data[12:], uint32(len(t.heap.items)))
+	binary.LittleEndian.PutUint32(data[16:], uint32(len(sketchData)))
+	data = append(data, sketchData...)
+
+	var buf [12]byte
+	for _, it := range t.heap.items {
+		binary.LittleEndian.PutUint64(buf[:], it.Count)
+		binary.LittleEndian.PutUint32(buf[8:], uint32(len(it.Key)))
+		data = append(data, buf[:]...)
+		data = append(data, it.Key...)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary sets t to TopK serialized by MarshalBinary.
+func (t *TopK) UnmarshalBinary(data []byte) error {
+	if len(data) < topKHeaderSize || [4]byte{data[0], data[1], data[2], data[3]} != topKMagic {
+		return ErrInvalidData
+	}
+	if data[4] != formatVersion {
+		return fmt.Errorf("countmin: unsupported format version %d", data[4])
+	}
+
+	k := binary.LittleEndian.Uint32(data[8:])
+	n := binary.LittleEndian.Uint32(data[12:])
+	sketchSize := binary.LittleEndian.Uint32(data[16:])
+	if k < 1 || k > 1<<24 || n > k || uint64(len(data)-topKHeaderSize) < uint64(sketchSize) {
+		return ErrInvalidData
+	}
+
+	data = data[topKHeaderSize:]
+	var sketch Sketch
+	if err := sketch.UnmarshalBinary(data[:sketchSize]); err != nil {
+		return err
+	}
+	data = data[sketchSize:]
+
+	// Each item has at least 12 bytes, so untrusted k and n can't
+	// allocate more memory than data size.
+	if uint64(n)*12 > uint64(len(data)) {
+		return ErrInvalidData
+	}
+
+	u := newTopK(int(k), &sketch, int(n))
+	for i := uint32(0); i < n; i++ {
+		if len(data) < 12 {
+			return ErrInvalidData
+		}
+		count := binary.LittleEndian.Uint64(data)
+		keyLen := binary.LittleEndian.Uint32(data[8:])
+		data = data[12:]
+		if uint64(len(data)) < uint64(keyLen) {
+			return ErrInvalidData
+		}
+		key := string(data[:keyLen])
+		data = data[keyLen:]
+
+		if _, ok := u.index[key]; ok {
+			return ErrInvalidData
+		}
+		heap.Push(&u.heap, Item{Key: key, Count: count})
+	}
+	if len(data) != 0 {
+		return ErrInvalidData
+	}
+
+	*t = *u
+	return nil
+}
+
+// topKHeap is a min-heap of items by count that keeps index of key positions.
+type topKHeap struct {
+	items []Item
+	index map[string]int
+}
+
+func (h *topKHeap) Len() int {
+	return len(h.items)
+}
+
+func (h *topKHeap) Less(i, j int) bool {
+	return h.items[i].Count < h.items[j].Count
+}
+
+func (h *topKHeap) Swap(i, j int) {
+	h.items[i], h.items[j] = h.items[j], h.items[i]
+	h.index[h.items[i].Key] = i
+	h.index[h.items[j].Key] = j
+}
+
+func (h *topKHeap) Push(x interface{}) {
+	it := x.(Item)
+	h.index[it.Key] = len(h.items)
+	h.items = append(h.items, it)
+}
+
+func (h *topKHeap) Pop() interface{} {
+	n := len(h.items) - 1
+	it := h.items[n]
+	h.items = h.items[:n]
+	delete(h.index, it.Key)
+	return it
+}
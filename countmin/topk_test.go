@@ -0,0 +1,279 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package countmin
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math/rand"
+	"runtime"
+	"strconv"
+	"testing"
+)
+
+func newTestTopK(t testing.TB, k int) *TopK {
+	s, err := NewConservative(2000, 4, testSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	topk, err := NewTopK(k, s)
+	if err != nil {
+		t.Fatalf("NewTopK(%d) returned error %v", k, err)
+	}
+	return topk
+}
+
+// addZipf adds keys following Zipf's law in random order, with key i
+// having count 10000/(i+1).  Keys are offset by start.
+func addZipf(topk *TopK, n, start int, seed int64) {
+	var keys []int
+	for i := 0; i < n; i++ {
+		for j := 0; j < 10000/(i+1); j++ {
+			keys = append(keys, start+i)
+		}
+	}
+	r := rand.New(rand.NewSource(seed)) //nolint:gosec
+	r.Shuffle(len(keys), func(i, j int) { keys[i], keys[j] = keys[j], keys[i] })
+	for _, k := range keys {
+		topk.AddString(strconv.Itoa(k), 1)
+	}
+}
+
+func checkHeap(t *testing.T, topk *TopK) {
+	t.Helper()
+	if len(topk.index) != len(topk.heap.items) {
+		t.Fatalf("index has %d keys; heap has %d items", len(topk.index), len(topk.heap.items))
+	}
+	for i, it := range topk.heap.items {
+		if topk.index[it.Key] != i {
+			t.Fatalf("index[%q] = %d; want %d", it.Key, topk.index[it.Key], i)
+		}
+		if i > 0 && topk.heap.items[(i-1)/2].Count > it.Count {
+			t.Fatalf("heap property is violated at %d", i)
+		}
+	}
+}
+
+func TestNewTopKInvalid(t *testing.T) {
+	s, _ := New(100, 4, testSeed)
+	if _, err := NewTopK(0, s); err == nil {
+		t.Error("NewTopK(0) didn't return error")
+	}
+	if _, err := NewTopK(10, nil); err == nil {
+		t.Error("NewTopK(10, nil) didn't return error")
+	}
+}
+
+func TestTopK(t *testing.T) {
+	topk := newTestTopK(t, 10)
+	addZipf(topk, 1000, 0, 1)
+	checkHeap(t, topk)
+
+	list := topk.List()
+	if len(list) != 10 {
+		t.Fatalf("List() returned %d items; want 10", len(list))
+	}
+	for i, it := range list {
+		if it.Key != strconv.Itoa(i) {
+			t.Errorf("List()[%d].Key = %q; want %q", i, it.Key, strconv.Itoa(i))
+		}
+		if want := uint64(10000 / (i + 1)); it.Count < want || it.Count > want+want/10 {
+			t.Errorf("List()[%d].Count = %d; want about %d", i, it.Count, want)
+		}
+	}
+}
+
+func TestTopKBytes(t *testing.T) {
+	topk := newTestTopK(t, 2)
+	for i := 0; i < 10; i++ {
+		topk.Add([]byte("a"), 3)
+		topk.Add([]byte("b"), 2)
+		topk.Add([]byte("c"), 1)
+	}
+	list := topk.List()
+	if len(list) != 2 || list[0] != (Item{"a", 30}) || list[1] != (Item{"b", 20}) {
+		t.Errorf("List() = %v; want [{a 30} {b 20}]", list)
+	}
+}
+
+func TestTopKMerge(t *testing.T) {
+	// Heavy hitters are split between two trackers.
+	t1 := newTestTopK(t, 5)
+	t2 := newTestTopK(t, 5)
+	addZipf(t1, 500, 0, 1)
+	addZipf(t2, 500, 0, 2)
+	for i := 0; i < 5; i++ {
+		t2.AddString("hot"+strconv.Itoa(i), 100000)
+	}
+
+	if err := t1.Merge(t2); err != nil {
+		t.Fatalf("Merge() returned error %v", err)
+	}
+	checkHeap(t, t1)
+
+	list := t1.List()
+	if len(list) != 5 {
+		t.Fatalf("List() returned %d items; want 5", len(list))
+	}
+	for i, it := range list {
+		if it.Key != "hot"+strconv.Itoa(i) {
+			t.Errorf("List()[%d].Key = %q; want %q", i, it.Key, "hot"+strconv.Itoa(i))
+		}
+	}
+
+	t3 := newTestTopK(t, 5)
+	t3.sketch.seed = 1
+	if err := t1.Merge(t3); !errors.Is(err, ErrIncompatible) {
+		t.Errorf("Merge() with different seed returned error %v; want %v", err, ErrIncompatible)
+	}
+}
+
+func TestTopKMarshalBinary(t *testing.T) {
+	topk := newTestTopK(t, 10)
+	addZipf(topk, 100, 0, 1)
+
+	data, err := topk.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() returned error %v", err)
+	}
+
+	var topk2 TopK
+	if err := topk2.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary() returned error %v", err)
+	}
+	checkHeap(t, &topk2)
+
+	if topk2.K() != 10 {
+		t.Errorf("K() = %d; want 10", topk2.K())
+	}
+	list, list2 := topk.List(), topk2.List()
+	if len(list) != len(list2) {
+		t.Fatalf("List() of unmarshaled TopK returned %d items; want %d", len(list2), len(list))
+	}
+	for i := range list {
+		if list[i] != list2[i] {
+			t.Errorf("List()[%d] of unmarshaled TopK = %v; want %v", i, list2[i], list[i])
+		}
+	}
+
+	// Unmarshaled TopK continues tracking.
+	topk.AddString("new", 50000)
+	topk2.AddString("new", 50000)
+	if topk.List()[0] != topk2.List()[0] {
+		t.Errorf("List()[0] of unmarshaled TopK = %v; want %v", topk2.List()[0], topk.List()[0])
+	}
+
+	data2, _ := topk2.MarshalBinary()
+	data3, _ := topk.MarshalBinary()
+	if !bytes.Equal(data2, data3) {
+		t.Error("MarshalBinary() of unmarshaled TopK returned different data")
+	}
+}
+
+func TestTopKUnmarshalBinaryInvalid(t *testing.T) {
+	topk := newTestTopK(t, 3)
+	topk.AddString("a", 1)
+	topk.AddString("b", 2)
+	valid, _ := topk.MarshalBinary()
+
+	modify := func(fn func(b []byte)) []byte {
+		b := append([]byte(nil), valid...)
+		fn(b)
+		return b
+	}
+
+	testCases := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"bad magic", modify(func(b []byte) { b[0] = 'X' })},
+		{"bad version", modify(func(b []byte) { b[4] = 2 })},
+		{"zero k", modify(func(b []byte) { b[8] = 0 })},
+		{"more items than k", modify(func(b []byte) { b[12] = 4 })},
+		{"bad sketch size", modify(func(b []byte) { b[16]++ })},
+		{"bad sketch", modify(func(b []byte) { b[topKHeaderSize] = 'X' })},
+		{"truncated", valid[:len(valid)-1]},
+		{"extra data", append(append([]byte(nil), valid...), 0)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var topk TopK
+			if err := topk.UnmarshalBinary(tc.data); err == nil {
+				t.Error("UnmarshalBinary() didn't return error")
+			}
+		})
+	}
+}
+
+// allocBytes returns number of bytes allocated by f.
+func allocBytes(f func()) uint64 {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	f()
+	runtime.ReadMemStats(&after)
+	return after.TotalAlloc - before.TotalAlloc
+}
+
+func TestTopKUnmarshalBinaryHugeK(t *testing.T) {
+	const maxAlloc = 1 << 20
+
+	topk := newTestTopK(t, 3)
+	topk.AddString("a", 1)
+	topk.AddString("b", 2)
+	valid, _ := topk.MarshalBinary()
+
+	// Index is sized by number of items, not by k.
+	data := append([]byte(nil), valid...)
+	binary.LittleEndian.PutUint32(data[8:], 1<<24)
+
+	var u TopK
+	n := allocBytes(func() {
+		if err := u.UnmarshalBinary(data); err != nil {
+			t.Fatalf("UnmarshalBinary() with k 2^24 returned error %v", err)
+		}
+	})
+	if u.K() != 1<<24 || len(u.List()) != 2 {
+		t.Errorf("UnmarshalBinary() with k 2^24 returned k %d and %d items; want 16777216 and 2", u.K(), len(u.List()))
+	}
+	if n > maxAlloc {
+		t.Errorf("UnmarshalBinary() with k 2^24 allocated %d bytes; want at most %d", n, maxAlloc)
+	}
+
+	// Number of items is checked against data size before allocating.
+	binary.LittleEndian.PutUint32(data[12:], 1<<24)
+	n = allocBytes(func() {
+		if err := u.UnmarshalBinary(data); err == nil {
+			t.Error("UnmarshalBinary() with 2^24 items and no data didn't return error")
+		}
+	})
+	if n > maxAlloc {
+		t.Errorf("UnmarshalBinary() with 2^24 items and no data allocated %d bytes; want at most %d", n, maxAlloc)
+	}
+}
+
+func BenchmarkTopKAdd(b *testing.B) {
+	topk := newTestTopK(b, 100)
+	keys := make([][]byte, 1000)
+	for i := range keys {
+		keys[i] = []byte("client-" + strconv.Itoa(i))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		topk.Add(keys[i%len(keys)], 1)
+	}
+}
@@ -0,0 +1,487 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cuckoo implements cuckoo filters using CircleHash64f.
+//
+// Cuckoo filters support approximate membership like Bloom filters, and
+// also support deleting items that were inserted.
+//
+// Filter derives fingerprint and buckets of an item from one Hash64 digest:
+//
+//	h  = circlehash.Hash64(data, seed)
+//	fp = high fingerprintBits bits of h, or 1 if they are 0
+//	i1 = h mod numBuckets
+//	i2 = i1 xor (circlehash.Hash64Uint64x2(fp, 0, seed) mod numBuckets)
+//
+// where numBuckets is a power of 2.  Fingerprints are packed into a bit
+// array, so fingerprint size doesn't need to be a multiple of 8 bits.
+//
+// When both buckets of an item are full, Insert relocates existing
+// fingerprints to their alternate buckets for at most MaxKicks times.
+// If that fails, the last relocated fingerprint is kept in a small stash,
+// so no inserted item is lost.  Insert returns ErrFull when stash is full.
+package cuckoo
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/fxamacker/circlehash"
+)
+
+const (
+	// MaxKicks is maximum number of fingerprints relocated by one Insert.
+	MaxKicks = 500
+
+	// MaxStashSize is maximum number of fingerprints kept in stash.
+	MaxStashSize = 8
+
+	formatVersion = 1
+
+	// algorithmCircleHash64fPartialKey is the fingerprint and bucket derivation
+	// described in package documentation.
+	algorithmCircleHash64fPartialKey = 1
+
+	// headerSize is the size of serialized filter header:
+	// magic (4), version (1), algorithm (1), fingerprint bits (1), bucket size (1),
+	// seed (8), number of buckets (8), count (8), stash size (4), reserved (4).
+	headerSize = 40
+
+	// stashEntrySize is the size of serialized stash entry:
+	// fingerprint (4), bucket (8).
+	stashEntrySize = 12
+
+	maxFingerprintBits = 32
+	maxBucketSize      = 8
+	maxNumBuckets      = 1 << 32
+)
+
+var magic = [4]byte{'C', 'H', 'C', 'F'}
+
+var (
+	// ErrFull is returned by Insert when filter is too full to insert an item.
+	ErrFull = errors.New("cuckoo: filter is full")
+
+	// ErrInvalidData is returned by UnmarshalBinary when data isn't a valid serialized filter.
+	ErrInvalidData = errors.New("cuckoo: invalid serialized filter")
+)
+
+type stashEntry struct {
+	fp     uint32
+	bucket uint64
+}
+
+// Filter is a cuckoo filter.  It is not safe for concurrent use.
+type Filter struct {
+	seed       uint64
+	fpBits     uint
+	fpMask     uint64
+	bucketSize uint64
+	numBuckets uint64
+	count      uint64
+	words      []uint64 // packed fingerprints, 0 is empty slot
+	stash      []stashEntry
+}
+
+// New returns a Filter that can hold at least capacity items, with
+// fingerprintBits bits per fingerprint and bucketSize fingerprints per bucket.
+// Number of buckets is a power of 2 chosen so filter is at most about
+// 95% full (84% if bucketSize is 2, 50% if bucketSize is 1) at capacity.
+//
+// False positive rate at full load is about 2*bucketSize/2^fingerprintBits.
+// 8 to 16 bit fingerprints with 4 fingerprints per bucket are typical.
+func New(capacity uint64, fingerprintBits, bucketSize int, seed uint64) (*Filter, error) {
+	if bucketSize < 1 || bucketSize > maxBucketSize {
+		return nil, fmt.Errorf("cuckoo: bucket size %d is not in range [1, %d]", bucketSize, maxBucketSize)
+	}
+
+	maxLoad := 0.95
+	switch bucketSize {
+	case 1:
+		maxLoad = 0.5
+	case 2:
+		maxLoad = 0.84
+	}
+
+	n := uint64(float64(capacity)/(float64(bucketSize)*maxLoad)) + 1
+	numBuckets := uint64(1)
+	for numBuckets < n && numBuckets < maxNumBuckets {
+		numBuckets <<= 1
+	}
+	if numBuckets < n {
+		return nil, fmt.Errorf("cuckoo: capacity %d is too large", capacity)
+	}
+
+	return newFilter(numBuckets, fingerprintBits, bucketSize, seed)
+}
+
+func newFilter(numBuckets uint64, fingerprintBits, bucketSize int, seed uint64) (*Filter, error) {
+	if err := checkParams(numBuckets, fingerprintBits, bucketSize); err != nil {
+		return nil, err
+	}
+
+	f := &Filter{
+		seed:       seed,
+		fpBits:     uint(fingerprintBits),
+		fpMask:     1<<uint(fingerprintBits) - 1,
+		bucketSize: uint64(bucketSize),
+		numBuckets: numBuckets,
+	}
+	// Extra word lets fingerprints that span two words be read without bounds checks.
+	f.words = make([]uint64, f.numWords()+1)
+	return f, nil
+}
+
+// checkParams returns error if filter parameters are out of range.
+func checkParams(numBuckets uint64, fingerprintBits, bucketSize int) error {
+	if fingerprintBits < 2 || fingerprintBits > maxFingerprintBits {
+		return fmt.Errorf("cuckoo: fingerprint bits %d is not in range [2, %d]", fingerprintBits, maxFingerprintBits)
+	}
+	if bucketSize < 1 || bucketSize > maxBucketSize {
+		return fmt.Errorf("cuckoo: bucket size %d is not in range [1, %d]", bucketSize, maxBucketSize)
+	}
+	if numBuckets == 0 || numBuckets > maxNumBuckets || numBuckets&(numBuckets-1) != 0 {
+		return fmt.Errorf("cuckoo: number of buckets %d is not a power of 2 in range [1, 2^32]", numBuckets)
+	}
+	return nil
+}
+
+// numWords returns number of words used by packed fingerprints.
+func (f *Filter) numWords() uint64 {
+	return numWords(f.numBuckets, f.bucketSize, f.fpBits)
+}
+
+// numWords returns number of words used by packed fingerprints of
+// numBuckets buckets.  It doesn't overflow for valid parameters.
+func numWords(numBuckets, bucketSize uint64, fpBits uint) uint64 {
+	return (numBuckets*bucketSize*uint64(fpBits) + 63) / 64
+}
+
+// Count returns number of items in f.
+func (f *Filter) Count() uint64 {
+	return f.count
+}
+
+// Capacity returns number of fingerprint slots in f (excluding stash).
+func (f *Filter) Capacity() uint64 {
+	return f.numBuckets * f.bucketSize
+}
+
+// LoadFactor returns fraction of fingerprint slots in use.
+func (f *Filter) LoadFactor() float64 {
+	return float64(f.count-uint64(len(f.stash))) / float64(f.Capacity())
+}
+
+// Seed returns the seed used by f.
+func (f *Filter) Seed() uint64 {
+	return f.seed
+}
+
+// Insert inserts b into f.  Inserting the same item more than once is allowed,
+// and each copy must be deleted separately.  It returns ErrFull if f is full.
+func (f *Filter) Insert(b []byte) error {
+	return f.insert(circlehash.Hash64(b, f.seed))
+}
+
+// InsertString inserts s into f.
+func (f *Filter) InsertString(s string) error {
+	return f.insert(circlehash.Hash64String(s, f.seed))
+}
+
+// Lookup returns true if b may be in f, and false if b is definitely not in f.
+func (f *Filter) Lookup(b []byte) bool {
+	return f.lookup(circlehash.Hash64(b, f.seed))
+}
+
+// LookupString returns true if s may be in f, and false if s is definitely not in f.
+func (f *Filter) LookupString(s string) bool {
+	return f.lookup(circlehash.Hash64String(s, f.seed))
+}
+
+// Delete deletes one copy of b from f and returns true if it was found.
+// Only items that were inserted should be deleted, or a different item
+// with the same fingerprint and buckets may be deleted.
+func (f *Filter) Delete(b []byte) bool {
+	return f.delete(circlehash.Hash64(b, f.seed))
+}
+
+// DeleteString deletes one copy of s from f and returns true if it was found.
+func (f *Filter) DeleteString(s string) bool {
+	return f.delete(circlehash.Hash64String(s, f.seed))
+}
+
+// Reset removes all items from f.
+func (f *Filter) Reset() {
+	for i := range f.words {
+		f.words[i] = 0
+	}
+	f.stash = f.stash[:0]
+	f.count = 0
+}
+
+// fingerprintAndBucket returns fingerprint and first bucket of digest h.
+func (f *Filter) fingerprintAndBucket(h uint64) (uint32, uint64) {
+	fp := uint32(h >> (64 - f.fpBits))
+	if fp == 0 {
+		fp = 1
+	}
+	return fp, h & (f.numBuckets - 1)
+}
+
+// altBucket returns the other bucket of fingerprint fp in bucket i.
+func (f *Filter) altBucket(i uint64, fp uint32) uint64 {
+	return (i ^ circlehash.Hash64Uint64x2(uint64(fp), 0, f.seed)) & (f.numBuckets - 1)
+}
+
+func (f *Filter) insert(h uint64) error {
+	fp, i1 := f.fingerprintAndBucket(h)
+	i2 := f.altBucket(i1, fp)
+
+	if f.insertIntoBucket(i1, fp) || f.insertIntoBucket(i2, fp) {
+		f.count++
+		return nil
+	}
+
+	if len(f.stash) >= MaxStashSize {
+		return ErrFull
+	}
+
+	// Relocate fingerprints using a generator seeded by h, so results are
+	// deterministic for the same sequence of operations.
+	rng := h | 1
+	next := func() uint64 {
+		rng ^= rng >> 12
+		rng ^= rng << 25
+		rng ^= rng >> 27
+		return rng * 0x2545F4914F6CDD1D
+	}
+
+	i := i1
+	if next()&1 == 1 {
+		i = i2
+	}
+	for n := 0; n < MaxKicks; n++ {
+		slot := i*f.bucketSize + next()%f.bucketSize
+		victim := f.getSlot(slot)
+		f.setSlot(slot, fp)
+		fp = victim
+
+		i = f.altBucket(i, fp)
+		if f.insertIntoBucket(i, fp) {
+			f.count++
+			return nil
+		}
+	}
+
+	f.stash = append(f.stash, stashEntry{fp: fp, bucket: i})
+	f.count++
+	return nil
+}
+
+func (f *Filter) lookup(h uint64) bool {
+	fp, i1 := f.fingerprintAndBucket(h)
+	i2 := f.altBucket(i1, fp)
+
+	if f.findInBucket(i1, fp) >= 0 || f.findInBucket(i2, fp) >= 0 {
+		return true
+	}
+	return f.findInStash(fp, i1, i2) >= 0
+}
+
+func (f *Filter) delete(h uint64) bool {
+	fp, i1 := f.fingerprintAndBucket(h)
+	i2 := f.altBucket(i1, fp)
+
+	if k := f.findInStash(fp, i1, i2); k >= 0 {
+		f.stash = append(f.stash[:k], f.stash[k+1:]...)
+		f.count--
+		return true
+	}
+
+	for _, i := range [2]uint64{i1, i2} {
+		if j := f.findInBucket(i, fp); j >= 0 {
+			f.setSlot(i*f.bucketSize+uint64(j), 0)
+			f.count--
+			f.drainStash()
+			return true
+		}
+	}
+	return false
+}
+
+// drainStash moves stashed fingerprints back to their buckets if there is room.
+func (f *Filter) drainStash() {
+	n := 0
+	for _, e := range f.stash {
+		if f.insertIntoBucket(e.bucket, e.fp) || f.insertIntoBucket(f.altBucket(e.bucket, e.fp), e.fp) {
+			continue
+		}
+		f.stash[n] = e
+		n++
+	}
+	f.stash = f.stash[:n]
+}
+
+func (f *Filter) insertIntoBucket(i uint64, fp uint32) bool {
+	slot := i * f.bucketSize
+	for j := uint64(0); j < f.bucketSize; j++ {
+		if f.getSlot(slot+j) == 0 {
+			f.setSlot(slot+j, fp)
+			return true
+		}
+	}
+	return false
+}
+
+func (f *Filter) findInBucket(i uint64, fp uint32) int {
+	slot := i * f.bucketSize
+	for j := uint64(0); j < f.bucketSize; j++ {
+		if f.getSlot(slot+j) == fp {
+			return int(j)
+		}
+	}
+	return -1
+}
+
+func (f *Filter) findInStash(fp uint32, i1, i2 uint64) int {
+	for k, e := range f.stash {
+		if e.fp == fp && (e.bucket == i1 || e.bucket == i2) {
+			return k
+		}
+	}
+	return -1
+}
+
+func (f *Filter) getSlot(slot uint64) uint32 {
+	bit := slot * uint64(f.fpBits)
+	w, off := bit/64, uint(bit%64)
+	v := f.words[w] >> off
+	if off+f.fpBits > 64 {
+		v |= f.words[w+1] << (64 - off)
+	}
+	return uint32(v & f.fpMask)
+}
+
+func (f *Filter) setSlot(slot uint64, fp uint32) {
+	bit := slot * uint64(f.fpBits)
+	w, off := bit/64, uint(bit%64)
+	f.words[w] = f.words[w]&^(f.fpMask<<off) | uint64(fp)<<off
+	if off+f.fpBits > 64 {
+		shift := 64 - off
+		f.words[w+1] = f.words[w+1]&^(f.fpMask>>shift) | uint64(fp)>>shift
+	}
+}
+
+// MarshalBinary returns serialized f.  Serialized filter records format
+// version, fingerprint and bucket algorithm, parameters, seed, and stash.
+func (f *Filter) MarshalBinary() ([]byte, error) {
+	numWords := f.numWords()
+	data := make([]byte, headerSize+stashEntrySize*len(f.stash)+8*int(numWords))
+
+	copy(data, magic[:])
+	data[4] = formatVersion
+	data[5] = algorithmCircleHash64fPartialKey
+	data[6] = byte(f.fpBits)
+	data[7] = byte(f.bucketSize)
+	binary.LittleEndian.PutUint64(data[8:], f.seed)
+	binary.LittleEndian.PutUint64(data[16:], f.numBuckets)
+	binary.LittleEndian.PutUint64(data[24:], f.count)
+	binary.LittleEndian.PutUint32(data[32:], uint32(len(f.stash)))
+
+	off := headerSize
+	for _, e := range f.stash {
+		binary.LittleEndian.PutUint32(data[off:], e.fp)
+		binary.LittleEndian.PutUint64(data[off+4:], e.bucket)
+		off += stashEntrySize
+	}
+	for _, w := range f.words[:numWords] {
+		binary.LittleEndian.PutUint64(data[off:], w)
+		off += 8
+	}
+	return data, nil
+}
+
+// UnmarshalBinary sets f to filter serialized by MarshalBinary.
+func (f *Filter) UnmarshalBinary(data []byte) error {
+	if len(data) < headerSize || [4]byte{data[0], data[1], data[2], data[3]} != magic {
+		return ErrInvalidData
+	}
+	if data[4] != formatVersion {
+		return fmt.Errorf("cuckoo: unsupported format version %d", data[4])
+	}
+	if data[5] != algorithmCircleHash64fPartialKey {
+		return fmt.Errorf("cuckoo: unsupported algorithm %d", data[5])
+	}
+
+	seed := binary.LittleEndian.Uint64(data[8:])
+	numBuckets := binary.LittleEndian.Uint64(data[16:])
+	count := binary.LittleEndian.Uint64(data[24:])
+	stashSize := binary.LittleEndian.Uint32(data[32:])
+
+	fingerprintBits, bucketSize := int(data[6]), int(data[7])
+
+	// Check data size before allocating words, so untrusted header can't
+	// allocate more memory than data size.
+	if err := checkParams(numBuckets, fingerprintBits, bucketSize); err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidData, err)
+	}
+	if stashSize > MaxStashSize {
+		return ErrInvalidData
+	}
+	words := numWords(numBuckets, uint64(bucketSize), uint(fingerprintBits))
+	if uint64(len(data)-headerSize) != stashEntrySize*uint64(stashSize)+8*words {
+		return ErrInvalidData
+	}
+
+	g, err := newFilter(numBuckets, fingerprintBits, bucketSize, seed)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidData, err)
+	}
+
+	off := headerSize
+	for k := uint32(0); k < stashSize; k++ {
+		e := stashEntry{
+			fp:     binary.LittleEndian.Uint32(data[off:]),
+			bucket: binary.LittleEndian.Uint64(data[off+4:]),
+		}
+		if e.fp == 0 || uint64(e.fp) > g.fpMask || e.bucket >= numBuckets {
+			return ErrInvalidData
+		}
+		g.stash = append(g.stash, e)
+		off += stashEntrySize
+	}
+	for i := uint64(0); i < words; i++ {
+		g.words[i] = binary.LittleEndian.Uint64(data[off:])
+		off += 8
+	}
+
+	// Verify count and that unused bits are 0.
+	if usedBits := g.Capacity() * uint64(g.fpBits) % 64; usedBits != 0 && g.words[words-1]>>usedBits != 0 {
+		return ErrInvalidData
+	}
+	n := uint64(len(g.stash))
+	for slot := uint64(0); slot < g.Capacity(); slot++ {
+		if g.getSlot(slot) != 0 {
+			n++
+		}
+	}
+	if n != count {
+		return ErrInvalidData
+	}
+	g.count = count
+
+	*f = *g
+	return nil
+}
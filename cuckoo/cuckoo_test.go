@@ -0,0 +1,392 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cuckoo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"testing"
+)
+
+const testSeed = uint64(0x9E3779B97F4A7C15)
+
+func newTestFilter(t *testing.T, capacity uint64, fpBits, bucketSize int) *Filter {
+	f, err := New(capacity, fpBits, bucketSize, testSeed)
+	if err != nil {
+		t.Fatalf("New(%d, %d, %d) returned error %v", capacity, fpBits, bucketSize, err)
+	}
+	return f
+}
+
+func TestNewInvalid(t *testing.T) {
+	testCases := []struct {
+		capacity   uint64
+		fpBits     int
+		bucketSize int
+	}{
+		{1000, 1, 4},
+		{1000, 33, 4},
+		{1000, 8, 0},
+		{1000, 8, 9},
+		{math.MaxUint64, 8, 4},
+	}
+	for _, tc := range testCases {
+		if _, err := New(tc.capacity, tc.fpBits, tc.bucketSize, testSeed); err == nil {
+			t.Errorf("New(%d, %d, %d) didn't return error", tc.capacity, tc.fpBits, tc.bucketSize)
+		}
+	}
+}
+
+func TestSlots(t *testing.T) {
+	for _, fpBits := range []int{2, 7, 8, 12, 13, 16, 31, 32} {
+		f, err := newFilter(16, fpBits, 4, testSeed)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		n := f.Capacity()
+		for slot := uint64(0); slot < n; slot++ {
+			f.setSlot(slot, uint32((slot*0x9E3779B9+1)&f.fpMask))
+		}
+		for slot := uint64(0); slot < n; slot++ {
+			want := uint32((slot*0x9E3779B9 + 1) & f.fpMask)
+			if got := f.getSlot(slot); got != want {
+				t.Errorf("fpBits %d: getSlot(%d) = 0x%x; want 0x%x", fpBits, slot, got, want)
+			}
+		}
+		if f.words[len(f.words)-1] != 0 {
+			t.Errorf("fpBits %d: padding word = 0x%x; want 0", fpBits, f.words[len(f.words)-1])
+		}
+	}
+}
+
+func TestInsertLookup(t *testing.T) {
+	const n = 10000
+
+	for _, bucketSize := range []int{1, 2, 4, 8} {
+		for _, fpBits := range []int{7, 12, 16} {
+			t.Run(fmt.Sprintf("b=%d,f=%d", bucketSize, fpBits), func(t *testing.T) {
+				f := newTestFilter(t, n, fpBits, bucketSize)
+				for i := 0; i < n; i++ {
+					if err := f.InsertString(strconv.Itoa(i)); err != nil {
+						t.Fatalf("InsertString(%d) returned error %v at load factor %v", i, err, f.LoadFactor())
+					}
+				}
+				if f.Count() != n {
+					t.Errorf("Count() = %d; want %d", f.Count(), n)
+				}
+				for i := 0; i < n; i++ {
+					if !f.Lookup([]byte(strconv.Itoa(i))) {
+						t.Fatalf("Lookup(%d) = false after Insert", i)
+					}
+				}
+			})
+		}
+	}
+}
+
+// theoreticalFalsePositiveRate returns probability that a fingerprint
+// matches any fingerprint in two buckets of f.
+func theoreticalFalsePositiveRate(f *Filter) float64 {
+	// Fingerprints are in range [1, 2^fpBits-1].
+	expectedEntries := 2 * float64(f.bucketSize) * f.LoadFactor()
+	return 1 - math.Pow(1-1/float64(f.fpMask), expectedEntries)
+}
+
+func TestFalsePositiveRate(t *testing.T) {
+	const n = 50000
+	const trials = 1000000
+
+	testCases := []struct {
+		fpBits     int
+		bucketSize int
+	}{
+		{8, 4},
+		{12, 4},
+		{8, 2},
+		{10, 8},
+		{16, 4},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("b=%d,f=%d", tc.bucketSize, tc.fpBits), func(t *testing.T) {
+			f := newTestFilter(t, n, tc.fpBits, tc.bucketSize)
+			for i := 0; i < n; i++ {
+				if err := f.InsertString("key" + strconv.Itoa(i)); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			fp := 0
+			for i := 0; i < trials; i++ {
+				if f.LookupString("other" + strconv.Itoa(i)) {
+					fp++
+				}
+			}
+
+			got := float64(fp) / trials
+			want := theoreticalFalsePositiveRate(f)
+			if got > want*1.2 || got < want/1.2 {
+				t.Errorf("false positive rate = %v at load factor %v; want about %v", got, f.LoadFactor(), want)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	const n = 5000
+
+	f := newTestFilter(t, n, 16, 4)
+	for i := 0; i < n; i++ {
+		_ = f.InsertString(strconv.Itoa(i))
+	}
+
+	for i := 0; i < n; i += 2 {
+		if !f.Delete([]byte(strconv.Itoa(i))) {
+			t.Fatalf("Delete(%d) = false", i)
+		}
+	}
+	if f.Count() != n/2 {
+		t.Errorf("Count() = %d; want %d", f.Count(), n/2)
+	}
+
+	found := 0
+	for i := 0; i < n; i++ {
+		ok := f.LookupString(strconv.Itoa(i))
+		if i%2 == 1 && !ok {
+			t.Fatalf("LookupString(%d) = false for item that wasn't deleted", i)
+		}
+		if i%2 == 0 && ok {
+			found++
+		}
+	}
+	if found > 5 {
+		t.Errorf("%d of %d deleted items are found", found, n/2)
+	}
+
+	for i := 1; i < n; i += 2 {
+		if !f.DeleteString(strconv.Itoa(i)) {
+			t.Fatalf("DeleteString(%d) = false", i)
+		}
+	}
+	if f.Count() != 0 {
+		t.Errorf("Count() = %d; want 0", f.Count())
+	}
+	for _, w := range f.words {
+		if w != 0 {
+			t.Fatal("filter isn't empty after deleting all items")
+		}
+	}
+}
+
+func TestDuplicates(t *testing.T) {
+	f := newTestFilter(t, 100, 12, 4)
+
+	for i := 0; i < 3; i++ {
+		if err := f.InsertString("dup"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := 0; i < 3; i++ {
+		if !f.LookupString("dup") {
+			t.Fatalf("LookupString() = false after deleting %d of 3 copies", i)
+		}
+		if !f.DeleteString("dup") {
+			t.Fatalf("DeleteString() = false after deleting %d of 3 copies", i)
+		}
+	}
+	if f.LookupString("dup") {
+		t.Error("LookupString() = true after deleting all copies")
+	}
+	if f.DeleteString("dup") {
+		t.Error("DeleteString() = true after deleting all copies")
+	}
+}
+
+func TestFullAndStash(t *testing.T) {
+	f, err := newFilter(16, 12, 2, testSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var inserted []string
+	for i := 0; ; i++ {
+		s := strconv.Itoa(i)
+		err := f.InsertString(s)
+		if errors.Is(err, ErrFull) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("InsertString(%q) returned error %v", s, err)
+		}
+		inserted = append(inserted, s)
+	}
+
+	if len(f.stash) != MaxStashSize {
+		t.Errorf("stash has %d entries when full; want %d", len(f.stash), MaxStashSize)
+	}
+	if f.Count() != uint64(len(inserted)) {
+		t.Errorf("Count() = %d; want %d", f.Count(), len(inserted))
+	}
+	for _, s := range inserted {
+		if !f.LookupString(s) {
+			t.Fatalf("LookupString(%q) = false for inserted item in full filter", s)
+		}
+	}
+
+	// Deleting items frees room for stashed fingerprints.
+	for _, s := range inserted[:len(inserted)/2] {
+		if !f.DeleteString(s) {
+			t.Fatalf("DeleteString(%q) = false", s)
+		}
+	}
+	if len(f.stash) != 0 {
+		t.Errorf("stash has %d entries after deleting half of items; want 0", len(f.stash))
+	}
+	for _, s := range inserted[len(inserted)/2:] {
+		if !f.LookupString(s) {
+			t.Fatalf("LookupString(%q) = false after draining stash", s)
+		}
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	f, _ := newFilter(16, 13, 2, testSeed)
+	var inserted []string
+	for i := 0; f.InsertString(strconv.Itoa(i)) == nil; i++ {
+		inserted = append(inserted, strconv.Itoa(i))
+	}
+
+	data, err := f.MarshalBinary()
+	if err != nil {
+		t.Fatalf("MarshalBinary() returned error %v", err)
+	}
+
+	var g Filter
+	if err := g.UnmarshalBinary(data); err != nil {
+		t.Fatalf("UnmarshalBinary() returned error %v", err)
+	}
+	if g.Count() != f.Count() || len(g.stash) != len(f.stash) || g.Seed() != f.Seed() {
+		t.Errorf("UnmarshalBinary() returned filter with count %d, stash %d, seed 0x%x; want %d, %d, 0x%x",
+			g.Count(), len(g.stash), g.Seed(), f.Count(), len(f.stash), f.Seed())
+	}
+	for _, s := range inserted {
+		if !g.LookupString(s) {
+			t.Fatalf("unmarshaled filter doesn't contain %q", s)
+		}
+	}
+
+	data2, _ := g.MarshalBinary()
+	if !bytes.Equal(data, data2) {
+		t.Error("MarshalBinary() of unmarshaled filter returned different data")
+	}
+
+	// Unmarshaled filter supports delete.
+	for _, s := range inserted {
+		if !g.DeleteString(s) {
+			t.Fatalf("DeleteString(%q) = false for unmarshaled filter", s)
+		}
+	}
+	if g.Count() != 0 {
+		t.Errorf("Count() = %d after deleting all items; want 0", g.Count())
+	}
+}
+
+func TestUnmarshalBinaryInvalid(t *testing.T) {
+	f, _ := newFilter(4, 12, 4, testSeed)
+	_ = f.InsertString("hello")
+	valid, _ := f.MarshalBinary()
+
+	modify := func(fn func(b []byte)) []byte {
+		b := append([]byte(nil), valid...)
+		fn(b)
+		return b
+	}
+
+	testCases := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"short header", valid[:headerSize-1]},
+		{"bad magic", modify(func(b []byte) { b[0] = 'X' })},
+		{"bad version", modify(func(b []byte) { b[4] = 2 })},
+		{"bad algorithm", modify(func(b []byte) { b[5] = 0 })},
+		{"bad fingerprint bits", modify(func(b []byte) { b[6] = 33 })},
+		{"bad bucket size", modify(func(b []byte) { b[7] = 0 })},
+		{"bad number of buckets", modify(func(b []byte) { b[16] = 3 })},
+		{"bad count", modify(func(b []byte) { b[24] = 2 })},
+		{"bad stash size", modify(func(b []byte) { b[32] = MaxStashSize + 1 })},
+		{"truncated", valid[:len(valid)-1]},
+		{"extra data", append(append([]byte(nil), valid...), 0)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var g Filter
+			if err := g.UnmarshalBinary(tc.data); err == nil {
+				t.Error("UnmarshalBinary() didn't return error")
+			}
+		})
+	}
+}
+
+func TestUnmarshalBinaryHugeHeader(t *testing.T) {
+	f, _ := newFilter(4, 12, 4, testSeed)
+	data, _ := f.MarshalBinary()
+	data = data[:headerSize]
+
+	// Header declares 2^32 buckets of 8 32-bit fingerprints (32 GiB)
+	// and a full stash without data.
+	data[6] = maxFingerprintBits
+	data[7] = maxBucketSize
+	binary.LittleEndian.PutUint64(data[16:], maxNumBuckets)
+	binary.LittleEndian.PutUint32(data[32:], MaxStashSize)
+
+	var g Filter
+	allocs := testing.AllocsPerRun(10, func() {
+		if err := g.UnmarshalBinary(data); err == nil {
+			t.Error("UnmarshalBinary() with huge header and no data didn't return error")
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("UnmarshalBinary() with huge header and no data allocated %v times; want 0", allocs)
+	}
+}
+
+func BenchmarkInsertDelete(b *testing.B) {
+	f, _ := New(1000000, 16, 4, testSeed)
+	key := []byte("session-0123456789abcdef")
+	for i := 0; i < b.N; i++ {
+		_ = f.Insert(key)
+		f.Delete(key)
+	}
+}
+
+func BenchmarkLookup(b *testing.B) {
+	f, _ := New(1000000, 16, 4, testSeed)
+	for i := 0; i < 900000; i++ {
+		_ = f.InsertString(strconv.Itoa(i))
+	}
+	key := []byte("session-0123456789abcdef")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = f.Lookup(key)
+	}
+}
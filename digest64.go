@@ -0,0 +1,238 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package circlehash
+
+import (
+	"unsafe"
+)
+
+// Digest64 is the streaming state of CircleHash64f.  It implements hash.Hash64.
+// Digest is compatible with Hash64 of all data written so far.
+//
+// The zero value uses seed 0.  Use NewDigest64 to specify another seed.
+type Digest64 struct {
+	seed            uint64
+	currentState    uint64
+	duplicatedState uint64
+	length          uint64 // total number of bytes written
+
+	// buf holds the last 1-64 bytes written.  A 64-byte chunk is only
+	// processed after more data is written because CircleHash64f
+	// processes the final 1-64 bytes differently.
+	buf  [64]byte
+	nbuf int
+
+	initialized bool
+}
+
+// NewDigest64 returns a streaming CircleHash64f state using seed.
+func NewDigest64(seed uint64) *Digest64 {
+	d := &Digest64{seed: seed}
+	d.Reset()
+	return d
+}
+
+// Reset resets d to its initial state with the same seed.
+func (d *Digest64) Reset() {
+	d.currentState = d.seed ^ pi0
+	d.duplicatedState = d.currentState
+	d.length = 0
+	d.nbuf = 0
+	d.initialized = true
+}
+
+// Size returns the number of bytes Sum will append.
+func (d *Digest64) Size() int {
+	return 8
+}
+
+// BlockSize returns the block size of CircleHash64f.
+func (d *Digest64) BlockSize() int {
+	return 64
+}
+
+// Write adds b to the running digest.  It always returns len(b), nil.
+func (d *Digest64) Write(b []byte) (int, error) {
+	d.write(*(*unsafe.Pointer)(unsafe.Pointer(&b)), len(b))
+	return len(b), nil
+}
+
+// WriteString adds s to the running digest.  It always returns len(s), nil.
+func (d *Digest64) WriteString(s string) (int, error) {
+	d.write(*(*unsafe.Pointer)(unsafe.Pointer(&s)), len(s))
+	return len(s), nil
+}
+
+// WriteByte adds c to the running digest.  It always returns nil.
+func (d *Digest64) WriteByte(c byte) error {
+	if !d.initialized {
+		d.Reset()
+	}
+	if d.nbuf == len(d.buf) {
+		d.processChunk(unsafe.Pointer(&d.buf))
+		d.nbuf = 0
+	}
+	d.buf[d.nbuf] = c
+	d.nbuf++
+	d.length++
+	return nil
+}
+
+// WriteUint64 adds v encoded in little-endian order (8 bytes) to the
+// running digest.
+func (d *Digest64) WriteUint64(v uint64) {
+	var b [8]byte
+	b[0] = byte(v)
+	b[1] = byte(v >> 8)
+	b[2] = byte(v >> 16)
+	b[3] = byte(v >> 24)
+	b[4] = byte(v >> 32)
+	b[5] = byte(v >> 40)
+	b[6] = byte(v >> 48)
+	b[7] = byte(v >> 56)
+	d.write(unsafe.Pointer(&b), len(b))
+}
+
+// Sum appends the current digest to b in big-endian order
+// (same as hash/fnv and hash/crc64) and returns the resulting slice.
+// It doesn't change the underlying hash state.
+func (d *Digest64) Sum(b []byte) []byte {
+	s := d.Sum64()
+	return append(b, byte(s>>56), byte(s>>48), byte(s>>40), byte(s>>32), byte(s>>24), byte(s>>16), byte(s>>8), byte(s))
+}
+
+// Sum64 returns the current digest.
+// It doesn't change the underlying hash state.
+func (d *Digest64) Sum64() uint64 {
+	if !d.initialized {
+		d.Reset()
+	}
+
+	currentState := d.currentState
+	if d.length > 64 {
+		currentState ^= d.duplicatedState
+	}
+
+	p := unsafe.Pointer(&d.buf)
+	dlen := uint64(d.nbuf)
+
+	// We have at most 64 bytes to process.
+	// Process chunks of 16 bytes
+	for ; dlen > 16; dlen -= 16 {
+		a := readUnaligned64(p)
+		b := readUnaligned64(add(p, 8))
+
+		currentState = mix64(a^pi1, b^currentState)
+
+		p = add(p, 16)
+	}
+
+	// We have at most 16 bytes to process.
+
+	// a and b are 0 for default case of dlen == 0
+	a := uint64(0)
+	b := uint64(0)
+
+	switch {
+	case dlen > 8:
+		// We have 9-16 bytes to process.
+		// a and b might overlap.
+		a = readUnaligned64(p)
+		b = readUnaligned64(add(p, uintptr(dlen-8)))
+
+	case dlen > 3:
+		// We have 4-8 bytes to process.
+		// a and b might overlap.
+		a = uint64(readUnaligned32(p))
+		b = uint64(readUnaligned32(add(p, uintptr(dlen-4))))
+
+	case dlen > 0:
+		// We have 1-3 bytes to process.
+		a = uint64(*(*byte)(p)) << 16
+		a |= uint64(*(*byte)(add(p, uintptr(dlen>>1)))) << 8
+		a |= uint64(*(*byte)(add(p, uintptr(dlen-1))))
+		// b is 0, so we don't need to set it to 0 again
+	}
+
+	// We use pi1 and pi4 during finalization (abseil and wyhash reuses same const)
+	w := mix64(a^pi1, b^currentState)
+	z := pi4 ^ d.length
+	return mix64(w, z)
+}
+
+// write adds n bytes at p to the running digest.
+func (d *Digest64) write(p unsafe.Pointer, n int) {
+	if !d.initialized {
+		d.Reset()
+	}
+	if n == 0 {
+		return
+	}
+
+	d.length += uint64(n)
+
+	// Fill buffer and process it if more data follows.
+	if d.nbuf > 0 {
+		c := copyFrom(d.buf[d.nbuf:], p, n)
+		d.nbuf += c
+		if c == n {
+			return
+		}
+		p = add(p, uintptr(c))
+		n -= c
+		d.processChunk(unsafe.Pointer(&d.buf))
+		d.nbuf = 0
+	}
+
+	// Process chunks of 64 bytes directly from input, keeping
+	// at least 1 byte for the buffer.
+	for ; n > 64; n -= 64 {
+		d.processChunk(p)
+		p = add(p, 64)
+	}
+
+	d.nbuf = copyFrom(d.buf[:], p, n)
+}
+
+// copyFrom copies up to n bytes at p to dst and returns number of bytes copied.
+func copyFrom(dst []byte, p unsafe.Pointer, n int) int {
+	if n > len(dst) {
+		n = len(dst)
+	}
+	for i := 0; i < n; i++ {
+		dst[i] = *(*byte)(add(p, uintptr(i)))
+	}
+	return n
+}
+
+// processChunk processes 64 bytes at p.
+func (d *Digest64) processChunk(p unsafe.Pointer) {
+	a := readUnaligned64(p)
+	b := readUnaligned64(add(p, 8))
+	c := readUnaligned64(add(p, 16))
+	e := readUnaligned64(add(p, 24))
+	f := readUnaligned64(add(p, 32))
+	g := readUnaligned64(add(p, 40))
+	h := readUnaligned64(add(p, 48))
+	i := readUnaligned64(add(p, 56))
+
+	cs0 := mix64(a^pi1, b^d.currentState)
+	cs1 := mix64(c^pi2, e^d.currentState)
+	d.currentState = (cs0 ^ cs1)
+
+	ds0 := mix64(f^pi3, g^d.duplicatedState)
+	ds1 := mix64(h^pi4, i^d.duplicatedState)
+	d.duplicatedState = (ds0 ^ ds1)
+}
@@ -0,0 +1,145 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package circlehash
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash"
+	"testing"
+)
+
+var _ hash.Hash64 = (*Digest64)(nil)
+
+func TestDigest64(t *testing.T) {
+	data := nonUniformBytes16KiB()[:1024]
+
+	// Write data in chunks of various sizes to cover all buffering code paths.
+	chunkSizes := []int{1, 3, 7, 16, 63, 64, 65, 100, 1024}
+
+	for _, seed := range []uint64{numsAllZeros, numsAll55s, numsAllFFs, numsGoldenRatio} {
+		for n := 0; n <= len(data); n++ {
+			want := Hash64(data[:n], seed)
+
+			for _, chunkSize := range chunkSizes {
+				d := NewDigest64(seed)
+				for i := 0; i < n; i += chunkSize {
+					end := i + chunkSize
+					if end > n {
+						end = n
+					}
+					if i%2 == 0 {
+						d.Write(data[i:end])
+					} else {
+						d.WriteString(string(data[i:end]))
+					}
+				}
+
+				if got := d.Sum64(); got != want {
+					t.Errorf("Digest64 with %d-byte writes of %d bytes = 0x%016x; want 0x%016x", chunkSize, n, got, want)
+				}
+			}
+		}
+	}
+}
+
+func TestDigest64WriteByte(t *testing.T) {
+	data := nonUniformBytes16KiB()[:300]
+	seed := numsGoldenRatio
+
+	d := NewDigest64(seed)
+	for i := 0; i < len(data); i++ {
+		if got, want := d.Sum64(), Hash64(data[:i], seed); got != want {
+			t.Errorf("Digest64 with WriteByte of %d bytes = 0x%016x; want 0x%016x", i, got, want)
+		}
+		d.WriteByte(data[i])
+	}
+}
+
+func TestDigest64WriteUint64(t *testing.T) {
+	seed := numsGoldenRatio
+	vs := []uint64{numsAllZeros, numsAll55s, numsAllAAs, numsAllFFs, numsGoldenRatio, numsGoldenRatioInv, 1, 2, 3, 4}
+
+	b := make([]byte, 0, len(vs)*8)
+	d := NewDigest64(seed)
+	for _, v := range vs {
+		d.WriteUint64(v)
+		var buf [8]byte
+		binary.LittleEndian.PutUint64(buf[:], v)
+		b = append(b, buf[:]...)
+
+		if got, want := d.Sum64(), Hash64(b, seed); got != want {
+			t.Errorf("Digest64 with WriteUint64 of %d bytes = 0x%016x; want 0x%016x", len(b), got, want)
+		}
+	}
+}
+
+func TestDigest64ZeroValue(t *testing.T) {
+	data := []byte("hello")
+
+	var d Digest64
+	d.Write(data)
+
+	if got, want := d.Sum64(), Hash64(data, 0); got != want {
+		t.Errorf("zero Digest64 Sum64() = 0x%016x; want 0x%016x", got, want)
+	}
+}
+
+func TestDigest64Reset(t *testing.T) {
+	data := nonUniformBytes16KiB()[:200]
+	seed := numsGoldenRatio
+
+	d := NewDigest64(seed)
+	d.Write(data)
+	d.Reset()
+	d.Write(data[:100])
+
+	if got, want := d.Sum64(), Hash64(data[:100], seed); got != want {
+		t.Errorf("Digest64 after Reset() = 0x%016x; want 0x%016x", got, want)
+	}
+}
+
+func TestDigest64Sum(t *testing.T) {
+	data := []byte("hello")
+	seed := numsGoldenRatio
+
+	d := NewDigest64(seed)
+	d.Write(data)
+
+	want := make([]byte, 14)
+	copy(want, "prefix")
+	binary.BigEndian.PutUint64(want[6:], Hash64(data, seed))
+	if got := d.Sum([]byte("prefix")); !bytes.Equal(got, want) {
+		t.Errorf("Digest64 Sum() = 0x%x; want 0x%x", got, want)
+	}
+
+	if d.Size() != 8 {
+		t.Errorf("Digest64 Size() = %d; want 8", d.Size())
+	}
+	if d.BlockSize() != 64 {
+		t.Errorf("Digest64 BlockSize() = %d; want 64", d.BlockSize())
+	}
+}
+
+func BenchmarkDigest64(b *testing.B) {
+	data := nonUniformBytes16KiB()[:1024]
+	b.SetBytes(int64(len(data)))
+	d := NewDigest64(numsGoldenRatio)
+	for i := 0; i < b.N; i++ {
+		d.Reset()
+		d.Write(data)
+		_ = d.Sum64()
+	}
+}
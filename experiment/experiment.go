@@ -0,0 +1,247 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package experiment implements deterministic assignment of units (such as
+// user IDs) to experiment arms using CircleHash64f.
+//
+// Unit is mapped to a uniform value u in [0, 1) for experiment salt:
+//
+//	saltSeed = circlehash.Hash64String(salt, seed)
+//	h        = circlehash.Hash64String(unit, saltSeed)
+//	u        = float64(h>>11) / (1<<53)
+//
+// Unit is assigned to arm i if u*W is in [W_0+...+W_(i-1), W_0+...+W_i),
+// where W_i is weight of arm i and W is sum of weights.
+//
+// Exposure (used to ramp up experiments) is decided by a second value
+// computed the same way with an independent seed:
+//
+//	exposureSeed = circlehash.Hash64Uint64x2(saltSeed, 1, seed)
+//	h            = circlehash.Hash64String(unit, exposureSeed)
+//
+// Unit is exposed if its exposure value is less than exposure of experiment.
+// Because arm doesn't depend on exposure, increasing exposure adds new units
+// to experiment without moving exposed units to other arms.  Changing arms
+// or weights reassigns units.
+//
+// Experiments in the same Layer are mutually exclusive: each unit is placed
+// in at most one experiment of layer, based on uniform value for layer salt.
+// Experiments in different layers (with different salts) are independent.
+//
+// Digests and mappings are stable and don't change between versions.
+package experiment
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/fxamacker/circlehash"
+)
+
+// Arm is an experiment arm (variant).
+type Arm struct {
+	// Name identifies arm.
+	Name string
+
+	// Weight is relative fraction of exposed units assigned to arm.
+	Weight float64
+}
+
+// Experiment is an experiment with weighted arms.
+// It is immutable and safe for concurrent use.
+type Experiment struct {
+	salt     string
+	arms     []Arm
+	bounds   []float64 // cumulative weights of arms
+	exposure float64
+}
+
+// NewExperiment returns an Experiment with salt, arms, and exposure.
+// Salt should be unique to experiment, such as its name.  Exposure is
+// fraction of units in experiment, in range [0, 1].
+func NewExperiment(salt string, arms []Arm, exposure float64) (*Experiment, error) {
+	if len(arms) == 0 {
+		return nil, errors.New("experiment: no arms")
+	}
+	if !(exposure >= 0 && exposure <= 1) {
+		return nil, fmt.Errorf("experiment: exposure %v isn't in range [0, 1]", exposure)
+	}
+
+	names := make(map[string]struct{}, len(arms))
+	bounds := make([]float64, len(arms))
+	total := 0.0
+	for i, a := range arms {
+		if !(a.Weight >= 0) || math.IsInf(a.Weight, 1) {
+			return nil, fmt.Errorf("experiment: arm %q has invalid weight %v", a.Name, a.Weight)
+		}
+		if _, ok := names[a.Name]; ok {
+			return nil, fmt.Errorf("experiment: duplicate arm %q", a.Name)
+		}
+		names[a.Name] = struct{}{}
+		total += a.Weight
+		bounds[i] = total
+	}
+	if total == 0 {
+		return nil, errors.New("experiment: no arms with positive weight")
+	}
+
+	return &Experiment{
+		salt:     salt,
+		arms:     append([]Arm(nil), arms...),
+		bounds:   bounds,
+		exposure: exposure,
+	}, nil
+}
+
+// WithExposure returns a copy of e with exposure.  Units exposed in e
+// remain in the same arms if exposure is increased.
+func (e *Experiment) WithExposure(exposure float64) (*Experiment, error) {
+	if !(exposure >= 0 && exposure <= 1) {
+		return nil, fmt.Errorf("experiment: exposure %v isn't in range [0, 1]", exposure)
+	}
+	t := *e
+	t.exposure = exposure
+	return &t, nil
+}
+
+// Salt returns salt of e.
+func (e *Experiment) Salt() string {
+	return e.salt
+}
+
+// Arms returns arms of e.
+func (e *Experiment) Arms() []Arm {
+	return append([]Arm(nil), e.arms...)
+}
+
+// Exposure returns fraction of units exposed to e.
+func (e *Experiment) Exposure() float64 {
+	return e.exposure
+}
+
+// arm returns index of arm for uniform value u.
+func (e *Experiment) arm(u float64) int {
+	x := u * e.bounds[len(e.bounds)-1]
+	for i, b := range e.bounds {
+		if x < b {
+			return i
+		}
+	}
+	// Unreachable unless rounding makes x equal to total.
+	for i := len(e.arms) - 1; ; i-- {
+		if e.arms[i].Weight > 0 {
+			return i
+		}
+	}
+}
+
+// Allocation is an experiment and its fraction of units in a layer.
+type Allocation struct {
+	Experiment *Experiment
+	Fraction   float64
+}
+
+// Layer is a set of mutually exclusive experiments.
+// It is immutable and safe for concurrent use.
+type Layer struct {
+	salt   string
+	exps   []*Experiment
+	bounds []float64 // cumulative fractions of experiments
+}
+
+// NewLayer returns a Layer with salt and allocations.  Fractions must sum to
+// at most 1.  Units in remaining fraction aren't in any experiment of layer.
+// Salt must differ from salts of experiments.
+func NewLayer(salt string, allocations []Allocation) (*Layer, error) {
+	exps := make([]*Experiment, len(allocations))
+	bounds := make([]float64, len(allocations))
+	salts := make(map[string]struct{}, len(allocations))
+	total := 0.0
+	for i, a := range allocations {
+		if a.Experiment == nil {
+			return nil, fmt.Errorf("experiment: allocation %d has nil experiment", i)
+		}
+		if a.Experiment.salt == salt {
+			return nil, fmt.Errorf("experiment: experiment salt %q is the same as layer salt", salt)
+		}
+		if _, ok := salts[a.Experiment.salt]; ok {
+			return nil, fmt.Errorf("experiment: duplicate experiment salt %q", a.Experiment.salt)
+		}
+		salts[a.Experiment.salt] = struct{}{}
+		if !(a.Fraction >= 0 && a.Fraction <= 1) {
+			return nil, fmt.Errorf("experiment: experiment %q has fraction %v not in range [0, 1]", a.Experiment.salt, a.Fraction)
+		}
+		total += a.Fraction
+		exps[i] = a.Experiment
+		bounds[i] = total
+	}
+	// Allow small rounding error, such as fractions 0.1 * 10.
+	if total > 1+1e-9 {
+		return nil, fmt.Errorf("experiment: fractions sum to %v, more than 1", total)
+	}
+	return &Layer{salt: salt, exps: exps, bounds: bounds}, nil
+}
+
+// Salt returns salt of l.
+func (l *Layer) Salt() string {
+	return l.salt
+}
+
+// Assigner assigns units to experiment arms.  Assignments depend only on
+// Seed, salts, arms, and unit, so they are the same across processes.
+type Assigner struct {
+	Seed uint64
+}
+
+// Uniform returns uniform value in [0, 1) for unit and salt.
+func (a Assigner) Uniform(salt, unit string) float64 {
+	return toUniform(circlehash.Hash64String(unit, circlehash.Hash64String(salt, a.Seed)))
+}
+
+// Assign returns arm of e for unit.  It returns false if unit isn't exposed
+// to e.
+func (a Assigner) Assign(e *Experiment, unit string) (string, bool) {
+	saltSeed := circlehash.Hash64String(e.salt, a.Seed)
+	if e.exposure < 1 {
+		exposureSeed := circlehash.Hash64Uint64x2(saltSeed, 1, a.Seed)
+		if toUniform(circlehash.Hash64String(unit, exposureSeed)) >= e.exposure {
+			return "", false
+		}
+	}
+	u := toUniform(circlehash.Hash64String(unit, saltSeed))
+	return e.arms[e.arm(u)].Name, true
+}
+
+// AssignLayer returns experiment of l and its arm for unit.  It returns
+// false if unit isn't in any experiment of l or isn't exposed to experiment.
+func (a Assigner) AssignLayer(l *Layer, unit string) (*Experiment, string, bool) {
+	u := a.Uniform(l.salt, unit)
+	for i, b := range l.bounds {
+		if u < b {
+			e := l.exps[i]
+			arm, ok := a.Assign(e, unit)
+			if !ok {
+				return nil, "", false
+			}
+			return e, arm, true
+		}
+	}
+	return nil, "", false
+}
+
+// toUniform maps h to [0, 1) using its high 53 bits.
+func toUniform(h uint64) float64 {
+	return float64(h>>11) / (1 << 53)
+}
@@ -0,0 +1,338 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package experiment
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/fxamacker/circlehash"
+)
+
+const testSeed = uint64(0x9E3779B97F4A7C15)
+
+func newTestExperiment(t *testing.T, salt string, arms []Arm, exposure float64) *Experiment {
+	e, err := NewExperiment(salt, arms, exposure)
+	if err != nil {
+		t.Fatalf("NewExperiment() returned error %v", err)
+	}
+	return e
+}
+
+func testUnit(i int) string {
+	return "user-" + strconv.Itoa(i)
+}
+
+func TestNewExperimentInvalid(t *testing.T) {
+	testCases := []struct {
+		name     string
+		arms     []Arm
+		exposure float64
+	}{
+		{"no arms", nil, 1},
+		{"zero weights", []Arm{{"a", 0}, {"b", 0}}, 1},
+		{"negative weight", []Arm{{"a", 1}, {"b", -1}}, 1},
+		{"NaN weight", []Arm{{"a", 1}, {"b", math.NaN()}}, 1},
+		{"infinite weight", []Arm{{"a", 1}, {"b", math.Inf(1)}}, 1},
+		{"duplicate names", []Arm{{"a", 1}, {"a", 1}}, 1},
+		{"negative exposure", []Arm{{"a", 1}}, -0.1},
+		{"exposure too large", []Arm{{"a", 1}}, 1.1},
+		{"NaN exposure", []Arm{{"a", 1}}, math.NaN()},
+	}
+	for _, tc := range testCases {
+		if _, err := NewExperiment("exp", tc.arms, tc.exposure); err == nil {
+			t.Errorf("%s: NewExperiment() didn't return error", tc.name)
+		}
+	}
+}
+
+func TestUniform(t *testing.T) {
+	a := Assigner{Seed: testSeed}
+	for i := 0; i < 1000; i++ {
+		unit := testUnit(i)
+		h := circlehash.Hash64String(unit, circlehash.Hash64String("exp", testSeed))
+
+		got := a.Uniform("exp", unit)
+		want := float64(h>>11) / (1 << 53)
+		if got != want {
+			t.Errorf("Uniform(%q) = %v; want %v", unit, got, want)
+		}
+		if got < 0 || got >= 1 {
+			t.Errorf("Uniform(%q) = %v; want value in [0, 1)", unit, got)
+		}
+	}
+}
+
+func TestGoldenAssignments(t *testing.T) {
+	// Assignments must not change between versions.
+	a := Assigner{Seed: testSeed}
+	e := newTestExperiment(t, "checkout-button", []Arm{{"control", 1}, {"blue", 1}, {"green", 2}}, 1)
+	half, err := e.WithExposure(0.5)
+	if err != nil {
+		t.Fatalf("WithExposure() returned error %v", err)
+	}
+
+	testCases := []struct {
+		unit        string
+		uniform     float64
+		arm         string
+		exposedHalf bool
+	}{
+		{"user-1", 0.6163147989893891, "green", false},
+		{"user-2", 0.21434156732773324, "control", false},
+		{"user-3", 0.3136704003305979, "blue", true},
+		{"user-4", 0.1452442797826654, "control", false},
+		{"user-5", 0.5271894693529511, "green", true},
+		{"user-6", 0.8707126916966873, "green", false},
+		{"user-7", 0.5607401171413259, "green", false},
+		{"user-8", 0.7569437101403228, "green", true},
+	}
+	for _, tc := range testCases {
+		if u := a.Uniform("checkout-button", tc.unit); u != tc.uniform {
+			t.Errorf("Uniform(%q) = %v; want %v", tc.unit, u, tc.uniform)
+		}
+		if arm, ok := a.Assign(e, tc.unit); !ok || arm != tc.arm {
+			t.Errorf("Assign(%q) = %q, %t; want %q, true", tc.unit, arm, ok, tc.arm)
+		}
+		arm, ok := a.Assign(half, tc.unit)
+		if ok != tc.exposedHalf || (ok && arm != tc.arm) {
+			t.Errorf("Assign(%q) with exposure 0.5 = %q, %t; want %q, %t", tc.unit, arm, ok, tc.arm, tc.exposedHalf)
+		}
+	}
+}
+
+func TestAssignBalance(t *testing.T) {
+	const n = 200000
+
+	a := Assigner{Seed: testSeed}
+	arms := []Arm{{"control", 5}, {"a", 3}, {"b", 2}, {"disabled", 0}}
+	e := newTestExperiment(t, "exp", arms, 1)
+
+	counts := make(map[string]int)
+	for i := 0; i < n; i++ {
+		arm, ok := a.Assign(e, testUnit(i))
+		if !ok {
+			t.Fatalf("Assign(%q) with exposure 1 returned false", testUnit(i))
+		}
+		counts[arm]++
+	}
+
+	for _, arm := range arms {
+		want := float64(n) * arm.Weight / 10
+		got := float64(counts[arm.Name])
+		// Allow 4 standard deviations.
+		if math.Abs(got-want) > 4*math.Sqrt(want)+1e-9 {
+			t.Errorf("arm %q has %v units; want %v", arm.Name, got, want)
+		}
+	}
+}
+
+func TestExposureRampUp(t *testing.T) {
+	const n = 100000
+
+	a := Assigner{Seed: testSeed}
+	e := newTestExperiment(t, "exp", []Arm{{"control", 1}, {"treatment", 1}}, 0)
+
+	assigned := make(map[string]string)
+	for _, exposure := range []float64{0, 0.01, 0.1, 0.5, 0.9, 1} {
+		e, err := e.WithExposure(exposure)
+		if err != nil {
+			t.Fatalf("WithExposure(%v) returned error %v", exposure, err)
+		}
+
+		exposed := 0
+		for i := 0; i < n; i++ {
+			unit := testUnit(i)
+			arm, ok := a.Assign(e, unit)
+			prev, wasExposed := assigned[unit]
+			if wasExposed && !ok {
+				t.Fatalf("exposure %v: unit %q is no longer exposed", exposure, unit)
+			}
+			if !ok {
+				continue
+			}
+			if wasExposed && arm != prev {
+				t.Fatalf("exposure %v: unit %q moved from arm %q to %q", exposure, unit, prev, arm)
+			}
+			assigned[unit] = arm
+			exposed++
+		}
+
+		want := exposure * n
+		if math.Abs(float64(exposed)-want) > 4*math.Sqrt(want)+1e-9 {
+			t.Errorf("exposure %v: %d units exposed; want %v", exposure, exposed, want)
+		}
+	}
+}
+
+func TestExposureIndependentOfArm(t *testing.T) {
+	const n = 100000
+
+	// With 50% exposure, exposed units are split evenly between arms.
+	a := Assigner{Seed: testSeed}
+	e := newTestExperiment(t, "exp", []Arm{{"control", 1}, {"treatment", 1}}, 0.5)
+
+	counts := make(map[string]int)
+	for i := 0; i < n; i++ {
+		if arm, ok := a.Assign(e, testUnit(i)); ok {
+			counts[arm]++
+		}
+	}
+	for arm, count := range counts {
+		want := float64(n) / 4
+		if math.Abs(float64(count)-want) > 4*math.Sqrt(want) {
+			t.Errorf("arm %q has %d exposed units; want %v", arm, count, want)
+		}
+	}
+}
+
+func TestSeedAndSaltIndependence(t *testing.T) {
+	const n = 100000
+
+	// Assignments in experiments with different salts or seeds are
+	// independent: about half of units in arm "a" of one are in arm "a"
+	// of the other.
+	arms := []Arm{{"a", 1}, {"b", 1}}
+	e1 := newTestExperiment(t, "exp1", arms, 1)
+	e2 := newTestExperiment(t, "exp2", arms, 1)
+
+	testCases := []struct {
+		name   string
+		a1, a2 Assigner
+		e1, e2 *Experiment
+	}{
+		{"salt", Assigner{testSeed}, Assigner{testSeed}, e1, e2},
+		{"seed", Assigner{testSeed}, Assigner{testSeed + 1}, e1, e1},
+	}
+	for _, tc := range testCases {
+		same := 0
+		for i := 0; i < n; i++ {
+			arm1, _ := tc.a1.Assign(tc.e1, testUnit(i))
+			arm2, _ := tc.a2.Assign(tc.e2, testUnit(i))
+			if arm1 == arm2 {
+				same++
+			}
+		}
+		want := float64(n) / 2
+		if math.Abs(float64(same)-want) > 4*math.Sqrt(want) {
+			t.Errorf("%s: %d units in the same arms; want %v", tc.name, same, want)
+		}
+	}
+}
+
+func TestLayer(t *testing.T) {
+	const n = 100000
+
+	a := Assigner{Seed: testSeed}
+	arms := []Arm{{"control", 1}, {"treatment", 1}}
+	e1 := newTestExperiment(t, "exp1", arms, 1)
+	e2 := newTestExperiment(t, "exp2", arms, 1)
+	e3 := newTestExperiment(t, "exp3", arms, 0.5)
+
+	l, err := NewLayer("layer", []Allocation{{e1, 0.2}, {e2, 0.3}, {e3, 0.4}})
+	if err != nil {
+		t.Fatalf("NewLayer() returned error %v", err)
+	}
+
+	counts := make(map[*Experiment]int)
+	for i := 0; i < n; i++ {
+		unit := testUnit(i)
+		e, arm, ok := a.AssignLayer(l, unit)
+		if !ok {
+			continue
+		}
+
+		// Arm is the same as assignment in experiment.
+		if want, _ := a.Assign(e, unit); arm != want {
+			t.Errorf("AssignLayer(%q) = %q; Assign() = %q", unit, arm, want)
+		}
+
+		// Experiment matches layer's uniform value.
+		u := a.Uniform("layer", unit)
+		if (e == e1) != (u < 0.2) || (e == e2) != (u >= 0.2 && u < 0.5) || (e == e3) != (u >= 0.5 && u < 0.9) {
+			t.Errorf("AssignLayer(%q) = %q with layer uniform value %v", unit, e.Salt(), u)
+		}
+		counts[e]++
+	}
+
+	for _, tc := range []struct {
+		e    *Experiment
+		want float64
+	}{
+		{e1, 0.2 * n},
+		{e2, 0.3 * n},
+		{e3, 0.4 * 0.5 * n},
+	} {
+		got := float64(counts[tc.e])
+		if math.Abs(got-tc.want) > 4*math.Sqrt(tc.want) {
+			t.Errorf("experiment %q has %v units; want %v", tc.e.Salt(), got, tc.want)
+		}
+	}
+}
+
+func TestNewLayerInvalid(t *testing.T) {
+	arms := []Arm{{"a", 1}}
+	e1, _ := NewExperiment("exp1", arms, 1)
+	e2, _ := NewExperiment("exp2", arms, 1)
+	sameSalt, _ := NewExperiment("layer", arms, 1)
+
+	testCases := []struct {
+		name        string
+		allocations []Allocation
+	}{
+		{"nil experiment", []Allocation{{nil, 0.5}}},
+		{"negative fraction", []Allocation{{e1, -0.1}}},
+		{"fraction too large", []Allocation{{e1, 1.5}}},
+		{"fractions sum too large", []Allocation{{e1, 0.6}, {e2, 0.6}}},
+		{"duplicate experiment", []Allocation{{e1, 0.2}, {e1, 0.2}}},
+		{"experiment salt same as layer", []Allocation{{sameSalt, 0.2}}},
+	}
+	for _, tc := range testCases {
+		if _, err := NewLayer("layer", tc.allocations); err == nil {
+			t.Errorf("%s: NewLayer() didn't return error", tc.name)
+		}
+	}
+
+	// Fractions summing to 1 with rounding error are allowed.
+	allocations := make([]Allocation, 10)
+	for i := range allocations {
+		e, _ := NewExperiment("exp"+strconv.Itoa(i), arms, 1)
+		allocations[i] = Allocation{e, 0.1}
+	}
+	if _, err := NewLayer("layer", allocations); err != nil {
+		t.Errorf("NewLayer() with 10 fractions of 0.1 returned error %v", err)
+	}
+}
+
+func TestWithExposureInvalid(t *testing.T) {
+	e := newTestExperiment(t, "exp", []Arm{{"a", 1}}, 1)
+	for _, exposure := range []float64{-1, 2, math.NaN()} {
+		if _, err := e.WithExposure(exposure); err == nil {
+			t.Errorf("WithExposure(%v) didn't return error", exposure)
+		}
+	}
+	if e.Exposure() != 1 {
+		t.Errorf("Exposure() = %v; want 1", e.Exposure())
+	}
+}
+
+func BenchmarkAssign(b *testing.B) {
+	a := Assigner{Seed: testSeed}
+	e, _ := NewExperiment("exp", []Arm{{"control", 1}, {"a", 1}, {"b", 1}}, 0.5)
+	for i := 0; i < b.N; i++ {
+		_, _ = a.Assign(e, "user-12345")
+	}
+}
@@ -0,0 +1,198 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package featurehash implements feature hashing (the hashing trick) using
+// CircleHash64f.  See "Feature Hashing for Large Scale Multitask Learning"
+// by Kilian Weinberger et al.
+//
+// Feature name in namespace is mapped to index and sign as follows:
+//
+//	nsSeed   = seed if namespace is "", otherwise
+//	           circlehash.Hash64String(namespace, seed)
+//	signSeed = circlehash.Hash64Uint64x2(nsSeed, 1, seed)
+//	h        = circlehash.Hash64String(name, nsSeed)
+//	index    = high 64 bits of 128-bit product h * dim
+//	sign     = +1 if circlehash.Hash64String(name, signSeed) < 2^63, otherwise -1
+//
+// Sign is always +1 for unsigned hashers.  Index is computed by multiplication
+// instead of modulo so it is unbiased for any dim.  N-grams of tokens are
+// hashed as tokens joined by a single space, so n-gram "new york" has the same
+// index as feature name "new york".
+//
+// Indices and signs are stable and don't change between versions, so the
+// same features produce the same vectors in training and serving.
+package featurehash
+
+import (
+	"errors"
+	"math/bits"
+	"sort"
+	"strings"
+
+	"github.com/fxamacker/circlehash"
+)
+
+// Feature is a named feature with value.
+type Feature struct {
+	// Namespace groups features, such as "title" or "user".  Features with
+	// the same name in different namespaces are hashed independently.
+	Namespace string
+
+	// Name identifies feature in namespace.
+	Name string
+
+	// Value is feature value.  It is 1 for binary features.
+	Value float64
+}
+
+// Entry is a nonzero element of a sparse vector.
+type Entry struct {
+	Index int
+	Value float64
+}
+
+// FeatureHasher maps features to sparse vectors of fixed dimension.
+// It is immutable and safe for concurrent use.
+type FeatureHasher struct {
+	dim    uint64
+	seed   uint64
+	signed bool
+}
+
+// New returns a signed FeatureHasher with dimension dim.  Signed hashing
+// multiplies feature values by hashed signs, so collisions cancel out in
+// expectation.
+func New(dim int, seed uint64) (*FeatureHasher, error) {
+	if dim < 1 {
+		return nil, errors.New("featurehash: dim must be positive")
+	}
+	return &FeatureHasher{dim: uint64(dim), seed: seed, signed: true}, nil
+}
+
+// NewUnsigned returns an unsigned FeatureHasher with dimension dim.
+func NewUnsigned(dim int, seed uint64) (*FeatureHasher, error) {
+	h, err := New(dim, seed)
+	if err != nil {
+		return nil, err
+	}
+	h.signed = false
+	return h, nil
+}
+
+// Dim returns dimension of vectors.
+func (h *FeatureHasher) Dim() int {
+	return int(h.dim)
+}
+
+// Seed returns seed of h.
+func (h *FeatureHasher) Seed() uint64 {
+	return h.seed
+}
+
+// Signed returns true if h is signed.
+func (h *FeatureHasher) Signed() bool {
+	return h.signed
+}
+
+// Index returns index and sign (+1 or -1) of feature name in namespace.
+func (h *FeatureHasher) Index(namespace, name string) (int, float64) {
+	nsSeed := h.namespaceSeed(namespace)
+	return h.index(circlehash.Hash64String(name, nsSeed)), h.sign(name, nsSeed)
+}
+
+func (h *FeatureHasher) namespaceSeed(namespace string) uint64 {
+	if namespace == "" {
+		return h.seed
+	}
+	return circlehash.Hash64String(namespace, h.seed)
+}
+
+func (h *FeatureHasher) index(digest uint64) int {
+	hi, _ := bits.Mul64(digest, h.dim)
+	return int(hi)
+}
+
+func (h *FeatureHasher) sign(name string, nsSeed uint64) float64 {
+	if !h.signed {
+		return 1
+	}
+	signSeed := circlehash.Hash64Uint64x2(nsSeed, 1, h.seed)
+	if circlehash.Hash64String(name, signSeed)>>63 == 0 {
+		return 1
+	}
+	return -1
+}
+
+// Transform returns sparse vector of features sorted by index.  Values of
+// features with the same index are summed, and entries with sum 0 are
+// omitted.
+func (h *FeatureHasher) Transform(features []Feature) []Entry {
+	entries := make([]Entry, 0, len(features))
+	for _, f := range features {
+		if f.Value == 0 {
+			continue
+		}
+		i, sign := h.Index(f.Namespace, f.Name)
+		entries = append(entries, Entry{Index: i, Value: sign * f.Value})
+	}
+	return merge(entries)
+}
+
+// TransformStrings returns sparse vector of binary features with names in
+// namespace, sorted by index.  It is the same as Transform of features with
+// value 1.
+func (h *FeatureHasher) TransformStrings(namespace string, names []string) []Entry {
+	nsSeed := h.namespaceSeed(namespace)
+	entries := make([]Entry, 0, len(names))
+	for _, name := range names {
+		i := h.index(circlehash.Hash64String(name, nsSeed))
+		entries = append(entries, Entry{Index: i, Value: h.sign(name, nsSeed)})
+	}
+	return merge(entries)
+}
+
+// merge sorts entries by index, sums values with the same index, and
+// removes zero values.  Entries with the same index are summed in input
+// order, so results are deterministic.
+func merge(entries []Entry) []Entry {
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Index < entries[j].Index
+	})
+
+	n := 0
+	for i := 0; i < len(entries); {
+		e := entries[i]
+		for i++; i < len(entries) && entries[i].Index == e.Index; i++ {
+			e.Value += entries[i].Value
+		}
+		if e.Value != 0 {
+			entries[n] = e
+			n++
+		}
+	}
+	return entries[:n]
+}
+
+// NGrams returns n-grams of tokens joined by a single space.  It returns
+// nil if n < 1 or len(tokens) < n.
+func NGrams(tokens []string, n int) []string {
+	if n < 1 || len(tokens) < n {
+		return nil
+	}
+	grams := make([]string, 0, len(tokens)-n+1)
+	for i := 0; i+n <= len(tokens); i++ {
+		grams = append(grams, strings.Join(tokens[i:i+n], " "))
+	}
+	return grams
+}
@@ -0,0 +1,277 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package featurehash
+
+import (
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/fxamacker/circlehash"
+)
+
+const testSeed = uint64(0x9E3779B97F4A7C15)
+
+func newTestHasher(t *testing.T, dim int) *FeatureHasher {
+	h, err := New(dim, testSeed)
+	if err != nil {
+		t.Fatalf("New() returned error %v", err)
+	}
+	return h
+}
+
+// referenceIndex returns index and sign of feature as documented in
+// package doc, using math/big for the 128-bit product.
+func referenceIndex(namespace, name string, dim int, seed uint64) (int, float64) {
+	nsSeed := seed
+	if namespace != "" {
+		nsSeed = circlehash.Hash64String(namespace, seed)
+	}
+	signSeed := circlehash.Hash64Uint64x2(nsSeed, 1, seed)
+
+	p := new(big.Int).SetUint64(circlehash.Hash64String(name, nsSeed))
+	p.Mul(p, big.NewInt(int64(dim)))
+	p.Rsh(p, 64)
+
+	sign := 1.0
+	if circlehash.Hash64String(name, signSeed) >= 1<<63 {
+		sign = -1
+	}
+	return int(p.Int64()), sign
+}
+
+func TestNewInvalid(t *testing.T) {
+	for _, dim := range []int{-1, 0} {
+		if _, err := New(dim, testSeed); err == nil {
+			t.Errorf("New(%d) didn't return error", dim)
+		}
+		if _, err := NewUnsigned(dim, testSeed); err == nil {
+			t.Errorf("NewUnsigned(%d) didn't return error", dim)
+		}
+	}
+}
+
+func TestIndexReference(t *testing.T) {
+	for _, dim := range []int{1, 2, 3, 1000, 1 << 20, 1<<31 - 1} {
+		h := newTestHasher(t, dim)
+		for _, ns := range []string{"", "title", "body"} {
+			for i := 0; i < 1000; i++ {
+				name := "feature" + strconv.Itoa(i)
+				gotIndex, gotSign := h.Index(ns, name)
+				wantIndex, wantSign := referenceIndex(ns, name, dim, testSeed)
+				if gotIndex != wantIndex || gotSign != wantSign {
+					t.Fatalf("Index(%q, %q) with dim %d = %d, %v; want %d, %v", ns, name, dim, gotIndex, gotSign, wantIndex, wantSign)
+				}
+			}
+		}
+	}
+}
+
+func TestIndexGolden(t *testing.T) {
+	// Indices and signs must not change between versions.
+	h := newTestHasher(t, 1<<20)
+
+	testCases := []struct {
+		namespace string
+		name      string
+		index     int
+		sign      float64
+	}{
+		{"", "hello", 536134, -1},
+		{"", "world", 603544, 1},
+		{"title", "hello", 184555, -1},
+		{"title", "new york", 633867, -1},
+		{"user", "id=42", 431179, 1},
+		{"", "", 86959, -1},
+	}
+	for _, tc := range testCases {
+		index, sign := h.Index(tc.namespace, tc.name)
+		if index != tc.index || sign != tc.sign {
+			t.Errorf("Index(%q, %q) = %d, %v; want %d, %v", tc.namespace, tc.name, index, sign, tc.index, tc.sign)
+		}
+	}
+}
+
+func TestUnsigned(t *testing.T) {
+	h, err := NewUnsigned(1<<20, testSeed)
+	if err != nil {
+		t.Fatalf("NewUnsigned() returned error %v", err)
+	}
+	signed := newTestHasher(t, 1<<20)
+
+	for i := 0; i < 1000; i++ {
+		name := "feature" + strconv.Itoa(i)
+		index, sign := h.Index("ns", name)
+		wantIndex, _ := signed.Index("ns", name)
+		if index != wantIndex || sign != 1 {
+			t.Errorf("Index(%q) = %d, %v; want %d, 1", name, index, sign, wantIndex)
+		}
+	}
+	if h.Signed() || !signed.Signed() {
+		t.Errorf("Signed() = %t, %t; want false, true", h.Signed(), signed.Signed())
+	}
+}
+
+func TestIndexDistribution(t *testing.T) {
+	const numFeatures = 100000
+	const dim = 10
+
+	h := newTestHasher(t, dim)
+	counts := make([]int, dim)
+	positive := 0
+	for i := 0; i < numFeatures; i++ {
+		index, sign := h.Index("ns", "feature"+strconv.Itoa(i))
+		counts[index]++
+		if sign > 0 {
+			positive++
+		}
+	}
+
+	want := float64(numFeatures) / dim
+	for i, c := range counts {
+		if math.Abs(float64(c)-want) > 5*math.Sqrt(want) {
+			t.Errorf("index %d has %d features; want about %.0f", i, c, want)
+		}
+	}
+	if math.Abs(float64(positive)-numFeatures/2) > 5*math.Sqrt(numFeatures/2) {
+		t.Errorf("%d of %d features have positive sign; want about half", positive, numFeatures)
+	}
+}
+
+func TestNamespaces(t *testing.T) {
+	// Features with the same name in different namespaces are independent.
+	h := newTestHasher(t, 1<<30)
+	same := 0
+	for i := 0; i < 1000; i++ {
+		name := "feature" + strconv.Itoa(i)
+		i1, _ := h.Index("title", name)
+		i2, _ := h.Index("body", name)
+		if i1 == i2 {
+			same++
+		}
+	}
+	if same > 0 {
+		t.Errorf("%d features have the same index in different namespaces", same)
+	}
+}
+
+func TestTransform(t *testing.T) {
+	h := newTestHasher(t, 1<<20)
+
+	features := []Feature{
+		{"", "hello", 2},
+		{"title", "new york", 1},
+		{"user", "id=42", 0.5},
+		{"", "world", 0},
+		{"", "hello", 1},
+	}
+	got := h.Transform(features)
+	want := []Entry{
+		{431179, 0.5},
+		{536134, -3},
+		{633867, -1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Transform() = %v; want %v", got, want)
+	}
+
+	if got := h.Transform(nil); len(got) != 0 {
+		t.Errorf("Transform(nil) = %v; want empty vector", got)
+	}
+}
+
+func TestTransformCollisions(t *testing.T) {
+	// With dim 1, all features collide and values are summed with signs.
+	h := newTestHasher(t, 1)
+
+	var features []Feature
+	want := 0.0
+	for i := 0; i < 100; i++ {
+		name := "feature" + strconv.Itoa(i)
+		_, sign := h.Index("", name)
+		features = append(features, Feature{Name: name, Value: float64(i)})
+		want += sign * float64(i)
+	}
+
+	got := h.Transform(features)
+	if len(got) != 1 || got[0].Index != 0 || got[0].Value != want {
+		t.Errorf("Transform() with dim 1 = %v; want [{0 %v}]", got, want)
+	}
+
+	// Colliding features with opposite signs cancel out.
+	var pos, neg string
+	for i := 0; pos == "" || neg == ""; i++ {
+		name := "feature" + strconv.Itoa(i)
+		if _, sign := h.Index("", name); sign > 0 {
+			pos = name
+		} else {
+			neg = name
+		}
+	}
+	if got := h.Transform([]Feature{{Name: pos, Value: 1}, {Name: neg, Value: 1}}); len(got) != 0 {
+		t.Errorf("Transform() of canceling features = %v; want empty vector", got)
+	}
+}
+
+func TestTransformStrings(t *testing.T) {
+	h := newTestHasher(t, 1000)
+
+	names := NGrams([]string{"the", "quick", "brown", "fox", "the", "quick"}, 2)
+	features := make([]Feature, len(names))
+	for i, name := range names {
+		features[i] = Feature{Namespace: "body", Name: name, Value: 1}
+	}
+
+	got := h.TransformStrings("body", names)
+	want := h.Transform(features)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TransformStrings() = %v; want %v", got, want)
+	}
+	for i := 1; i < len(got); i++ {
+		if got[i-1].Index >= got[i].Index {
+			t.Errorf("TransformStrings() isn't sorted by index: %v", got)
+		}
+	}
+}
+
+func TestNGrams(t *testing.T) {
+	tokens := []string{"new", "york", "city"}
+
+	testCases := []struct {
+		n    int
+		want []string
+	}{
+		{0, nil},
+		{1, []string{"new", "york", "city"}},
+		{2, []string{"new york", "york city"}},
+		{3, []string{"new york city"}},
+		{4, nil},
+	}
+	for _, tc := range testCases {
+		if got := NGrams(tokens, tc.n); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("NGrams(%q, %d) = %q; want %q", tokens, tc.n, got, tc.want)
+		}
+	}
+}
+
+func BenchmarkTransformStrings(b *testing.B) {
+	h, _ := New(1<<20, testSeed)
+	names := NGrams([]string{"the", "quick", "brown", "fox", "jumps", "over", "the", "lazy", "dog"}, 2)
+	for i := 0; i < b.N; i++ {
+		_ = h.TransformStrings("body", names)
+	}
+}
@@ -0,0 +1,176 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package circlehash
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// Hash64StringFoldASCII returns a 64-bit digest of s with ASCII letters
+// folded to lower case, without allocating a folded copy of s.
+// Digest is the same as Hash64String of s with 'A' to 'Z' replaced by
+// 'a' to 'z'.  Other bytes are unchanged.
+func Hash64StringFoldASCII(s string, seed uint64) uint64 {
+	i := 0
+	for i < len(s) && !isASCIIUpper(s[i]) {
+		i++
+	}
+	if i == len(s) {
+		// Nothing to fold.
+		return Hash64String(s, seed)
+	}
+
+	d := Digest64{seed: seed}
+	d.Reset()
+	d.WriteString(s[:i])
+
+	// Fold into buf and write buf to digest when it's full.
+	var buf [64]byte
+	n := 0
+	for ; i < len(s); i++ {
+		c := s[i]
+		if isASCIIUpper(c) {
+			c += 'a' - 'A'
+		}
+		buf[n] = c
+		n++
+		if n == len(buf) {
+			d.Write(buf[:])
+			n = 0
+		}
+	}
+	d.Write(buf[:n])
+	return d.Sum64()
+}
+
+// Hash64StringFold returns a 64-bit digest of s with simple Unicode
+// case folding, without allocating a folded copy of s.
+// Digest is the same as Hash64String of s with each rune r replaced by
+// FoldRune(r).  Invalid UTF-8 bytes are unchanged.
+//
+// For ASCII strings, Hash64StringFold is the same as Hash64StringFoldASCII.
+func Hash64StringFold(s string, seed uint64) uint64 {
+	i := 0
+	for i < len(s) {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if isASCIIUpper(c) {
+				break
+			}
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r != utf8.RuneError && FoldRune(r) != r {
+			break
+		}
+		i += size
+	}
+	if i == len(s) {
+		// Nothing to fold.
+		return Hash64String(s, seed)
+	}
+
+	d := Digest64{seed: seed}
+	d.Reset()
+	d.WriteString(s[:i])
+
+	w := foldWriter{d: &d}
+	w.writeString(s[i:])
+	w.flush()
+	return d.Sum64()
+}
+
+// foldWriter folds runes into buf and writes buf to digest when it's
+// nearly full.
+type foldWriter struct {
+	d   *Digest64
+	buf [64]byte
+	n   int
+}
+
+func (w *foldWriter) writeString(s string) {
+	for i := 0; i < len(s); {
+		if w.n > len(w.buf)-utf8.UTFMax {
+			w.flush()
+		}
+
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if isASCIIUpper(c) {
+				c += 'a' - 'A'
+			}
+			w.buf[w.n] = c
+			w.n++
+			i++
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError {
+			// Invalid UTF-8 byte or U+FFFD is unchanged.
+			w.n += copy(w.buf[w.n:], s[i:i+size])
+		} else {
+			w.n += utf8.EncodeRune(w.buf[w.n:], FoldRune(r))
+		}
+		i += size
+	}
+}
+
+func (w *foldWriter) flush() {
+	w.d.Write(w.buf[:w.n])
+	w.n = 0
+}
+
+// FoldRune returns simple case folding of r used by Hash64StringFold.
+// Runes in the same unicode.SimpleFold orbit, such as 'k', 'K', and 'K'
+// (Kelvin sign), or 'ς', 'σ', and 'Σ', are folded to the same rune: the
+// smallest rune c in orbit with unicode.ToLower(unicode.ToUpper(c)) == c,
+// which is the lower case rune for orbits with upper and lower case.
+//
+// Runes without simple case folding are unchanged, even if they have
+// lower or upper case mappings outside their orbit.  For example, 'İ'
+// (U+0130) and 'ı' (U+0131) aren't folded to 'i', because they only have
+// full or Turkic case foldings.
+func FoldRune(r rune) rune {
+	if r < utf8.RuneSelf {
+		if isASCIIUpper(byte(r)) {
+			r += 'a' - 'A'
+		}
+		return r
+	}
+
+	fold, least := rune(-1), r
+	for c := r; ; {
+		if (fold < 0 || c < fold) && unicode.ToLower(unicode.ToUpper(c)) == c {
+			fold = c
+		}
+		if c < least {
+			least = c
+		}
+		if c = unicode.SimpleFold(c); c == r {
+			break
+		}
+	}
+	if fold < 0 {
+		return least
+	}
+	return fold
+}
+
+func isASCIIUpper(c byte) bool {
+	return 'A' <= c && c <= 'Z'
+}
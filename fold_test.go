@@ -0,0 +1,212 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package circlehash
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+	"unicode/utf8"
+)
+
+var foldTestStrings = []string{
+	"",
+	"a",
+	"A",
+	"foo",
+	"Foo",
+	"FOO",
+	"Example.COM",
+	"user.Name+Tag@Example.com",
+	"ALL UPPER CASE STRING THAT IS LONGER THAN SIXTY-FOUR BYTES TO TEST CHUNKS",
+	"mixed Case string that is longer than sixty-four bytes to test chunks AT END",
+	"Straße",
+	"STRASSE",
+	"ΣΊΣΥΦΟΣ",
+	"σίσυφος",
+	"ΣΊΣΥΦΟΣ ΣΊΣΥΦΟΣ ΣΊΣΥΦΟΣ ΣΊΣΥΦΟΣ ΣΊΣΥΦΟΣ ΣΊΣΥΦΟΣ ΣΊΣΥΦΟΣ ΣΊΣΥΦΟΣ",
+	"Kelvin K",
+	"long ſ",
+	"İstanbul",
+	"ǅ",
+	"Ⅻ",
+	"ＡＢＣ",
+	"\xff\xfeInvalid UTF-8 \xc3",
+	"� replacement char",
+	"日本語",
+	"Cafe\u0301",
+	"CAF\u00c9",
+	"A\u030a\u0301 \u212b",
+	"\u1e9b\u0323",
+	"\u0071\u0307\u0323",
+	"한국어 \u1112\u1161\u11ab",
+	strings.Repeat("e\u0301", 40),
+	"\u0301 leading combining mark",
+	"\xcc\x81\xff invalid after combining mark e\u0301",
+}
+
+// foldString returns s with each rune folded by FoldRune
+// and invalid UTF-8 bytes unchanged.
+func foldString(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError {
+			sb.WriteString(s[i : i+size])
+		} else {
+			sb.WriteRune(FoldRune(r))
+		}
+		i += size
+	}
+	return sb.String()
+}
+
+// foldStringASCII returns s with ASCII upper case letters folded to lower case.
+func foldStringASCII(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		if 'A' <= c && c <= 'Z' {
+			b[i] = c + 'a' - 'A'
+		}
+	}
+	return string(b)
+}
+
+func TestHash64StringFold(t *testing.T) {
+	for _, seed := range []uint64{numsAllZeros, numsAllFFs, numsGoldenRatio} {
+		for _, s := range foldTestStrings {
+			got := Hash64StringFold(s, seed)
+			want := Hash64String(foldString(s), seed)
+			if got != want {
+				t.Errorf("Hash64StringFold(%q, 0x%x) = 0x%016x; want 0x%016x", s, seed, got, want)
+			}
+		}
+	}
+}
+
+func TestHash64StringFoldASCII(t *testing.T) {
+	for _, seed := range []uint64{numsAllZeros, numsAllFFs, numsGoldenRatio} {
+		for _, s := range foldTestStrings {
+			got := Hash64StringFoldASCII(s, seed)
+			want := Hash64String(foldStringASCII(s), seed)
+			if got != want {
+				t.Errorf("Hash64StringFoldASCII(%q, 0x%x) = 0x%016x; want 0x%016x", s, seed, got, want)
+			}
+		}
+	}
+}
+
+func TestHash64StringFoldEqual(t *testing.T) {
+	seed := numsGoldenRatio
+
+	testCases := []struct {
+		s1 string
+		s2 string
+	}{
+		{"Foo", "foo"},
+		{"EXAMPLE.com", "example.COM"},
+		{"ΣΊΣΥΦΟΣ", "σίσυφος"},
+		{"ς", "σ"},
+		{"K", "k"},
+		{"ſ", "S"},
+		{"ǅ", "ǆ"},
+		{"ＡＢＣ", "ａｂｃ"},
+	}
+
+	for _, tc := range testCases {
+		if !strings.EqualFold(tc.s1, tc.s2) {
+			t.Fatalf("strings.EqualFold(%q, %q) = false", tc.s1, tc.s2)
+		}
+
+		h1 := Hash64StringFold(tc.s1, seed)
+		h2 := Hash64StringFold(tc.s2, seed)
+		if h1 != h2 {
+			t.Errorf("Hash64StringFold(%q) = 0x%016x; Hash64StringFold(%q) = 0x%016x", tc.s1, h1, tc.s2, h2)
+		}
+	}
+}
+
+func TestFoldRune(t *testing.T) {
+	testCases := []struct {
+		r    rune
+		want rune
+	}{
+		{'A', 'a'},
+		{'a', 'a'},
+		{'\u212a', 'k'}, // Kelvin sign
+		{'\u017f', 's'}, // long s
+		{'\u03c2', '\u03c3'},
+		{'\u03a3', '\u03c3'},
+		{'\u01c5', '\u01c6'},
+		{'\u0130', '\u0130'}, // no simple case folding
+		{'\u0131', '\u0131'}, // no simple case folding
+		{'\u00df', '\u00df'},
+		{'\u1e9e', '\u00df'},
+		{'\u1fd3', '\u0390'}, // orbit without upper case
+	}
+
+	for _, tc := range testCases {
+		if got := FoldRune(tc.r); got != tc.want {
+			t.Errorf("FoldRune(%U) = %U; want %U", tc.r, got, tc.want)
+		}
+	}
+}
+
+func TestFoldRuneOrbit(t *testing.T) {
+	// FoldRune(r) is in unicode.SimpleFold orbit of r, and all runes in
+	// orbit fold to the same rune.
+	for r := rune(0); r <= unicode.MaxRune; r++ {
+		f := FoldRune(r)
+
+		inOrbit := f == r
+		for c := unicode.SimpleFold(r); c != r; c = unicode.SimpleFold(c) {
+			if c == f {
+				inOrbit = true
+			}
+			if fc := FoldRune(c); fc != f {
+				t.Fatalf("FoldRune(%U) = %U; FoldRune(%U) = %U", r, f, c, fc)
+			}
+		}
+		if !inOrbit {
+			t.Fatalf("FoldRune(%U) = %U isn't in SimpleFold orbit", r, f)
+		}
+	}
+}
+
+func TestHash64StringFoldAllocs(t *testing.T) {
+	for _, s := range foldTestStrings {
+		allocs := testing.AllocsPerRun(10, func() {
+			_ = Hash64StringFold(s, numsGoldenRatio)
+			_ = Hash64StringFoldASCII(s, numsGoldenRatio)
+		})
+		if allocs != 0 {
+			t.Errorf("Hash64StringFold(%q) allocated %v times; want 0", s, allocs)
+		}
+	}
+}
+
+func BenchmarkHash64StringFold(b *testing.B) {
+	s := "user.Name+Tag@Example.com"
+	for i := 0; i < b.N; i++ {
+		_ = Hash64StringFold(s, numsGoldenRatio)
+	}
+}
+
+func BenchmarkHash64StringFoldASCII(b *testing.B) {
+	s := "user.Name+Tag@Example.com"
+	for i := 0; i < b.N; i++ {
+		_ = Hash64StringFoldASCII(s, numsGoldenRatio)
+	}
+}
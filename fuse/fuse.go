@@ -0,0 +1,378 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package fuse implements binary fuse filters using CircleHash64f.
+//
+// Binary fuse filters are approximate membership filters for static sets.
+// They use about 9 (8-bit fingerprints) or 18 (16-bit fingerprints) bits
+// per key for large sets, with false positive rate about 1/256 or 1/65536,
+// and need only 3 memory accesses per query.
+// See "Binary Fuse Filters: Fast and Smaller Than Xor Filters"
+// by Thomas Mueller Graf and Daniel Lemire.
+//
+// Build hashes keys with circlehash.Hash64.  If construction fails,
+// it retries with seed i derived from base seed:
+//
+//	seed_i = circlehash.Hash64Uint64x2(baseSeed, i, baseSeed)
+//
+// Seed used by the successful attempt is recorded in serialized filter.
+//
+// Serialized filter can be queried without copying, for example:
+//
+//	//go:embed denylist.fuse
+//	var denylistData []byte
+//
+//	var denylist, _ = fuse.Load(denylistData)
+package fuse
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"sort"
+
+	"github.com/fxamacker/circlehash"
+)
+
+const (
+	// MaxAttempts is maximum number of construction attempts by Build.
+	MaxAttempts = 100
+
+	// MaxKeys is maximum number of keys in a filter.
+	MaxKeys uint64 = 1 << 31
+
+	formatVersion = 1
+
+	// algorithmCircleHash64f3Wise is binary fuse filter with 3 hash
+	// positions derived from CircleHash64f digest.
+	algorithmCircleHash64f3Wise = 1
+
+	// headerSize is the size of serialized filter header:
+	// magic (4), version (1), algorithm (1), fingerprint bits (1), reserved (1),
+	// seed (8), number of keys (4), segment length (4), segment count (4),
+	// array length (4).
+	headerSize = 32
+
+	maxSegmentLength = 1 << 18
+)
+
+var magic = [4]byte{'C', 'H', 'F', 'F'}
+
+var (
+	// ErrConstructionFailed is returned when Build fails to construct filter
+	// after MaxAttempts attempts.
+	ErrConstructionFailed = errors.New("fuse: failed to construct filter")
+
+	// ErrInvalidData is returned by Load when data isn't a valid serialized filter.
+	ErrInvalidData = errors.New("fuse: invalid serialized filter")
+)
+
+// Filter is an immutable binary fuse filter with 8-bit or 16-bit fingerprints.
+// It is safe for concurrent use.
+type Filter struct {
+	seed               uint64
+	fpBits             int
+	n                  uint32
+	segmentLength      uint32
+	segmentLengthMask  uint32
+	segmentCount       uint32
+	segmentCountLength uint32
+
+	// fingerprints are 1 byte (8-bit) or 2 bytes in little-endian (16-bit)
+	// per position.  It is a subslice of data passed to Load.
+	fingerprints []byte
+}
+
+// Build returns a Filter containing keys, with fingerprintBits 8 or 16.
+// Duplicate keys are allowed.
+func Build(keys [][]byte, fingerprintBits int, seed uint64) (*Filter, error) {
+	return build(len(keys), func(i int, seed uint64) uint64 {
+		return circlehash.Hash64(keys[i], seed)
+	}, fingerprintBits, seed)
+}
+
+// BuildStrings returns a Filter containing keys, with fingerprintBits 8 or 16.
+// Duplicate keys are allowed.
+func BuildStrings(keys []string, fingerprintBits int, seed uint64) (*Filter, error) {
+	return build(len(keys), func(i int, seed uint64) uint64 {
+		return circlehash.Hash64String(keys[i], seed)
+	}, fingerprintBits, seed)
+}
+
+// Contains returns true if b may be in f, and false if b is definitely not in f.
+func (f *Filter) Contains(b []byte) bool {
+	return f.contains(circlehash.Hash64(b, f.seed))
+}
+
+// ContainsString returns true if s may be in f, and false if s is definitely not in f.
+func (f *Filter) ContainsString(s string) bool {
+	return f.contains(circlehash.Hash64String(s, f.seed))
+}
+
+// Len returns number of distinct keys in f.
+func (f *Filter) Len() int {
+	return int(f.n)
+}
+
+// FingerprintBits returns size of fingerprints in bits (8 or 16).
+func (f *Filter) FingerprintBits() int {
+	return f.fpBits
+}
+
+// Seed returns the seed used to hash keys in f.
+// It is derived from seed passed to Build.
+func (f *Filter) Seed() uint64 {
+	return f.seed
+}
+
+func (f *Filter) contains(h uint64) bool {
+	p0, p1, p2 := f.positions(h)
+	if f.fpBits == 8 {
+		fp := uint8(fingerprint(h))
+		return fp^f.fingerprints[p0]^f.fingerprints[p1]^f.fingerprints[p2] == 0
+	}
+	fp := uint16(fingerprint(h))
+	return fp^f.get16(p0)^f.get16(p1)^f.get16(p2) == 0
+}
+
+func (f *Filter) get16(p uint32) uint16 {
+	return binary.LittleEndian.Uint16(f.fingerprints[2*p:])
+}
+
+func fingerprint(h uint64) uint64 {
+	return h ^ (h >> 32)
+}
+
+// positions returns 3 positions of digest h in 3 consecutive segments.
+func (f *Filter) positions(h uint64) (uint32, uint32, uint32) {
+	hi, _ := bits.Mul64(h, uint64(f.segmentCountLength))
+	p0 := uint32(hi)
+	p1 := p0 + f.segmentLength
+	p2 := p1 + f.segmentLength
+	p1 ^= uint32(h>>18) & f.segmentLengthMask
+	p2 ^= uint32(h) & f.segmentLengthMask
+	return p0, p1, p2
+}
+
+// setParameters sets segment layout for n keys.
+func (f *Filter) setParameters(n uint32) {
+	segmentLength := uint32(4)
+	if n > 0 {
+		e := math.Floor(math.Log(float64(n))/math.Log(3.33) + 2.25)
+		segmentLength = 1 << uint(e)
+	}
+	if segmentLength > maxSegmentLength {
+		segmentLength = maxSegmentLength
+	}
+
+	capacity := uint64(0)
+	if n > 1 {
+		sizeFactor := math.Max(1.125, 0.875+0.25*math.Log(1000000)/math.Log(float64(n)))
+		capacity = uint64(math.Round(float64(n) * sizeFactor))
+	}
+
+	segmentCount := uint32(1)
+	if c := (capacity + uint64(segmentLength) - 1) / uint64(segmentLength); c > 3 {
+		segmentCount = uint32(c) - 2
+	}
+
+	f.n = n
+	f.segmentLength = segmentLength
+	f.segmentLengthMask = segmentLength - 1
+	f.segmentCount = segmentCount
+	f.segmentCountLength = segmentCount * segmentLength
+}
+
+func (f *Filter) arrayLength() uint32 {
+	return (f.segmentCount + 2) * f.segmentLength
+}
+
+func build(n int, hash func(i int, seed uint64) uint64, fingerprintBits int, baseSeed uint64) (*Filter, error) {
+	if fingerprintBits != 8 && fingerprintBits != 16 {
+		return nil, fmt.Errorf("fuse: fingerprint bits %d is not 8 or 16", fingerprintBits)
+	}
+	if uint64(n) > MaxKeys {
+		return nil, fmt.Errorf("fuse: number of keys %d exceeds %d", n, MaxKeys)
+	}
+
+	hashes := make([]uint64, n)
+
+	for attempt := uint64(0); attempt < MaxAttempts; attempt++ {
+		seed := circlehash.Hash64Uint64x2(baseSeed, attempt, baseSeed)
+
+		for i := range hashes {
+			hashes[i] = hash(i, seed)
+		}
+		distinct := dedup(hashes)
+
+		f := &Filter{seed: seed, fpBits: fingerprintBits}
+		f.setParameters(uint32(len(distinct)))
+
+		order, found, ok := f.peel(distinct)
+		if !ok {
+			continue
+		}
+
+		f.fingerprints = make([]byte, int(f.arrayLength())*fingerprintBits/8)
+		f.assign(order, found)
+		return f, nil
+	}
+
+	return nil, ErrConstructionFailed
+}
+
+// dedup sorts hashes and returns them without duplicates.
+func dedup(hashes []uint64) []uint64 {
+	sort.Slice(hashes, func(i, j int) bool { return hashes[i] < hashes[j] })
+	n := 0
+	for i, h := range hashes {
+		if i == 0 || h != hashes[n-1] {
+			hashes[n] = h
+			n++
+		}
+	}
+	return hashes[:n]
+}
+
+// peel returns hashes in peeling order and which of their 3 positions
+// was peeled.  It returns false if hashes can't be fully peeled.
+func (f *Filter) peel(hashes []uint64) ([]uint64, []uint8, bool) {
+	arrayLength := f.arrayLength()
+
+	// counts[p] is (number of hashes at p) << 2 | xor of their position
+	// index (0, 1, or 2) at p, so the index is known when count is 1.
+	counts := make([]uint32, arrayLength)
+	xors := make([]uint64, arrayLength)
+
+	for _, h := range hashes {
+		p0, p1, p2 := f.positions(h)
+		counts[p0] += 4
+		xors[p0] ^= h
+		counts[p1] = (counts[p1] + 4) ^ 1
+		xors[p1] ^= h
+		counts[p2] = (counts[p2] + 4) ^ 2
+		xors[p2] ^= h
+	}
+
+	queue := make([]uint32, 0, 64)
+	for p, c := range counts {
+		if c>>2 == 1 {
+			queue = append(queue, uint32(p))
+		}
+	}
+
+	order := make([]uint64, 0, len(hashes))
+	found := make([]uint8, 0, len(hashes))
+
+	for len(queue) > 0 {
+		p := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		if counts[p]>>2 != 1 {
+			continue
+		}
+
+		h := xors[p]
+		order = append(order, h)
+		found = append(found, uint8(counts[p]&3))
+
+		p0, p1, p2 := f.positions(h)
+		for j, q := range [3]uint32{p0, p1, p2} {
+			counts[q] = (counts[q] - 4) ^ uint32(j)
+			xors[q] ^= h
+			if counts[q]>>2 == 1 {
+				queue = append(queue, q)
+			}
+		}
+	}
+
+	return order, found, len(order) == len(hashes)
+}
+
+// assign sets fingerprints in reverse peeling order, so fingerprint at
+// peeled position of each hash is set after all others it depends on.
+func (f *Filter) assign(order []uint64, found []uint8) {
+	for i := len(order) - 1; i >= 0; i-- {
+		h := order[i]
+		p0, p1, p2 := f.positions(h)
+		p := [3]uint32{p0, p1, p2}[found[i]]
+
+		if f.fpBits == 8 {
+			f.fingerprints[p] = uint8(fingerprint(h)) ^ f.fingerprints[p0] ^ f.fingerprints[p1] ^ f.fingerprints[p2]
+		} else {
+			fp := uint16(fingerprint(h)) ^ f.get16(p0) ^ f.get16(p1) ^ f.get16(p2)
+			binary.LittleEndian.PutUint16(f.fingerprints[2*p:], fp)
+		}
+	}
+}
+
+// MarshalBinary returns serialized f.  Serialized filter records format
+// version, algorithm, fingerprint size, seed, and segment layout.
+func (f *Filter) MarshalBinary() ([]byte, error) {
+	data := make([]byte, headerSize+len(f.fingerprints))
+	copy(data, magic[:])
+	data[4] = formatVersion
+	data[5] = algorithmCircleHash64f3Wise
+	data[6] = byte(f.fpBits)
+	binary.LittleEndian.PutUint64(data[8:], f.seed)
+	binary.LittleEndian.PutUint32(data[16:], f.n)
+	binary.LittleEndian.PutUint32(data[20:], f.segmentLength)
+	binary.LittleEndian.PutUint32(data[24:], f.segmentCount)
+	binary.LittleEndian.PutUint32(data[28:], f.arrayLength())
+	copy(data[headerSize:], f.fingerprints)
+	return data, nil
+}
+
+// Load returns Filter serialized by MarshalBinary.  Returned filter uses
+// data without copying, so data must not be modified while filter is in use.
+func Load(data []byte) (*Filter, error) {
+	if len(data) < headerSize || [4]byte{data[0], data[1], data[2], data[3]} != magic {
+		return nil, ErrInvalidData
+	}
+	if data[4] != formatVersion {
+		return nil, fmt.Errorf("fuse: unsupported format version %d", data[4])
+	}
+	if data[5] != algorithmCircleHash64f3Wise {
+		return nil, fmt.Errorf("fuse: unsupported algorithm %d", data[5])
+	}
+
+	fpBits := int(data[6])
+	if fpBits != 8 && fpBits != 16 {
+		return nil, ErrInvalidData
+	}
+
+	n := binary.LittleEndian.Uint32(data[16:])
+	if uint64(n) > MaxKeys {
+		return nil, ErrInvalidData
+	}
+
+	f := &Filter{
+		seed:   binary.LittleEndian.Uint64(data[8:]),
+		fpBits: fpBits,
+	}
+	f.setParameters(n)
+
+	if binary.LittleEndian.Uint32(data[20:]) != f.segmentLength ||
+		binary.LittleEndian.Uint32(data[24:]) != f.segmentCount ||
+		binary.LittleEndian.Uint32(data[28:]) != f.arrayLength() {
+		return nil, ErrInvalidData
+	}
+	if uint64(len(data)-headerSize) != uint64(f.arrayLength())*uint64(fpBits/8) {
+		return nil, ErrInvalidData
+	}
+
+	f.fingerprints = data[headerSize:]
+	return f, nil
+}
@@ -0,0 +1,239 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fuse
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/fxamacker/circlehash"
+)
+
+const testSeed = uint64(0x9E3779B97F4A7C15)
+
+func testKeys(n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = "key" + strconv.Itoa(i)
+	}
+	return keys
+}
+
+func TestBuild(t *testing.T) {
+	for _, fpBits := range []int{8, 16} {
+		for _, n := range []int{0, 1, 2, 3, 10, 100, 1000, 100000} {
+			t.Run(fmt.Sprintf("f=%d,n=%d", fpBits, n), func(t *testing.T) {
+				keys := testKeys(n)
+				f, err := BuildStrings(keys, fpBits, testSeed)
+				if err != nil {
+					t.Fatalf("BuildStrings() returned error %v", err)
+				}
+				if f.Len() != n {
+					t.Errorf("Len() = %d; want %d", f.Len(), n)
+				}
+				if f.FingerprintBits() != fpBits {
+					t.Errorf("FingerprintBits() = %d; want %d", f.FingerprintBits(), fpBits)
+				}
+				for _, k := range keys {
+					if !f.ContainsString(k) || !f.Contains([]byte(k)) {
+						t.Fatalf("ContainsString(%q) = false", k)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestBuildInvalid(t *testing.T) {
+	for _, fpBits := range []int{0, 4, 12, 32} {
+		if _, err := BuildStrings(testKeys(10), fpBits, testSeed); err == nil {
+			t.Errorf("BuildStrings() with %d-bit fingerprints didn't return error", fpBits)
+		}
+	}
+}
+
+func TestBuildDuplicates(t *testing.T) {
+	keys := append(testKeys(1000), testKeys(500)...)
+	f, err := BuildStrings(keys, 8, testSeed)
+	if err != nil {
+		t.Fatalf("BuildStrings() with duplicate keys returned error %v", err)
+	}
+	if f.Len() != 1000 {
+		t.Errorf("Len() = %d; want 1000", f.Len())
+	}
+	for _, k := range keys {
+		if !f.ContainsString(k) {
+			t.Fatalf("ContainsString(%q) = false", k)
+		}
+	}
+}
+
+func TestBuildDeterministic(t *testing.T) {
+	keys := testKeys(10000)
+	f1, _ := BuildStrings(keys, 16, testSeed)
+
+	// Key order doesn't matter.
+	reversed := make([][]byte, len(keys))
+	for i, k := range keys {
+		reversed[len(keys)-1-i] = []byte(k)
+	}
+	f2, _ := Build(reversed, 16, testSeed)
+
+	data1, _ := f1.MarshalBinary()
+	data2, _ := f2.MarshalBinary()
+	if !bytes.Equal(data1, data2) {
+		t.Error("Build() with the same keys and seed returned different filters")
+	}
+}
+
+func TestSeedDerivation(t *testing.T) {
+	f, err := BuildStrings(testKeys(1000), 8, testSeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found := false
+	for i := uint64(0); i < MaxAttempts; i++ {
+		if f.Seed() == circlehash.Hash64Uint64x2(testSeed, i, testSeed) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Errorf("Seed() = 0x%x isn't derived from base seed 0x%x", f.Seed(), testSeed)
+	}
+}
+
+func TestFalsePositiveRate(t *testing.T) {
+	const n = 100000
+	const trials = 2000000
+
+	for _, fpBits := range []int{8, 16} {
+		f, err := BuildStrings(testKeys(n), fpBits, testSeed)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		fp := 0
+		for i := 0; i < trials; i++ {
+			if f.ContainsString("other" + strconv.Itoa(i)) {
+				fp++
+			}
+		}
+
+		got := float64(fp) / trials
+		want := 1 / float64(uint64(1)<<uint(fpBits))
+		if got > want*1.3 || got < want/1.3 {
+			t.Errorf("%d-bit fingerprints: false positive rate = %v; want about %v", fpBits, got, want)
+		}
+
+		bitsPerKey := float64(8*len(f.fingerprints)) / n
+		if maxBitsPerKey := float64(fpBits) * 1.2; bitsPerKey > maxBitsPerKey {
+			t.Errorf("%d-bit fingerprints: filter uses %v bits per key; want at most %v", fpBits, bitsPerKey, maxBitsPerKey)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	keys := testKeys(5000)
+	for _, fpBits := range []int{8, 16} {
+		f, _ := BuildStrings(keys, fpBits, testSeed)
+		data, err := f.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary() returned error %v", err)
+		}
+
+		g, err := Load(data)
+		if err != nil {
+			t.Fatalf("Load() returned error %v", err)
+		}
+		if &g.fingerprints[0] != &data[headerSize] {
+			t.Error("Load() copied data")
+		}
+		if g.Len() != f.Len() || g.Seed() != f.Seed() || g.FingerprintBits() != fpBits {
+			t.Errorf("Load() returned filter with len %d, seed 0x%x, %d-bit fingerprints; want %d, 0x%x, %d",
+				g.Len(), g.Seed(), g.FingerprintBits(), f.Len(), f.Seed(), fpBits)
+		}
+		for _, k := range keys {
+			if !g.ContainsString(k) {
+				t.Fatalf("loaded filter doesn't contain %q", k)
+			}
+		}
+
+		data2, _ := g.MarshalBinary()
+		if !bytes.Equal(data, data2) {
+			t.Error("MarshalBinary() of loaded filter returned different data")
+		}
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	f, _ := BuildStrings(testKeys(100), 16, testSeed)
+	valid, _ := f.MarshalBinary()
+
+	modify := func(fn func(b []byte)) []byte {
+		b := append([]byte(nil), valid...)
+		fn(b)
+		return b
+	}
+
+	testCases := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"short header", valid[:headerSize-1]},
+		{"bad magic", modify(func(b []byte) { b[0] = 'X' })},
+		{"bad version", modify(func(b []byte) { b[4] = 2 })},
+		{"bad algorithm", modify(func(b []byte) { b[5] = 0 })},
+		{"bad fingerprint bits", modify(func(b []byte) { b[6] = 32 })},
+		{"bad number of keys", modify(func(b []byte) { b[17] = 1 })},
+		{"bad segment length", modify(func(b []byte) { b[20]++ })},
+		{"bad segment count", modify(func(b []byte) { b[24]++ })},
+		{"bad array length", modify(func(b []byte) { b[28]++ })},
+		{"8-bit fingerprints with 16-bit data", modify(func(b []byte) { b[6] = 8 })},
+		{"truncated", valid[:len(valid)-1]},
+		{"extra data", append(append([]byte(nil), valid...), 0)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Load(tc.data); err == nil {
+				t.Error("Load() didn't return error")
+			}
+		})
+	}
+}
+
+func BenchmarkBuild(b *testing.B) {
+	keys := testKeys(100000)
+	for i := 0; i < b.N; i++ {
+		_, _ = BuildStrings(keys, 8, testSeed)
+	}
+}
+
+func BenchmarkContains(b *testing.B) {
+	for _, fpBits := range []int{8, 16} {
+		f, _ := BuildStrings(testKeys(1000000), fpBits, testSeed)
+		key := []byte("user.name@example.com")
+		b.Run(strconv.Itoa(fpBits), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_ = f.Contains(key)
+			}
+		})
+	}
+}
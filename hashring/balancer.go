@@ -0,0 +1,109 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hashring
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/fxamacker/circlehash"
+)
+
+// Balancer assigns keys to nodes of a Ring with bounded loads.
+// Each node has capacity ceil(c * (m+1) / n), where m is total load
+// and n is number of nodes.  Key is assigned to the first node clockwise
+// from its position whose load is below capacity, so no node has load
+// more than about c times the average.
+//
+// Balancer is safe for concurrent use.
+type Balancer struct {
+	ring *Ring
+	c    float64
+
+	mu    sync.Mutex
+	loads map[string]int
+	total int
+}
+
+// NewBalancer returns a Balancer for ring with load factor c > 1.
+func NewBalancer(ring *Ring, c float64) (*Balancer, error) {
+	if !(c > 1) || math.IsInf(c, 1) {
+		return nil, fmt.Errorf("hashring: load factor %v isn't greater than 1", c)
+	}
+	return &Balancer{ring: ring, c: c, loads: make(map[string]int)}, nil
+}
+
+// Acquire assigns key b to a node, increments load of node, and
+// returns node.  Caller must call Release when key is no longer assigned.
+func (bl *Balancer) Acquire(b []byte) (string, error) {
+	return bl.acquire(circlehash.Hash64(b, bl.ring.seed))
+}
+
+// AcquireString assigns key s to a node, increments load of node, and
+// returns node.  Caller must call Release when key is no longer assigned.
+func (bl *Balancer) AcquireString(s string) (string, error) {
+	return bl.acquire(circlehash.Hash64String(s, bl.ring.seed))
+}
+
+func (bl *Balancer) acquire(h uint64) (string, error) {
+	s := bl.ring.load()
+	if len(s.points) == 0 {
+		return "", ErrEmptyRing
+	}
+
+	bl.mu.Lock()
+	defer bl.mu.Unlock()
+
+	capacity := int(math.Ceil(bl.c * float64(bl.total+1) / float64(len(s.nodes))))
+
+	i := s.search(h)
+	for j := 0; j < len(s.points); j++ {
+		node := s.points[i].node
+		if bl.loads[node] < capacity {
+			bl.loads[node]++
+			bl.total++
+			return node, nil
+		}
+		i++
+		if i == len(s.points) {
+			i = 0
+		}
+	}
+
+	// Unreachable because total capacity exceeds total load.
+	return "", ErrEmptyRing
+}
+
+// Release decrements load of node.
+func (bl *Balancer) Release(node string) {
+	bl.mu.Lock()
+	defer bl.mu.Unlock()
+
+	if bl.loads[node] > 0 {
+		bl.loads[node]--
+		bl.total--
+		if bl.loads[node] == 0 {
+			delete(bl.loads, node)
+		}
+	}
+}
+
+// Load returns load of node.
+func (bl *Balancer) Load(node string) int {
+	bl.mu.Lock()
+	defer bl.mu.Unlock()
+	return bl.loads[node]
+}
@@ -0,0 +1,114 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hashring
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestNewBalancerInvalid(t *testing.T) {
+	r := newTestRing(t, 10, "a")
+	for _, c := range []float64{0, 1, -1, math.NaN(), math.Inf(1)} {
+		if _, err := NewBalancer(r, c); err == nil {
+			t.Errorf("NewBalancer(%v) didn't return error", c)
+		}
+	}
+}
+
+func TestBalancerBoundedLoads(t *testing.T) {
+	const numKeys = 10000
+	const c = 1.25
+
+	var nodes []string
+	for i := 0; i < 8; i++ {
+		nodes = append(nodes, "node"+strconv.Itoa(i))
+	}
+	r := newTestRing(t, 20, nodes...)
+	bl, err := NewBalancer(r, c)
+	if err != nil {
+		t.Fatalf("NewBalancer() returned error %v", err)
+	}
+
+	// Skewed keys: many copies of few hot keys.
+	assigned := make([]string, numKeys)
+	for i := range assigned {
+		key := "hot" + strconv.Itoa(i%20)
+		if i%2 == 0 {
+			key = "key" + strconv.Itoa(i)
+		}
+		node, err := bl.AcquireString(key)
+		if err != nil {
+			t.Fatalf("AcquireString() returned error %v", err)
+		}
+		assigned[i] = node
+	}
+
+	maxLoad := int(math.Ceil(c * numKeys / float64(len(nodes))))
+	total := 0
+	for _, node := range nodes {
+		load := bl.Load(node)
+		if load > maxLoad {
+			t.Errorf("node %s has load %d; want at most %d", node, load, maxLoad)
+		}
+		total += load
+	}
+	if total != numKeys {
+		t.Errorf("total load = %d; want %d", total, numKeys)
+	}
+
+	// Keys go to their ring node when it has capacity.
+	for _, node := range assigned {
+		bl.Release(node)
+	}
+	for _, node := range nodes {
+		if load := bl.Load(node); load != 0 {
+			t.Errorf("node %s has load %d after releasing all keys", node, load)
+		}
+	}
+	got, _ := bl.Acquire([]byte("key"))
+	if want, _ := r.Get([]byte("key")); got != want {
+		t.Errorf("Acquire() on idle balancer = %s; want %s", got, want)
+	}
+	bl.Release(got)
+	bl.Release(got) // extra release is ignored
+	if bl.total != 0 {
+		t.Errorf("total load = %d after extra release; want 0", bl.total)
+	}
+}
+
+func TestBalancerEmptyRing(t *testing.T) {
+	r := newTestRing(t, 10)
+	bl, _ := NewBalancer(r, 1.5)
+	if _, err := bl.AcquireString("key"); !errors.Is(err, ErrEmptyRing) {
+		t.Errorf("AcquireString() returned error %v; want %v", err, ErrEmptyRing)
+	}
+}
+
+func BenchmarkBalancerAcquireRelease(b *testing.B) {
+	r := newTestRing(b, 100)
+	for i := 0; i < 16; i++ {
+		_ = r.Add("node" + strconv.Itoa(i))
+	}
+	bl, _ := NewBalancer(r, 1.25)
+	key := []byte("user.name@example.com")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		node, _ := bl.Acquire(key)
+		bl.Release(node)
+	}
+}
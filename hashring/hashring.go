@@ -0,0 +1,257 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package hashring implements a consistent hash ring using CircleHash64f.
+//
+// Each node is placed on the ring at virtual node positions
+//
+//	circlehash.Hash64String(node+"#"+strconv.Itoa(i), seed)
+//
+// for i in [0, vnodes).  Key with digest circlehash.Hash64(key, seed) is
+// assigned to node of the first virtual node at or after the digest,
+// wrapping around.  Adding or removing a node only moves keys to or
+// from that node.
+//
+// Balancer adds "consistent hashing with bounded loads" by Vahab Mirrokni,
+// Mikkel Thorup, and Morteza Zadimoghaddam on top of Ring.
+package hashring
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+
+	"github.com/fxamacker/circlehash"
+)
+
+var (
+	// ErrEmptyRing is returned when looking up a key in a ring without nodes.
+	ErrEmptyRing = errors.New("hashring: ring has no nodes")
+
+	// ErrNodeExists is returned when adding a node that is already in ring.
+	ErrNodeExists = errors.New("hashring: node already exists")
+
+	// ErrNodeNotFound is returned when removing a node that isn't in ring.
+	ErrNodeNotFound = errors.New("hashring: node not found")
+)
+
+type point struct {
+	hash uint64
+	node string
+}
+
+// state is an immutable snapshot of ring membership.
+type state struct {
+	points []point        // sorted by hash, then node
+	nodes  map[string]int // node to number of virtual nodes
+}
+
+// Ring is a consistent hash ring.  Lookups are lock-free and safe for
+// concurrent use with membership changes, which replace ring snapshot
+// atomically.
+type Ring struct {
+	seed   uint64
+	vnodes int
+
+	mu    sync.Mutex   // serializes membership changes
+	state atomic.Value // *state
+}
+
+// New returns an empty Ring that places vnodes virtual nodes per node
+// by default.  More virtual nodes distribute keys more evenly.
+func New(vnodes int, seed uint64) (*Ring, error) {
+	if vnodes < 1 {
+		return nil, fmt.Errorf("hashring: number of virtual nodes %d is less than 1", vnodes)
+	}
+	r := &Ring{seed: seed, vnodes: vnodes}
+	r.state.Store(&state{nodes: map[string]int{}})
+	return r, nil
+}
+
+// Seed returns the seed used by r.
+func (r *Ring) Seed() uint64 {
+	return r.seed
+}
+
+func (r *Ring) load() *state {
+	return r.state.Load().(*state)
+}
+
+// Len returns number of nodes in r.
+func (r *Ring) Len() int {
+	return len(r.load().nodes)
+}
+
+// Nodes returns sorted names of nodes in r.
+func (r *Ring) Nodes() []string {
+	s := r.load()
+	nodes := make([]string, 0, len(s.nodes))
+	for node := range s.nodes {
+		nodes = append(nodes, node)
+	}
+	sort.Strings(nodes)
+	return nodes
+}
+
+// Add adds node with default number of virtual nodes.
+func (r *Ring) Add(node string) error {
+	return r.AddWeighted(node, r.vnodes)
+}
+
+// AddWeighted adds node with vnodes virtual nodes, so node receives
+// share of keys proportional to vnodes.
+func (r *Ring) AddWeighted(node string, vnodes int) error {
+	if vnodes < 1 {
+		return fmt.Errorf("hashring: number of virtual nodes %d is less than 1", vnodes)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old := r.load()
+	if _, ok := old.nodes[node]; ok {
+		return ErrNodeExists
+	}
+
+	points := make([]point, len(old.points), len(old.points)+vnodes)
+	copy(points, old.points)
+	for i := 0; i < vnodes; i++ {
+		points = append(points, point{
+			hash: circlehash.Hash64String(node+"#"+strconv.Itoa(i), r.seed),
+			node: node,
+		})
+	}
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].hash != points[j].hash {
+			return points[i].hash < points[j].hash
+		}
+		return points[i].node < points[j].node
+	})
+
+	nodes := make(map[string]int, len(old.nodes)+1)
+	for n, v := range old.nodes {
+		nodes[n] = v
+	}
+	nodes[node] = vnodes
+
+	r.state.Store(&state{points: points, nodes: nodes})
+	return nil
+}
+
+// Remove removes node from r.
+func (r *Ring) Remove(node string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old := r.load()
+	vnodes, ok := old.nodes[node]
+	if !ok {
+		return ErrNodeNotFound
+	}
+
+	points := make([]point, 0, len(old.points)-vnodes)
+	for _, p := range old.points {
+		if p.node != node {
+			points = append(points, p)
+		}
+	}
+
+	nodes := make(map[string]int, len(old.nodes)-1)
+	for n, v := range old.nodes {
+		if n != node {
+			nodes[n] = v
+		}
+	}
+
+	r.state.Store(&state{points: points, nodes: nodes})
+	return nil
+}
+
+// Get returns node for key b.
+func (r *Ring) Get(b []byte) (string, error) {
+	return r.get(circlehash.Hash64(b, r.seed))
+}
+
+// GetString returns node for key s.
+func (r *Ring) GetString(s string) (string, error) {
+	return r.get(circlehash.Hash64String(s, r.seed))
+}
+
+func (r *Ring) get(h uint64) (string, error) {
+	s := r.load()
+	if len(s.points) == 0 {
+		return "", ErrEmptyRing
+	}
+	return s.points[s.search(h)].node, nil
+}
+
+// GetN returns up to n distinct nodes for key b, starting with node
+// returned by Get and continuing clockwise.  It can be used to choose replicas.
+func (r *Ring) GetN(b []byte, n int) ([]string, error) {
+	return r.getN(circlehash.Hash64(b, r.seed), n)
+}
+
+// GetNString returns up to n distinct nodes for key s, starting with node
+// returned by GetString and continuing clockwise.
+func (r *Ring) GetNString(s string, n int) ([]string, error) {
+	return r.getN(circlehash.Hash64String(s, r.seed), n)
+}
+
+func (r *Ring) getN(h uint64, n int) ([]string, error) {
+	s := r.load()
+	if len(s.points) == 0 {
+		return nil, ErrEmptyRing
+	}
+	if n > len(s.nodes) {
+		n = len(s.nodes)
+	}
+	if n <= 0 {
+		return nil, nil
+	}
+
+	nodes := make([]string, 0, n)
+	i := s.search(h)
+	for len(nodes) < n {
+		node := s.points[i].node
+		if !contains(nodes, node) {
+			nodes = append(nodes, node)
+		}
+		i++
+		if i == len(s.points) {
+			i = 0
+		}
+	}
+	return nodes, nil
+}
+
+func contains(nodes []string, node string) bool {
+	for _, n := range nodes {
+		if n == node {
+			return true
+		}
+	}
+	return false
+}
+
+// search returns index of first point with hash >= h, wrapping around.
+func (s *state) search(h uint64) int {
+	i := sort.Search(len(s.points), func(i int) bool { return s.points[i].hash >= h })
+	if i == len(s.points) {
+		i = 0
+	}
+	return i
+}
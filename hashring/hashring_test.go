@@ -0,0 +1,260 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hashring
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/fxamacker/circlehash"
+)
+
+const testSeed = uint64(0x9E3779B97F4A7C15)
+
+func newTestRing(t testing.TB, vnodes int, nodes ...string) *Ring {
+	r, err := New(vnodes, testSeed)
+	if err != nil {
+		t.Fatalf("New(%d) returned error %v", vnodes, err)
+	}
+	for _, node := range nodes {
+		if err := r.Add(node); err != nil {
+			t.Fatalf("Add(%q) returned error %v", node, err)
+		}
+	}
+	return r
+}
+
+func assignments(t *testing.T, r *Ring, numKeys int) []string {
+	t.Helper()
+	nodes := make([]string, numKeys)
+	for i := range nodes {
+		node, err := r.GetString("key" + strconv.Itoa(i))
+		if err != nil {
+			t.Fatalf("GetString() returned error %v", err)
+		}
+		nodes[i] = node
+	}
+	return nodes
+}
+
+func TestNewInvalid(t *testing.T) {
+	if _, err := New(0, testSeed); err == nil {
+		t.Error("New(0) didn't return error")
+	}
+}
+
+func TestEmptyRing(t *testing.T) {
+	r := newTestRing(t, 10)
+	if _, err := r.Get([]byte("key")); !errors.Is(err, ErrEmptyRing) {
+		t.Errorf("Get() returned error %v; want %v", err, ErrEmptyRing)
+	}
+	if _, err := r.GetN([]byte("key"), 2); !errors.Is(err, ErrEmptyRing) {
+		t.Errorf("GetN() returned error %v; want %v", err, ErrEmptyRing)
+	}
+}
+
+func TestVirtualNodePlacement(t *testing.T) {
+	r := newTestRing(t, 3, "a")
+	s := r.load()
+	for i := 0; i < 3; i++ {
+		h := circlehash.Hash64String("a#"+strconv.Itoa(i), testSeed)
+		found := false
+		for _, p := range s.points {
+			if p.hash == h && p.node == "a" {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("virtual node a#%d isn't at 0x%016x", i, h)
+		}
+	}
+}
+
+func TestAddRemove(t *testing.T) {
+	r := newTestRing(t, 10, "b", "a")
+	if err := r.Add("a"); !errors.Is(err, ErrNodeExists) {
+		t.Errorf("Add() of existing node returned error %v; want %v", err, ErrNodeExists)
+	}
+	if err := r.Remove("c"); !errors.Is(err, ErrNodeNotFound) {
+		t.Errorf("Remove() of missing node returned error %v; want %v", err, ErrNodeNotFound)
+	}
+	if err := r.AddWeighted("c", 0); err == nil {
+		t.Error("AddWeighted() with 0 virtual nodes didn't return error")
+	}
+	if got := r.Nodes(); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("Nodes() = %q; want [a b]", got)
+	}
+
+	if err := r.Remove("a"); err != nil {
+		t.Fatalf("Remove() returned error %v", err)
+	}
+	if r.Len() != 1 || len(r.load().points) != 10 {
+		t.Errorf("ring has %d nodes and %d points; want 1 and 10", r.Len(), len(r.load().points))
+	}
+}
+
+func TestDistribution(t *testing.T) {
+	const numKeys = 100000
+
+	var nodes []string
+	for i := 0; i < 10; i++ {
+		nodes = append(nodes, "node"+strconv.Itoa(i))
+	}
+	r := newTestRing(t, 500, nodes...)
+
+	counts := make(map[string]int)
+	for _, node := range assignments(t, r, numKeys) {
+		counts[node]++
+	}
+
+	// With 500 virtual nodes, each node gets within about 15% of average.
+	for _, node := range nodes {
+		if c := counts[node]; math.Abs(float64(c)-numKeys/10) > 0.15*numKeys/10 {
+			t.Errorf("node %s has %d keys; want about %d", node, c, numKeys/10)
+		}
+	}
+}
+
+func TestWeighted(t *testing.T) {
+	const numKeys = 100000
+
+	r := newTestRing(t, 1)
+	_ = r.AddWeighted("small", 200)
+	_ = r.AddWeighted("large", 600)
+
+	counts := make(map[string]int)
+	for _, node := range assignments(t, r, numKeys) {
+		counts[node]++
+	}
+	if got := float64(counts["large"]) / numKeys; math.Abs(got-0.75) > 0.05 {
+		t.Errorf("node with 3/4 of virtual nodes has %v of keys; want about 0.75", got)
+	}
+}
+
+func TestMinimalMovement(t *testing.T) {
+	const numKeys = 20000
+
+	r := newTestRing(t, 100, "a", "b", "c", "d")
+	before := assignments(t, r, numKeys)
+
+	_ = r.Add("e")
+	afterAdd := assignments(t, r, numKeys)
+	moved := 0
+	for i := range before {
+		if before[i] != afterAdd[i] {
+			if afterAdd[i] != "e" {
+				t.Fatalf("key %d moved from %s to %s when adding e", i, before[i], afterAdd[i])
+			}
+			moved++
+		}
+	}
+	if got := float64(moved) / numKeys; math.Abs(got-0.2) > 0.05 {
+		t.Errorf("%v of keys moved when adding fifth node; want about 0.2", got)
+	}
+
+	_ = r.Remove("b")
+	afterRemove := assignments(t, r, numKeys)
+	for i := range afterAdd {
+		if afterAdd[i] != afterRemove[i] && afterAdd[i] != "b" {
+			t.Fatalf("key %d moved from %s to %s when removing b", i, afterAdd[i], afterRemove[i])
+		}
+	}
+
+	// Restoring original membership restores original assignments.
+	_ = r.Remove("e")
+	_ = r.Add("b")
+	if got := assignments(t, r, numKeys); !reflect.DeepEqual(got, before) {
+		t.Error("assignments changed after removing and adding nodes back")
+	}
+}
+
+func TestGetN(t *testing.T) {
+	r := newTestRing(t, 50, "a", "b", "c", "d", "e")
+
+	for i := 0; i < 1000; i++ {
+		key := "key" + strconv.Itoa(i)
+		nodes, err := r.GetNString(key, 3)
+		if err != nil {
+			t.Fatalf("GetNString() returned error %v", err)
+		}
+		if len(nodes) != 3 {
+			t.Fatalf("GetNString() returned %d nodes; want 3", len(nodes))
+		}
+		if first, _ := r.GetString(key); nodes[0] != first {
+			t.Fatalf("GetNString()[0] = %s; want %s", nodes[0], first)
+		}
+		if nodes[0] == nodes[1] || nodes[0] == nodes[2] || nodes[1] == nodes[2] {
+			t.Fatalf("GetNString() = %q has duplicate nodes", nodes)
+		}
+
+		all, _ := r.GetN([]byte(key), 10)
+		if len(all) != 5 || !reflect.DeepEqual(all[:3], nodes) {
+			t.Fatalf("GetN(10) = %q; want 5 nodes starting with %q", all, nodes)
+		}
+	}
+
+	if nodes, _ := r.GetNString("key", 0); len(nodes) != 0 {
+		t.Errorf("GetNString(0) = %q; want none", nodes)
+	}
+}
+
+func TestConcurrentReads(t *testing.T) {
+	r := newTestRing(t, 50, "a", "b", "c")
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for g := 0; g < 4; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; ; i++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				node, err := r.GetString(strconv.Itoa(g*1000000 + i))
+				if err != nil || node == "" {
+					t.Errorf("GetString() = %q, %v during membership change", node, err)
+					return
+				}
+			}
+		}(g)
+	}
+
+	for i := 0; i < 200; i++ {
+		node := "n" + strconv.Itoa(i)
+		_ = r.Add(node)
+		_ = r.Remove(node)
+	}
+	close(stop)
+	wg.Wait()
+}
+
+func BenchmarkGet(b *testing.B) {
+	r := newTestRing(b, 200)
+	for i := 0; i < 100; i++ {
+		_ = r.Add("node" + strconv.Itoa(i))
+	}
+	key := []byte("user.name@example.com")
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = r.Get(key)
+	}
+}
@@ -0,0 +1,300 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hll
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"testing"
+)
+
+const testSeed = uint64(0x9E3779B97F4A7C15)
+
+func newTestSketch(t testing.TB, precision int) *Sketch {
+	s, err := New(precision, testSeed)
+	if err != nil {
+		t.Fatalf("New(%d) returned error %v", precision, err)
+	}
+	return s
+}
+
+// checkEstimate checks that estimate of n items is within 5 standard errors.
+func checkEstimate(t *testing.T, s *Sketch, n int) {
+	t.Helper()
+
+	stdErr := 1.04 / math.Sqrt(float64(uint64(1)<<s.p))
+	got := s.Estimate()
+	if relErr := math.Abs(float64(got)-float64(n)) / float64(n); relErr > 5*stdErr {
+		t.Errorf("p=%d: Estimate() = %d; want %d (relative error %v exceeds %v)", s.p, got, n, relErr, 5*stdErr)
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	for _, p := range []int{-1, 0, MinPrecision - 1, MaxPrecision + 1} {
+		if _, err := New(p, testSeed); err == nil {
+			t.Errorf("New(%d) didn't return error", p)
+		}
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	hashes := []uint64{
+		0,
+		math.MaxUint64,
+		0x8000000000000000,
+		0x0000008000000000,
+		0x0000004000000000,
+		0x0000000000000001,
+		0x9E3779B97F4A7C15,
+		0x1234567800000000,
+	}
+	for p := MinPrecision; p <= MaxPrecision; p++ {
+		s := newTestSketch(t, p)
+		for _, h := range hashes {
+			wantIdx := uint32(h >> (64 - s.p))
+			wantRho := rho(h<<s.p, 64-s.p)
+
+			k := s.encodeHash(h)
+			if !s.validSparseEntry(k) {
+				t.Errorf("p=%d: encodeHash(0x%016x) = 0x%x isn't valid", p, h, k)
+			}
+			idx, r := s.decode(k)
+			if idx != wantIdx || r != wantRho {
+				t.Errorf("p=%d: decode(encodeHash(0x%016x)) = (%d, %d); want (%d, %d)", p, h, idx, r, wantIdx, wantRho)
+			}
+		}
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	for _, p := range []int{4, 10, 14, 18} {
+		for _, n := range []int{1, 10, 100, 1000, 10000, 100000, 1000000} {
+			t.Run(fmt.Sprintf("p=%d,n=%d", p, n), func(t *testing.T) {
+				s := newTestSketch(t, p)
+				for i := 0; i < n; i++ {
+					s.AddString("user" + strconv.Itoa(i))
+				}
+				checkEstimate(t, s, n)
+			})
+		}
+	}
+}
+
+func TestEstimateSparseExact(t *testing.T) {
+	// Sparse representation is nearly exact for small cardinalities.
+	s := newTestSketch(t, 14)
+	for n := 1; n <= 1000; n++ {
+		s.Add([]byte(strconv.Itoa(n)))
+		if n%100 == 0 {
+			if got := s.Estimate(); got < uint64(n)-1 || got > uint64(n)+1 {
+				t.Errorf("Estimate() = %d; want %d", got, n)
+			}
+		}
+	}
+	if !s.isSparse {
+		t.Error("sketch with 1000 items isn't sparse")
+	}
+}
+
+func TestEstimateDuplicates(t *testing.T) {
+	s := newTestSketch(t, 14)
+	for j := 0; j < 10; j++ {
+		for i := 0; i < 50000; i++ {
+			s.AddString(strconv.Itoa(i))
+		}
+	}
+	checkEstimate(t, s, 50000)
+}
+
+func TestSparseDenseEquivalent(t *testing.T) {
+	// Sparse entries converted to dense registers must equal registers
+	// of items added in dense representation.
+	sparse := newTestSketch(t, 12)
+	dense := newTestSketch(t, 12)
+	dense.toDense()
+
+	for i := 0; i < 800; i++ {
+		sparse.AddString(strconv.Itoa(i))
+		dense.AddString(strconv.Itoa(i))
+	}
+	sparse.flush()
+	if !sparse.isSparse {
+		t.Fatal("sketch isn't sparse")
+	}
+	sparse.toDense()
+
+	if !bytes.Equal(sparse.registers, dense.registers) {
+		t.Error("registers converted from sparse representation differ from dense registers")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	testCases := []struct {
+		name   string
+		n1, n2 int
+	}{
+		{"sparse+sparse", 100, 200},
+		{"sparse+dense", 100, 200000},
+		{"dense+sparse", 200000, 100},
+		{"dense+dense", 200000, 300000},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s1 := newTestSketch(t, 14)
+			s2 := newTestSketch(t, 14)
+			all := newTestSketch(t, 14)
+
+			// s2's items start from the middle of s1's items.
+			start := tc.n1 - tc.n1/2
+			for i := 0; i < tc.n1; i++ {
+				s1.AddString(strconv.Itoa(i))
+				all.AddString(strconv.Itoa(i))
+			}
+			for i := start; i < start+tc.n2; i++ {
+				s2.AddString(strconv.Itoa(i))
+				all.AddString(strconv.Itoa(i))
+			}
+
+			if err := s1.Merge(s2); err != nil {
+				t.Fatalf("Merge() returned error %v", err)
+			}
+			if got, want := s1.Estimate(), all.Estimate(); got != want {
+				t.Errorf("Estimate() of merged sketch = %d; want %d", got, want)
+			}
+
+			union := start + tc.n2
+			if union < tc.n1 {
+				union = tc.n1
+			}
+			checkEstimate(t, s1, union)
+		})
+	}
+}
+
+func TestMergeIncompatible(t *testing.T) {
+	s := newTestSketch(t, 14)
+	p12 := newTestSketch(t, 12)
+	otherSeed, _ := New(14, 1)
+
+	for _, o := range []*Sketch{p12, otherSeed} {
+		if err := s.Merge(o); !errors.Is(err, ErrIncompatible) {
+			t.Errorf("Merge(p=%d, seed=0x%x) returned error %v; want %v", o.p, o.seed, err, ErrIncompatible)
+		}
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	for _, n := range []int{0, 100, 100000} {
+		s := newTestSketch(t, 14)
+		for i := 0; i < n; i++ {
+			s.AddString(strconv.Itoa(i))
+		}
+
+		data, err := s.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary() returned error %v", err)
+		}
+
+		var s2 Sketch
+		if err := s2.UnmarshalBinary(data); err != nil {
+			t.Fatalf("UnmarshalBinary() returned error %v", err)
+		}
+		if s2.Precision() != s.Precision() || s2.Seed() != s.Seed() || s2.isSparse != s.isSparse {
+			t.Errorf("UnmarshalBinary() returned sketch with p=%d, seed=0x%x, sparse=%t; want p=%d, seed=0x%x, sparse=%t",
+				s2.Precision(), s2.Seed(), s2.isSparse, s.Precision(), s.Seed(), s.isSparse)
+		}
+		if s2.Estimate() != s.Estimate() {
+			t.Errorf("Estimate() of unmarshaled sketch = %d; want %d", s2.Estimate(), s.Estimate())
+		}
+
+		data2, _ := s2.MarshalBinary()
+		if !bytes.Equal(data, data2) {
+			t.Error("MarshalBinary() of unmarshaled sketch returned different data")
+		}
+	}
+}
+
+func TestUnmarshalBinaryInvalid(t *testing.T) {
+	sparse := newTestSketch(t, 10)
+	sparse.AddString("a")
+	sparse.AddString("b")
+	validSparse, _ := sparse.MarshalBinary()
+
+	dense := newTestSketch(t, 4)
+	for i := 0; i < 100; i++ {
+		dense.AddString(strconv.Itoa(i))
+	}
+	validDense, _ := dense.MarshalBinary()
+
+	modify := func(valid []byte, fn func(b []byte)) []byte {
+		b := append([]byte(nil), valid...)
+		fn(b)
+		return b
+	}
+
+	testCases := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"short header", validSparse[:headerSize-1]},
+		{"bad magic", modify(validSparse, func(b []byte) { b[0] = 'X' })},
+		{"bad version", modify(validSparse, func(b []byte) { b[4] = 2 })},
+		{"bad algorithm", modify(validSparse, func(b []byte) { b[5] = 0 })},
+		{"bad precision", modify(validSparse, func(b []byte) { b[6] = MaxPrecision + 1 })},
+		{"bad representation", modify(validSparse, func(b []byte) { b[7] = 2 })},
+		{"sparse without count", validSparse[:headerSize]},
+		{"sparse truncated", validSparse[:len(validSparse)-1]},
+		{"sparse unsorted", modify(validSparse, func(b []byte) {
+			copy(b[headerSize+4:], validSparse[headerSize+8:headerSize+12])
+			copy(b[headerSize+8:], validSparse[headerSize+4:headerSize+8])
+		})},
+		{"sparse bad entry", modify(validSparse, func(b []byte) { b[len(b)-1] = 0xff })},
+		{"dense truncated", validDense[:len(validDense)-1]},
+		{"dense bad register", modify(validDense, func(b []byte) { b[headerSize] = 62 })},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var s Sketch
+			if err := s.UnmarshalBinary(tc.data); err == nil {
+				t.Error("UnmarshalBinary() didn't return error")
+			}
+		})
+	}
+}
+
+func BenchmarkAdd(b *testing.B) {
+	s := newTestSketch(b, 14)
+	key := []byte("user.name@example.com")
+	for i := 0; i < b.N; i++ {
+		s.Add(key)
+	}
+}
+
+func BenchmarkEstimate(b *testing.B) {
+	s := newTestSketch(b, 14)
+	for i := 0; i < 100000; i++ {
+		s.AddString(strconv.Itoa(i))
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = s.Estimate()
+	}
+}
@@ -0,0 +1,616 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package circlehash
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// JSON encoding version 1 uses its own tags, so JSON digests don't match
+// HashValue digests by accident:
+//   - null: Hash64Uint64x2(tagNull, 0, seed)
+//   - true and false: Hash64Uint64x2(tagBool, 1 or 0, seed)
+//   - number: Hash64Uint64x2(tagNumber, Hash64(sign, significant digits, exponent), seed)
+//     where number is sign * digits * 10^exponent with no leading or trailing
+//     zeros in digits, and zero is "+0" with exponent 0
+//   - string: Hash64Uint64x2(tagString, Hash64(unescaped UTF-8 string, seed), seed)
+//   - array: Hash64(tagArray, element digests...)
+//   - object: CombineOrdered64(seed, tagObject, number of members, sum of
+//     Combine64(seed, key digest, value digest)) where sum is modulo 2^64
+//
+// All uint64 values are encoded in little-endian order.
+const (
+	jsonEncodingVersion = uint64(1)
+
+	jsonTagNull   = jsonEncodingVersion<<32 | 0x101
+	jsonTagBool   = jsonEncodingVersion<<32 | 0x102
+	jsonTagNumber = jsonEncodingVersion<<32 | 0x103
+	jsonTagString = jsonEncodingVersion<<32 | 0x104
+	jsonTagArray  = jsonEncodingVersion<<32 | 0x105
+	jsonTagObject = jsonEncodingVersion<<32 | 0x106
+)
+
+const (
+	// jsonMaxNestingDepth is the same as encoding/json.
+	jsonMaxNestingDepth = 10000
+
+	// jsonMaxExponentDigits limits significant digits of number exponent
+	// so exponent arithmetic doesn't overflow.
+	jsonMaxExponentDigits = 15
+)
+
+// JSONSyntaxError is returned by HashJSON when input isn't valid JSON.
+type JSONSyntaxError struct {
+	msg    string
+	Offset int64 // byte offset of invalid character, or input length for unexpected end of input
+}
+
+func (e *JSONSyntaxError) Error() string {
+	return fmt.Sprintf("circlehash: invalid JSON at offset %d: %s", e.Offset, e.msg)
+}
+
+// HashJSON returns a 64-bit digest of the JSON value read from r.
+// Semantically equal JSON values produce the same digest:
+//   - whitespace is ignored
+//   - object members are hashed independent of order, same as sorting keys
+//   - numbers are compared by exact decimal value, so 1, 1.0, 10e-1, and 0.1E1
+//     hash alike, and -0 and 0 hash alike
+//   - strings are compared after unescaping, so "\u0041" and "A" hash alike,
+//     and invalid UTF-8 is replaced by U+FFFD like encoding/json
+//
+// Objects with duplicate keys include all duplicate members in digest.
+//
+// HashJSON streams input into CircleHash64f states without building a tree.
+// It returns *JSONSyntaxError if input isn't valid JSON, or if input contains
+// anything other than whitespace after the JSON value.
+func HashJSON(r io.Reader, seed uint64) (uint64, error) {
+	h := jsonHasher{
+		r:    bufio.NewReader(r),
+		seed: seed,
+	}
+
+	v, err := h.value()
+	if err != nil {
+		return 0, err
+	}
+
+	if err := h.skipSpace(); err != nil {
+		return 0, err
+	}
+	if _, err := h.r.ReadByte(); err != io.EOF {
+		if err != nil {
+			return 0, err
+		}
+		return 0, h.syntaxError(h.offset, "invalid character after top-level value")
+	}
+
+	return v, nil
+}
+
+// jsonHasher hashes JSON values using JSON encoding version 1.
+type jsonHasher struct {
+	r      *bufio.Reader
+	seed   uint64
+	offset int64 // number of bytes read
+	depth  int
+}
+
+func (h *jsonHasher) syntaxError(offset int64, format string, a ...interface{}) error {
+	return &JSONSyntaxError{msg: fmt.Sprintf(format, a...), Offset: offset}
+}
+
+// readByte reads next byte and returns JSONSyntaxError at end of input.
+func (h *jsonHasher) readByte() (byte, error) {
+	c, err := h.r.ReadByte()
+	if err != nil {
+		if err == io.EOF {
+			return 0, h.syntaxError(h.offset, "unexpected end of JSON input")
+		}
+		return 0, err
+	}
+	h.offset++
+	return c, nil
+}
+
+// peekByte returns next byte without reading it.  It returns 0 at end of input.
+func (h *jsonHasher) peekByte() (byte, error) {
+	b, err := h.r.Peek(1)
+	if err != nil {
+		if err == io.EOF {
+			return 0, nil
+		}
+		return 0, err
+	}
+	return b[0], nil
+}
+
+func (h *jsonHasher) skipSpace() error {
+	for {
+		c, err := h.peekByte()
+		if err != nil {
+			return err
+		}
+		if c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+			return nil
+		}
+		_, _ = h.r.ReadByte()
+		h.offset++
+	}
+}
+
+func (h *jsonHasher) value() (uint64, error) {
+	if err := h.skipSpace(); err != nil {
+		return 0, err
+	}
+
+	c, err := h.readByte()
+	if err != nil {
+		return 0, err
+	}
+
+	switch c {
+	case '{':
+		return h.object()
+	case '[':
+		return h.array()
+	case '"':
+		return h.string()
+	case 't':
+		return circle64fUint64x2(jsonTagBool, 1, h.seed), h.literal("rue")
+	case 'f':
+		return circle64fUint64x2(jsonTagBool, 0, h.seed), h.literal("alse")
+	case 'n':
+		return circle64fUint64x2(jsonTagNull, 0, h.seed), h.literal("ull")
+	}
+
+	if c == '-' || isDigit(c) {
+		return h.number(c)
+	}
+	return 0, h.syntaxError(h.offset-1, "invalid character %q looking for beginning of value", c)
+}
+
+// literal reads rest of literal true, false, or null.
+func (h *jsonHasher) literal(rest string) error {
+	for i := 0; i < len(rest); i++ {
+		c, err := h.readByte()
+		if err != nil {
+			return err
+		}
+		if c != rest[i] {
+			return h.syntaxError(h.offset-1, "invalid character %q in literal", c)
+		}
+	}
+	return nil
+}
+
+func (h *jsonHasher) enter() error {
+	h.depth++
+	if h.depth > jsonMaxNestingDepth {
+		return h.syntaxError(h.offset-1, "exceeded max depth")
+	}
+	return nil
+}
+
+func (h *jsonHasher) object() (uint64, error) {
+	if err := h.enter(); err != nil {
+		return 0, err
+	}
+	defer func() { h.depth-- }()
+
+	var n, sum uint64
+
+	if err := h.skipSpace(); err != nil {
+		return 0, err
+	}
+	if c, err := h.peekByte(); err != nil {
+		return 0, err
+	} else if c == '}' {
+		h.offset++
+		_, _ = h.r.ReadByte()
+		return CombineOrdered64(h.seed, jsonTagObject, 0, 0), nil
+	}
+
+	for {
+		if err := h.skipSpace(); err != nil {
+			return 0, err
+		}
+		c, err := h.readByte()
+		if err != nil {
+			return 0, err
+		}
+		if c != '"' {
+			return 0, h.syntaxError(h.offset-1, "invalid character %q looking for beginning of object key string", c)
+		}
+		k, err := h.string()
+		if err != nil {
+			return 0, err
+		}
+
+		if err := h.skipSpace(); err != nil {
+			return 0, err
+		}
+		c, err = h.readByte()
+		if err != nil {
+			return 0, err
+		}
+		if c != ':' {
+			return 0, h.syntaxError(h.offset-1, "invalid character %q after object key", c)
+		}
+
+		v, err := h.value()
+		if err != nil {
+			return 0, err
+		}
+
+		n++
+		sum += Combine64(h.seed, k, v)
+
+		if err := h.skipSpace(); err != nil {
+			return 0, err
+		}
+		c, err = h.readByte()
+		if err != nil {
+			return 0, err
+		}
+		switch c {
+		case ',':
+			continue
+		case '}':
+			return CombineOrdered64(h.seed, jsonTagObject, n, sum), nil
+		}
+		return 0, h.syntaxError(h.offset-1, "invalid character %q after object key:value pair", c)
+	}
+}
+
+func (h *jsonHasher) array() (uint64, error) {
+	if err := h.enter(); err != nil {
+		return 0, err
+	}
+	defer func() { h.depth-- }()
+
+	d := Digest64{seed: h.seed}
+	d.Reset()
+	d.WriteUint64(jsonTagArray)
+
+	if err := h.skipSpace(); err != nil {
+		return 0, err
+	}
+	if c, err := h.peekByte(); err != nil {
+		return 0, err
+	} else if c == ']' {
+		h.offset++
+		_, _ = h.r.ReadByte()
+		return d.Sum64(), nil
+	}
+
+	for {
+		v, err := h.value()
+		if err != nil {
+			return 0, err
+		}
+		d.WriteUint64(v)
+
+		if err := h.skipSpace(); err != nil {
+			return 0, err
+		}
+		c, err := h.readByte()
+		if err != nil {
+			return 0, err
+		}
+		switch c {
+		case ',':
+			continue
+		case ']':
+			return d.Sum64(), nil
+		}
+		return 0, h.syntaxError(h.offset-1, "invalid character %q after array element", c)
+	}
+}
+
+// string reads rest of string after opening quote and returns its digest.
+func (h *jsonHasher) string() (uint64, error) {
+	d := Digest64{seed: h.seed}
+	d.Reset()
+
+	for {
+		r, size, err := h.r.ReadRune()
+		if err != nil {
+			if err == io.EOF {
+				return 0, h.syntaxError(h.offset, "unexpected end of JSON input")
+			}
+			return 0, err
+		}
+		h.offset += int64(size)
+
+		switch {
+		case r == '"':
+			return circle64fUint64x2(jsonTagString, d.Sum64(), h.seed), nil
+
+		case r == '\\':
+			if err := h.escape(&d); err != nil {
+				return 0, err
+			}
+
+		case r < 0x20:
+			return 0, h.syntaxError(h.offset-1, "invalid character %q in string literal", r)
+
+		default:
+			// Invalid UTF-8 is returned by ReadRune as RuneError and written as U+FFFD.
+			writeRune(&d, r)
+		}
+	}
+}
+
+// escape reads rest of escape sequence after backslash and writes unescaped rune to d.
+func (h *jsonHasher) escape(d *Digest64) error {
+	c, err := h.readByte()
+	if err != nil {
+		return err
+	}
+
+	switch c {
+	case '"', '\\', '/':
+		d.WriteByte(c)
+	case 'b':
+		d.WriteByte('\b')
+	case 'f':
+		d.WriteByte('\f')
+	case 'n':
+		d.WriteByte('\n')
+	case 'r':
+		d.WriteByte('\r')
+	case 't':
+		d.WriteByte('\t')
+	case 'u':
+		r, err := h.hex4()
+		if err != nil {
+			return err
+		}
+		return h.unicodeEscape(d, r)
+	default:
+		return h.syntaxError(h.offset-1, "invalid character %q in string escape code", c)
+	}
+	return nil
+}
+
+// unicodeEscape writes rune r from \uXXXX escape to d.  Surrogate pair in
+// two escapes is combined, and lone surrogate is replaced by U+FFFD like
+// encoding/json.
+func (h *jsonHasher) unicodeEscape(d *Digest64, r rune) error {
+	for utf16.IsSurrogate(r) {
+		next, err := h.r.Peek(2)
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if len(next) < 2 || next[0] != '\\' || next[1] != 'u' {
+			break
+		}
+		_, _ = h.r.Discard(2)
+		h.offset += 2
+
+		r2, err := h.hex4()
+		if err != nil {
+			return err
+		}
+		if dec := utf16.DecodeRune(r, r2); dec != utf8.RuneError {
+			writeRune(d, dec)
+			return nil
+		}
+
+		// Replace lone surrogate r and process r2 as next escaped rune.
+		writeRune(d, utf8.RuneError)
+		r = r2
+	}
+
+	if utf16.IsSurrogate(r) {
+		r = utf8.RuneError
+	}
+	writeRune(d, r)
+	return nil
+}
+
+func writeRune(d *Digest64, r rune) {
+	var buf [utf8.UTFMax]byte
+	n := utf8.EncodeRune(buf[:], r)
+	d.Write(buf[:n])
+}
+
+func (h *jsonHasher) hex4() (rune, error) {
+	var r rune
+	for i := 0; i < 4; i++ {
+		c, err := h.readByte()
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case '0' <= c && c <= '9':
+			c -= '0'
+		case 'a' <= c && c <= 'f':
+			c = c - 'a' + 10
+		case 'A' <= c && c <= 'F':
+			c = c - 'A' + 10
+		default:
+			return 0, h.syntaxError(h.offset-1, "invalid character %q in \\u hexadecimal character escape", c)
+		}
+		r = r<<4 | rune(c)
+	}
+	return r, nil
+}
+
+// number reads number starting with c and returns its digest.
+func (h *jsonHasher) number(c byte) (uint64, error) {
+	d := Digest64{seed: h.seed}
+	d.Reset()
+
+	var err error
+
+	if c == '-' {
+		d.WriteByte('-')
+		if c, err = h.readByte(); err != nil {
+			return 0, err
+		}
+		if !isDigit(c) {
+			return 0, h.syntaxError(h.offset-1, "invalid character %q in numeric literal", c)
+		}
+	} else {
+		d.WriteByte('+')
+	}
+
+	significant := false       // true if nonzero digit is written
+	pendingZeros := int64(0)   // zeros after last nonzero digit
+	fractionDigits := int64(0) // number of digits after decimal point
+
+	addDigit := func(c byte) {
+		if c == '0' {
+			if significant {
+				pendingZeros++
+			}
+			return
+		}
+		for ; pendingZeros > 0; pendingZeros-- {
+			d.WriteByte('0')
+		}
+		d.WriteByte(c)
+		significant = true
+	}
+
+	// Integer part.  JSON doesn't allow leading zeros.
+	addDigit(c)
+	if c != '0' {
+		if err := h.digits(addDigit, nil); err != nil {
+			return 0, err
+		}
+	}
+
+	// Fraction part.
+	if next, err := h.peekByte(); err != nil {
+		return 0, err
+	} else if next == '.' {
+		_, _ = h.readByte()
+		if err := h.digits(addDigit, &fractionDigits); err != nil {
+			return 0, err
+		}
+		if fractionDigits == 0 {
+			return 0, h.numberError()
+		}
+	}
+
+	// Exponent part.
+	exponent, err := h.exponent()
+	if err != nil {
+		return 0, err
+	}
+
+	if !significant {
+		return jsonZeroDigest(h.seed), nil
+	}
+
+	d.WriteUint64(uint64(exponent - fractionDigits + pendingZeros))
+	return circle64fUint64x2(jsonTagNumber, d.Sum64(), h.seed), nil
+}
+
+// digits reads decimal digits and calls fn for each digit.
+// If count isn't nil, it's incremented for each digit.
+func (h *jsonHasher) digits(fn func(byte), count *int64) error {
+	for {
+		c, err := h.peekByte()
+		if err != nil {
+			return err
+		}
+		if !isDigit(c) {
+			return nil
+		}
+		_, _ = h.readByte()
+		fn(c)
+		if count != nil {
+			*count++
+		}
+	}
+}
+
+// exponent reads optional exponent part of number.
+func (h *jsonHasher) exponent() (int64, error) {
+	c, err := h.peekByte()
+	if err != nil {
+		return 0, err
+	}
+	if c != 'e' && c != 'E' {
+		return 0, nil
+	}
+	_, _ = h.readByte()
+
+	if c, err = h.peekByte(); err != nil {
+		return 0, err
+	}
+	negative := c == '-'
+	if c == '-' || c == '+' {
+		_, _ = h.readByte()
+	}
+
+	exponent := int64(0)
+	numDigits := 0
+	significantDigits := 0
+	err = h.digits(func(c byte) {
+		numDigits++
+		if exponent > 0 || c != '0' {
+			significantDigits++
+		}
+		exponent = exponent*10 + int64(c-'0')
+	}, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	if numDigits == 0 {
+		return 0, h.numberError()
+	}
+	if significantDigits > jsonMaxExponentDigits {
+		return 0, h.syntaxError(h.offset-1, "number exponent out of range")
+	}
+	if negative {
+		exponent = -exponent
+	}
+	return exponent, nil
+}
+
+// numberError returns syntax error for invalid character (or end of input) in number.
+func (h *jsonHasher) numberError() error {
+	c, err := h.peekByte()
+	if err != nil {
+		return err
+	}
+	if c == 0 {
+		return h.syntaxError(h.offset, "unexpected end of JSON input")
+	}
+	return h.syntaxError(h.offset, "invalid character %q in numeric literal", c)
+}
+
+// jsonZeroDigest returns digest of JSON number zero.
+func jsonZeroDigest(seed uint64) uint64 {
+	d := Digest64{seed: seed}
+	d.Reset()
+	d.WriteByte('+')
+	d.WriteByte('0')
+	d.WriteUint64(0)
+	return circle64fUint64x2(jsonTagNumber, d.Sum64(), seed)
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
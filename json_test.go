@@ -0,0 +1,266 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package circlehash
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func hashJSONString(t *testing.T, s string) uint64 {
+	h, err := HashJSON(strings.NewReader(s), numsGoldenRatio)
+	if err != nil {
+		t.Fatalf("HashJSON(%q) returned error %v", s, err)
+	}
+	return h
+}
+
+func TestHashJSONEqual(t *testing.T) {
+	testCases := []struct {
+		name string
+		docs []string
+	}{
+		{"whitespace", []string{`{"a":[1,2,{"b":null}]}`, " {\n\t\"a\" : [ 1 , 2 , { \"b\" : null } ] }\r\n"}},
+		{"key order", []string{`{"a":1,"b":2,"c":3}`, `{"c":3,"a":1,"b":2}`, `{"b":2,"c":3,"a":1}`}},
+		{"nested key order", []string{`{"x":{"a":true,"b":false},"y":[{"c":1,"d":2}]}`, `{"y":[{"d":2,"c":1}],"x":{"b":false,"a":true}}`}},
+		{"integer forms", []string{`1`, `1.0`, `1.000`, `10e-1`, `0.1E1`, `0.01e+2`, `100E-2`}},
+		{"large integer forms", []string{`1000`, `1e3`, `1E+3`, `10.00e2`, `1000.0`}},
+		{"fraction forms", []string{`0.5`, `5e-1`, `50E-2`, `0.50`, `0.0005e3`}},
+		{"negative forms", []string{`-12.5`, `-125e-1`, `-0.125e2`}},
+		{"zero forms", []string{`0`, `-0`, `0.0`, `-0.0e10`, `0e-5`, `0.000`}},
+		{"big numbers", []string{`123456789012345678901234567890`, `1234567890123456789012345678.90e2`}},
+		{"escaped ASCII", []string{`"A/b"`, `"A\/b"`, `"\u0041/b"`}},
+		{"escaped control", []string{`"\n\t\r\b\f"`, `"\u000a\u0009\u000D\u0008\u000C"`}},
+		{"escaped unicode", []string{`"é😀"`, `"\u00e9\ud83d\ude00"`, `"\u00E9😀"`}},
+		{"lone surrogate", []string{`"\ud800"`, `"\ufffd"`, `"�"`}},
+		{"lone surrogate before escape", []string{`"\ud800\u0041"`, `"�A"`}},
+		{"two high surrogates before pair", []string{`"\ud800\ud83d\ude00"`, `"�😀"`}},
+		{"invalid UTF-8", []string{"\"\xff\"", `"�"`}},
+		{"escaped keys", []string{`{"key":1}`, `{"\u006bey":1}`}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			want := hashJSONString(t, tc.docs[0])
+			for _, doc := range tc.docs[1:] {
+				if got := hashJSONString(t, doc); got != want {
+					t.Errorf("HashJSON(%q) = 0x%016x; HashJSON(%q) = 0x%016x", doc, got, tc.docs[0], want)
+				}
+			}
+		})
+	}
+}
+
+func TestHashJSONDistinct(t *testing.T) {
+	docs := []string{
+		`null`,
+		`true`,
+		`false`,
+		`0`,
+		`1`,
+		`-1`,
+		`10`,
+		`0.1`,
+		`1.1`,
+		`11`,
+		`101`,
+		`1001`,
+		`1e100`,
+		`1e-100`,
+		`""`,
+		`"0"`,
+		`"1"`,
+		`"null"`,
+		`[]`,
+		`{}`,
+		`[null]`,
+		`[[]]`,
+		`[1,2]`,
+		`[2,1]`,
+		`[1,[2]]`,
+		`[[1],2]`,
+		`{"a":1}`,
+		`{"a":"1"}`,
+		`{"1":"a"}`,
+		`{"a":1,"b":2}`,
+		`{"a":2,"b":1}`,
+		`{"a":{"b":1}}`,
+		`{"a":1,"a":1}`,
+		`[{"a":1}]`,
+		`["a",1]`,
+	}
+
+	seen := make(map[uint64]string)
+	for _, doc := range docs {
+		h := hashJSONString(t, doc)
+		if prev, ok := seen[h]; ok {
+			t.Errorf("HashJSON(%q) == HashJSON(%q) = 0x%016x", doc, prev, h)
+		}
+		seen[h] = doc
+	}
+}
+
+func TestHashJSONMarshaledStrings(t *testing.T) {
+	// Strings escaped by encoding/json hash the same as unescaped strings.
+	strs := []string{
+		"",
+		"plain",
+		"quote \" backslash \\ slash /",
+		"<html> & entities",
+		"control \x00\x01\x1f",
+		"unicode é 日本語 😀",
+		"line separators \u2028 \u2029",
+		strings.Repeat("long string ", 20),
+	}
+
+	for _, s := range strs {
+		b, err := json.Marshal(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := hashJSONString(t, string(b))
+		want := circle64fUint64x2(jsonTagString, Hash64String(s, numsGoldenRatio), numsGoldenRatio)
+		if got != want {
+			t.Errorf("HashJSON(%s) = 0x%016x; want 0x%016x", b, got, want)
+		}
+	}
+}
+
+func TestHashJSONSyntaxErrors(t *testing.T) {
+	testCases := []struct {
+		doc        string
+		wantOffset int64
+	}{
+		{``, 0},
+		{`   `, 3},
+		{`x`, 0},
+		{`nul`, 3},
+		{`nulL`, 3},
+		{`tru e`, 3},
+		{`[1,]`, 3},
+		{`[1 2]`, 3},
+		{`[1,2`, 4},
+		{`{"a" 1}`, 5},
+		{`{"a":1,}`, 7},
+		{`{"a":1 "b":2}`, 7},
+		{`{a:1}`, 1},
+		{`{"a":}`, 5},
+		{`"abc`, 4},
+		{"\"a\nb\"", 2},
+		{`"\x"`, 2},
+		{`"\u12G4"`, 5},
+		{`-`, 1},
+		{`-a`, 1},
+		{`1.`, 2},
+		{`1.e5`, 2},
+		{`1e`, 2},
+		{`1e+`, 3},
+		{`1ex`, 2},
+		{`01`, 1},
+		{`1 2`, 2},
+		{`{} {}`, 3},
+		{`+1`, 0},
+		{`.5`, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.doc, func(t *testing.T) {
+			_, err := HashJSON(strings.NewReader(tc.doc), numsGoldenRatio)
+			if err == nil {
+				t.Fatalf("HashJSON(%q) didn't return error", tc.doc)
+			}
+
+			var syntaxErr *JSONSyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("HashJSON(%q) returned error %v (%T); want *JSONSyntaxError", tc.doc, err, err)
+			}
+			if syntaxErr.Offset != tc.wantOffset {
+				t.Errorf("HashJSON(%q) returned error %q with offset %d; want offset %d", tc.doc, err, syntaxErr.Offset, tc.wantOffset)
+			}
+
+			// Verify input is rejected by encoding/json too.
+			if json.Valid([]byte(tc.doc)) {
+				t.Errorf("json.Valid(%q) = true", tc.doc)
+			}
+		})
+	}
+}
+
+func TestHashJSONExponentOutOfRange(t *testing.T) {
+	// Exponent with up to 15 significant digits is supported.
+	hashJSONString(t, `1e000000000000000000999999999999999`)
+
+	_, err := HashJSON(strings.NewReader(`1e1234567890123456`), numsGoldenRatio)
+
+	var syntaxErr *JSONSyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("HashJSON() with exponent out of range returned error %v; want *JSONSyntaxError", err)
+	}
+	if syntaxErr.Offset != 17 {
+		t.Errorf("HashJSON() with exponent out of range returned offset %d; want 17", syntaxErr.Offset)
+	}
+}
+
+func TestHashJSONMaxDepth(t *testing.T) {
+	doc := strings.Repeat("[", jsonMaxNestingDepth) + strings.Repeat("]", jsonMaxNestingDepth)
+	if _, err := HashJSON(strings.NewReader(doc), numsGoldenRatio); err != nil {
+		t.Errorf("HashJSON() with max depth returned error %v", err)
+	}
+
+	doc = "[" + doc + "]"
+	_, err := HashJSON(strings.NewReader(doc), numsGoldenRatio)
+
+	var syntaxErr *JSONSyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Fatalf("HashJSON() exceeding max depth returned error %v; want *JSONSyntaxError", err)
+	}
+	if syntaxErr.Offset != jsonMaxNestingDepth {
+		t.Errorf("HashJSON() exceeding max depth returned offset %d; want %d", syntaxErr.Offset, jsonMaxNestingDepth)
+	}
+}
+
+func TestHashJSONReaderError(t *testing.T) {
+	errRead := errors.New("read error")
+
+	_, err := HashJSON(iotest.ErrReader(errRead), numsGoldenRatio)
+	if !errors.Is(err, errRead) {
+		t.Errorf("HashJSON() returned error %v; want %v", err, errRead)
+	}
+}
+
+func TestHashJSONOneByteReader(t *testing.T) {
+	doc := `{"b": [1.50, "xA", null], "a": {"c": true}}`
+	want := hashJSONString(t, doc)
+
+	got, err := HashJSON(iotest.OneByteReader(strings.NewReader(doc)), numsGoldenRatio)
+	if err != nil {
+		t.Fatalf("HashJSON() returned error %v", err)
+	}
+	if got != want {
+		t.Errorf("HashJSON() with one byte reader = 0x%016x; want 0x%016x", got, want)
+	}
+}
+
+func BenchmarkHashJSON(b *testing.B) {
+	doc := `{"id": 12345, "name": "example", "tags": ["a", "b", "c"], "nested": {"x": 1.5, "y": -2e10, "z": null}, "ok": true}`
+	b.SetBytes(int64(len(doc)))
+	for i := 0; i < b.N; i++ {
+		_, _ = HashJSON(strings.NewReader(doc), numsGoldenRatio)
+	}
+}
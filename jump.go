@@ -0,0 +1,46 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package circlehash
+
+// JumpHash returns bucket in range [0, buckets) for key using jump
+// consistent hash by John Lamping and Eric Veach.  When buckets increases
+// from n to n+1, only about 1/(n+1) of keys move, all to the new bucket.
+// If buckets is less than 1, JumpHash returns -1.
+//
+// Key should be a digest, such as Hash64 of the original key, because
+// keys with few distinct bits aren't distributed uniformly.
+func JumpHash(key uint64, buckets int) int {
+	if buckets < 1 {
+		return -1
+	}
+
+	b, j := int64(-1), int64(0)
+	for j < int64(buckets) {
+		b = j
+		key = key*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((key>>33)+1)))
+	}
+	return int(b)
+}
+
+// JumpHashBytes returns JumpHash(Hash64(b, seed), buckets).
+func JumpHashBytes(b []byte, buckets int, seed uint64) int {
+	return JumpHash(Hash64(b, seed), buckets)
+}
+
+// JumpHashString returns JumpHash(Hash64String(s, seed), buckets).
+func JumpHashString(s string, buckets int, seed uint64) int {
+	return JumpHash(Hash64String(s, seed), buckets)
+}
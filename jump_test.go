@@ -0,0 +1,107 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package circlehash
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+// checkUniform checks that counts are within 5 standard deviations of
+// their expected values.
+func checkUniform(t *testing.T, name string, counts []int, expected []float64) {
+	t.Helper()
+	for i, c := range counts {
+		if math.Abs(float64(c)-expected[i]) > 5*math.Sqrt(expected[i]) {
+			t.Errorf("%s: bucket %d has %d keys; want about %.0f", name, i, c, expected[i])
+		}
+	}
+}
+
+func TestJumpHashInvalidBuckets(t *testing.T) {
+	for _, buckets := range []int{-1, 0} {
+		if got := JumpHash(numsGoldenRatio, buckets); got != -1 {
+			t.Errorf("JumpHash(0x%x, %d) = %d; want -1", numsGoldenRatio, buckets, got)
+		}
+	}
+	if got := JumpHash(numsGoldenRatio, 1); got != 0 {
+		t.Errorf("JumpHash(0x%x, 1) = %d; want 0", numsGoldenRatio, got)
+	}
+}
+
+func TestJumpHashUniform(t *testing.T) {
+	const numKeys = 100000
+
+	for _, buckets := range []int{2, 7, 10, 100} {
+		counts := make([]int, buckets)
+		for i := 0; i < numKeys; i++ {
+			b := JumpHashString("key"+strconv.Itoa(i), buckets, numsGoldenRatio)
+			if b < 0 || b >= buckets {
+				t.Fatalf("JumpHashString() = %d; want in range [0, %d)", b, buckets)
+			}
+			counts[b]++
+		}
+
+		expected := make([]float64, buckets)
+		for i := range expected {
+			expected[i] = float64(numKeys) / float64(buckets)
+		}
+		checkUniform(t, "buckets="+strconv.Itoa(buckets), counts, expected)
+	}
+}
+
+func TestJumpHashMinimalMovement(t *testing.T) {
+	const numKeys = 100000
+
+	for _, buckets := range []int{1, 5, 10, 50} {
+		moved := 0
+		for i := 0; i < numKeys; i++ {
+			key := Hash64String(strconv.Itoa(i), numsGoldenRatio)
+			before := JumpHash(key, buckets)
+			after := JumpHash(key, buckets+1)
+			if before != after {
+				if after != buckets {
+					t.Fatalf("key moved from bucket %d to %d when adding bucket %d", before, after, buckets)
+				}
+				moved++
+			}
+		}
+
+		want := float64(numKeys) / float64(buckets+1)
+		if math.Abs(float64(moved)-want) > 5*math.Sqrt(want) {
+			t.Errorf("%d of %d keys moved when adding bucket %d; want about %.0f", moved, numKeys, buckets, want)
+		}
+	}
+}
+
+func TestJumpHashBytesAndString(t *testing.T) {
+	for i := 0; i < 1000; i++ {
+		s := strconv.Itoa(i)
+		want := JumpHash(Hash64String(s, numsGoldenRatio), 17)
+		if got := JumpHashBytes([]byte(s), 17, numsGoldenRatio); got != want {
+			t.Errorf("JumpHashBytes(%q) = %d; want %d", s, got, want)
+		}
+		if got := JumpHashString(s, 17, numsGoldenRatio); got != want {
+			t.Errorf("JumpHashString(%q) = %d; want %d", s, got, want)
+		}
+	}
+}
+
+func BenchmarkJumpHash(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = JumpHash(uint64(i), 1000)
+	}
+}
@@ -0,0 +1,429 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package kmv implements k-minimum-values (bottom-k) sketches using CircleHash64f.
+//
+// Sketch keeps k keys with the smallest circlehash.Hash64 digests.
+// Retained keys are a deterministic uniform sample of distinct keys,
+// and digests estimate cardinality of sets and of their unions,
+// intersections, and differences.
+// See "On Synopses for Distinct-Value Estimation Under Multiset Operations"
+// by Kevin Beyer, Peter J. Haas, Berthold Reinwald, Yannis Sismanis,
+// and Rainer Gemulla.
+//
+// Sketches can be combined only if they use the same seed.
+package kmv
+
+import (
+	"container/heap"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/fxamacker/circlehash"
+)
+
+const (
+	formatVersion = 1
+
+	// algorithmCircleHash64f identifies circlehash.Hash64 as hash function.
+	algorithmCircleHash64f = 1
+
+	// headerSize is the size of serialized sketch header:
+	// magic (4), version (1), algorithm (1), reserved (2), k (4), number of entries (4), seed (8).
+	headerSize = 24
+
+	// maxK limits k of serialized sketches.
+	maxK = 1 << 24
+)
+
+var magic = [4]byte{'C', 'H', 'K', 'M'}
+
+var (
+	// ErrSeedMismatch is returned when combining sketches with different seeds.
+	ErrSeedMismatch = errors.New("kmv: sketches have different seeds")
+
+	// ErrInvalidData is returned by UnmarshalBinary when data isn't a valid serialized sketch.
+	ErrInvalidData = errors.New("kmv: invalid serialized sketch")
+)
+
+type entry struct {
+	hash uint64
+	key  string
+}
+
+// Sketch is a k-minimum-values sketch.  It is not safe for concurrent use.
+type Sketch struct {
+	k       int
+	seed    uint64
+	entries maxHeap             // k entries with the smallest hashes
+	hashes  map[uint64]struct{} // hashes of entries
+}
+
+// New returns an empty Sketch that keeps k keys.  Relative standard error
+// of cardinality estimate is about 1/sqrt(k-2).
+func New(k int, seed uint64) (*Sketch, error) {
+	if k < 2 || k > maxK {
+		return nil, fmt.Errorf("kmv: k %d is not in range [2, %d]", k, maxK)
+	}
+	return &Sketch{
+		k:      k,
+		seed:   seed,
+		hashes: make(map[uint64]struct{}),
+	}, nil
+}
+
+// K returns maximum number of keys kept by s.
+func (s *Sketch) K() int {
+	return s.k
+}
+
+// Seed returns the seed used by s.
+func (s *Sketch) Seed() uint64 {
+	return s.seed
+}
+
+// Len returns number of keys kept by s.
+func (s *Sketch) Len() int {
+	return len(s.entries)
+}
+
+// Add adds b to s.
+func (s *Sketch) Add(b []byte) {
+	h := circlehash.Hash64(b, s.seed)
+	if s.admits(h) {
+		s.insert(h, string(b))
+	}
+}
+
+// AddString adds str to s.
+func (s *Sketch) AddString(str string) {
+	h := circlehash.Hash64String(str, s.seed)
+	if s.admits(h) {
+		s.insert(h, str)
+	}
+}
+
+func (s *Sketch) saturated() bool {
+	return len(s.entries) == s.k
+}
+
+func (s *Sketch) admits(h uint64) bool {
+	if s.saturated() && h >= s.entries[0].hash {
+		return false
+	}
+	_, ok := s.hashes[h]
+	return !ok
+}
+
+func (s *Sketch) insert(h uint64, key string) {
+	if s.saturated() {
+		delete(s.hashes, s.entries[0].hash)
+		s.entries[0] = entry{hash: h, key: key}
+		heap.Fix(&s.entries, 0)
+	} else {
+		heap.Push(&s.entries, entry{hash: h, key: key})
+	}
+	s.hashes[h] = struct{}{}
+}
+
+// sorted returns entries sorted by hash.
+func (s *Sketch) sorted() []entry {
+	entries := make([]entry, len(s.entries))
+	copy(entries, s.entries)
+	sort.Slice(entries, func(i, j int) bool { return entries[i].hash < entries[j].hash })
+	return entries
+}
+
+// Sample returns kept keys sorted by digest.  Keys are a uniform random
+// sample of distinct keys added to s, and sketches with the same seed
+// sample the same keys.
+func (s *Sketch) Sample() []string {
+	keys := make([]string, len(s.entries))
+	for i, e := range s.sorted() {
+		keys[i] = e.key
+	}
+	return keys
+}
+
+// Estimate returns estimated number of distinct keys added to s.
+func (s *Sketch) Estimate() float64 {
+	if !s.saturated() {
+		return float64(len(s.entries))
+	}
+	return estimate(s.k, s.entries[0].hash)
+}
+
+// estimate returns cardinality estimate from n smallest hashes with
+// the largest being maxHash.
+func estimate(n int, maxHash uint64) float64 {
+	u := (float64(maxHash) + 1) / (1 << 64)
+	return float64(n-1) / u
+}
+
+// Merge merges o into s, so s is sketch of union of both sets.
+// If o has smaller k, s keeps o.K() keys.  It returns ErrSeedMismatch
+// if o has different seed.
+func (s *Sketch) Merge(o *Sketch) error {
+	if s.seed != o.seed {
+		return ErrSeedMismatch
+	}
+	if o.k < s.k {
+		entries := s.sorted()
+		s.k = o.k
+		if len(entries) > s.k {
+			entries = entries[:s.k]
+		}
+		s.entries = s.entries[:0]
+		s.hashes = make(map[uint64]struct{}, len(entries))
+		for _, e := range entries {
+			s.insert(e.hash, e.key)
+		}
+	}
+	for _, e := range o.entries {
+		if s.admits(e.hash) {
+			s.insert(e.hash, e.key)
+		}
+	}
+	return nil
+}
+
+// Union returns sketch of union of sets of a and b.
+func Union(a, b *Sketch) (*Sketch, error) {
+	if a.seed != b.seed {
+		return nil, ErrSeedMismatch
+	}
+	u, _ := New(a.k, a.seed)
+	_ = u.Merge(a)
+	_ = u.Merge(b)
+	return u, nil
+}
+
+// setStats contains union estimate and membership counts of k smallest
+// hashes of union of two sketches.
+type setStats struct {
+	union     float64
+	n         int // number of union hashes considered
+	both      int
+	onlyA     int
+	onlyB     int
+	estimated bool
+}
+
+// stats returns set statistics of a and b.  Only hashes smaller than
+// the largest hash of each saturated sketch are considered, so membership
+// in both sets is known for each considered hash.
+func stats(a, b *Sketch) (setStats, error) {
+	if a.seed != b.seed {
+		return setStats{}, ErrSeedMismatch
+	}
+
+	k := a.k
+	if b.k < k {
+		k = b.k
+	}
+	limit := uint64(math.MaxUint64)
+	estimated := false
+	for _, s := range []*Sketch{a, b} {
+		if s.saturated() {
+			estimated = true
+			if s.entries[0].hash < limit {
+				limit = s.entries[0].hash
+			}
+		}
+	}
+
+	var hashes []uint64
+	for _, s := range []*Sketch{a, b} {
+		for h := range s.hashes {
+			if h <= limit {
+				hashes = append(hashes, h)
+			}
+		}
+	}
+	sort.Slice(hashes, func(i, j int) bool { return hashes[i] < hashes[j] })
+
+	var st setStats
+	for i, h := range hashes {
+		if i > 0 && h == hashes[i-1] {
+			continue
+		}
+		if estimated && st.n == k {
+			// Without saturated sketches, all hashes of both sets are
+			// considered, so counts are exact even beyond k.
+			break
+		}
+		st.n++
+		_, inA := a.hashes[h]
+		_, inB := b.hashes[h]
+		switch {
+		case inA && inB:
+			st.both++
+		case inA:
+			st.onlyA++
+		default:
+			st.onlyB++
+		}
+		limit = h
+	}
+
+	st.estimated = estimated
+	switch {
+	case !estimated:
+		st.union = float64(st.n)
+	case st.n > 1:
+		st.union = estimate(st.n, limit)
+	}
+	return st, nil
+}
+
+func (st setStats) fraction(n int) float64 {
+	if !st.estimated {
+		return float64(n)
+	}
+	if st.n == 0 {
+		return 0
+	}
+	return st.union * float64(n) / float64(st.n)
+}
+
+// EstimateUnion returns estimated number of distinct keys in union of sets of a and b.
+func EstimateUnion(a, b *Sketch) (float64, error) {
+	st, err := stats(a, b)
+	return st.union, err
+}
+
+// EstimateIntersection returns estimated number of distinct keys in both sets of a and b.
+func EstimateIntersection(a, b *Sketch) (float64, error) {
+	st, err := stats(a, b)
+	return st.fraction(st.both), err
+}
+
+// EstimateDifference returns estimated number of distinct keys in set of a
+// but not in set of b.
+func EstimateDifference(a, b *Sketch) (float64, error) {
+	st, err := stats(a, b)
+	return st.fraction(st.onlyA), err
+}
+
+// EstimateJaccard returns estimated Jaccard similarity of sets of a and b.
+func EstimateJaccard(a, b *Sketch) (float64, error) {
+	st, err := stats(a, b)
+	if err != nil || st.n == 0 {
+		return 0, err
+	}
+	return float64(st.both) / float64(st.n), nil
+}
+
+// MarshalBinary returns serialized s.  Serialized sketch records format
+// version, hash algorithm, k, seed, and kept keys.
+func (s *Sketch) MarshalBinary() ([]byte, error) {
+	entries := s.sorted()
+
+	size := headerSize
+	for _, e := range entries {
+		size += 4 + len(e.key)
+	}
+
+	data := make([]byte, headerSize, size)
+	copy(data, magic[:])
+	data[4] = formatVersion
+	data[5] = algorithmCircleHash64f
+	binary.LittleEndian.PutUint32(data[8:], uint32(s.k))
+	binary.LittleEndian.PutUint32(data[12:], uint32(len(entries)))
+	binary.LittleEndian.PutUint64(data[16:], s.seed)
+
+	var buf [4]byte
+	for _, e := range entries {
+		binary.LittleEndian.PutUint32(buf[:], uint32(len(e.key)))
+		data = append(data, buf[:]...)
+		data = append(data, e.key...)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary sets s to sketch serialized by MarshalBinary.
+// Keys are rehashed, so data serialized with a different hash algorithm
+// or seed is rejected.
+func (s *Sketch) UnmarshalBinary(data []byte) error {
+	if len(data) < headerSize || [4]byte{data[0], data[1], data[2], data[3]} != magic {
+		return ErrInvalidData
+	}
+	if data[4] != formatVersion {
+		return fmt.Errorf("kmv: unsupported format version %d", data[4])
+	}
+	if data[5] != algorithmCircleHash64f {
+		return fmt.Errorf("kmv: unsupported algorithm %d", data[5])
+	}
+
+	k := binary.LittleEndian.Uint32(data[8:])
+	n := binary.LittleEndian.Uint32(data[12:])
+	if k > maxK || n > k {
+		return ErrInvalidData
+	}
+
+	t, err := New(int(k), binary.LittleEndian.Uint64(data[16:]))
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrInvalidData, err)
+	}
+
+	data = data[headerSize:]
+	prev := uint64(0)
+	for i := uint32(0); i < n; i++ {
+		if len(data) < 4 {
+			return ErrInvalidData
+		}
+		keyLen := binary.LittleEndian.Uint32(data)
+		data = data[4:]
+		if uint64(len(data)) < uint64(keyLen) {
+			return ErrInvalidData
+		}
+		key := string(data[:keyLen])
+		data = data[keyLen:]
+
+		// Keys are sorted by distinct hashes.
+		h := circlehash.Hash64String(key, t.seed)
+		if i > 0 && h <= prev {
+			return ErrInvalidData
+		}
+		prev = h
+		t.insert(h, key)
+	}
+	if len(data) != 0 {
+		return ErrInvalidData
+	}
+
+	*s = *t
+	return nil
+}
+
+// maxHeap is a max-heap of entries by hash.
+type maxHeap []entry
+
+func (h maxHeap) Len() int           { return len(h) }
+func (h maxHeap) Less(i, j int) bool { return h[i].hash > h[j].hash }
+func (h maxHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *maxHeap) Push(x interface{}) {
+	*h = append(*h, x.(entry))
+}
+
+func (h *maxHeap) Pop() interface{} {
+	old := *h
+	e := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return e
+}
@@ -0,0 +1,409 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kmv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/fxamacker/circlehash"
+)
+
+const testSeed = uint64(0x9E3779B97F4A7C15)
+
+func newTestSketch(t testing.TB, k int) *Sketch {
+	s, err := New(k, testSeed)
+	if err != nil {
+		t.Fatalf("New(%d) returned error %v", k, err)
+	}
+	return s
+}
+
+// addRange adds keys [start, end) to s.
+func addRange(s *Sketch, start, end int) {
+	for i := start; i < end; i++ {
+		s.AddString(strconv.Itoa(i))
+	}
+}
+
+func checkClose(t *testing.T, name string, got, want float64, k int) {
+	t.Helper()
+	// Allow 5 standard errors relative to union size.
+	if tolerance := 5 / math.Sqrt(float64(k-2)); math.Abs(got-want) > tolerance*math.Max(want, 1)+1 {
+		t.Errorf("%s = %v; want %v", name, got, want)
+	}
+}
+
+func TestNewInvalid(t *testing.T) {
+	for _, k := range []int{-1, 0, 1, maxK + 1} {
+		if _, err := New(k, testSeed); err == nil {
+			t.Errorf("New(%d) didn't return error", k)
+		}
+	}
+}
+
+func TestEstimate(t *testing.T) {
+	const k = 1024
+
+	for _, n := range []int{0, 1, 100, k, 10000, 1000000} {
+		s := newTestSketch(t, k)
+		addRange(s, 0, n)
+		addRange(s, 0, n/2) // duplicates
+
+		got := s.Estimate()
+		if n < k {
+			if got != float64(n) {
+				t.Errorf("n=%d: Estimate() = %v; want exact %d", n, got, n)
+			}
+			continue
+		}
+		checkClose(t, fmt.Sprintf("n=%d: Estimate()", n), got, float64(n), k)
+	}
+}
+
+func TestSample(t *testing.T) {
+	s := newTestSketch(t, 10)
+	addRange(s, 0, 1000)
+	s.Add([]byte("5"))
+
+	// Sample is 10 keys with the smallest digests.
+	type keyHash struct {
+		key  string
+		hash uint64
+	}
+	var all []keyHash
+	for i := 0; i < 1000; i++ {
+		all = append(all, keyHash{strconv.Itoa(i), circlehash.Hash64String(strconv.Itoa(i), testSeed)})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].hash < all[j].hash })
+	var want []string
+	for _, kh := range all[:10] {
+		want = append(want, kh.key)
+	}
+
+	if got := s.Sample(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Sample() = %q; want %q", got, want)
+	}
+	if s.Len() != 10 {
+		t.Errorf("Len() = %d; want 10", s.Len())
+	}
+}
+
+func TestSetOperations(t *testing.T) {
+	const k = 4096
+
+	testCases := []struct {
+		name         string
+		aStart, aEnd int
+		bStart, bEnd int
+	}{
+		{"exact", 0, 1000, 500, 1500},
+		{"one saturated", 0, 100000, 99000, 100100},
+		{"half overlap", 0, 100000, 50000, 150000},
+		{"subset", 0, 200000, 50000, 100000},
+		{"disjoint", 0, 100000, 100000, 150000},
+		{"identical", 0, 100000, 0, 100000},
+		{"small overlap", 0, 100000, 95000, 195000},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := newTestSketch(t, k)
+			b := newTestSketch(t, k)
+			addRange(a, tc.aStart, tc.aEnd)
+			addRange(b, tc.bStart, tc.bEnd)
+
+			lo := tc.bStart
+			if lo < tc.aStart {
+				lo = tc.aStart
+			}
+			hi := tc.bEnd
+			if hi > tc.aEnd {
+				hi = tc.aEnd
+			}
+			inter := 0
+			if hi > lo {
+				inter = hi - lo
+			}
+			union := (tc.aEnd - tc.aStart) + (tc.bEnd - tc.bStart) - inter
+			diff := (tc.aEnd - tc.aStart) - inter
+
+			gotUnion, err := EstimateUnion(a, b)
+			if err != nil {
+				t.Fatalf("EstimateUnion() returned error %v", err)
+			}
+			gotInter, _ := EstimateIntersection(a, b)
+			gotDiff, _ := EstimateDifference(a, b)
+			gotJaccard, _ := EstimateJaccard(a, b)
+
+			checkClose(t, "EstimateUnion()", gotUnion, float64(union), k)
+			// Errors of intersection and difference are relative to union.
+			if tolerance := 5 / math.Sqrt(k) * float64(union); math.Abs(gotInter-float64(inter)) > tolerance+1 {
+				t.Errorf("EstimateIntersection() = %v; want %d", gotInter, inter)
+			}
+			if tolerance := 5 / math.Sqrt(k) * float64(union); math.Abs(gotDiff-float64(diff)) > tolerance+1 {
+				t.Errorf("EstimateDifference() = %v; want %d", gotDiff, diff)
+			}
+			if want := float64(inter) / float64(union); math.Abs(gotJaccard-want) > 5*math.Sqrt(want*(1-want)/k)+0.001 {
+				t.Errorf("EstimateJaccard() = %v; want %v", gotJaccard, want)
+			}
+
+			u, err := Union(a, b)
+			if err != nil {
+				t.Fatalf("Union() returned error %v", err)
+			}
+			if got := u.Estimate(); got != gotUnion {
+				t.Errorf("Union().Estimate() = %v; want %v", got, gotUnion)
+			}
+		})
+	}
+}
+
+func TestSetOperationsExact(t *testing.T) {
+	a := newTestSketch(t, 100)
+	b := newTestSketch(t, 100)
+	addRange(a, 0, 30)
+	addRange(b, 20, 60)
+
+	for _, tc := range []struct {
+		name string
+		fn   func(a, b *Sketch) (float64, error)
+		want float64
+	}{
+		{"EstimateUnion", EstimateUnion, 60},
+		{"EstimateIntersection", EstimateIntersection, 10},
+		{"EstimateDifference", EstimateDifference, 20},
+		{"EstimateJaccard", EstimateJaccard, 10.0 / 60},
+	} {
+		if got, _ := tc.fn(a, b); got != tc.want {
+			t.Errorf("%s() = %v; want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestSetOperationsExactUnsaturated(t *testing.T) {
+	// Neither sketch is saturated, but union has more than k keys.
+	testCases := []struct {
+		name             string
+		aStart, aEnd     int
+		bStart, bEnd     int
+		union, intersect float64
+		difference       float64
+	}{
+		{"disjoint", 0, 9, 9, 18, 18, 0, 9},
+		{"overlapping", 0, 9, 4, 13, 13, 5, 4},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			a := newTestSketch(t, 10)
+			b := newTestSketch(t, 10)
+			addRange(a, tc.aStart, tc.aEnd)
+			addRange(b, tc.bStart, tc.bEnd)
+
+			for _, op := range []struct {
+				name string
+				fn   func(a, b *Sketch) (float64, error)
+				want float64
+			}{
+				{"EstimateUnion", EstimateUnion, tc.union},
+				{"EstimateIntersection", EstimateIntersection, tc.intersect},
+				{"EstimateDifference", EstimateDifference, tc.difference},
+				{"EstimateJaccard", EstimateJaccard, tc.intersect / tc.union},
+			} {
+				if got, _ := op.fn(a, b); got != op.want {
+					t.Errorf("%s() = %v; want %v", op.name, got, op.want)
+				}
+			}
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	// Sketches of shards merge into sketch of all keys.
+	all := newTestSketch(t, 256)
+	addRange(all, 0, 100000)
+
+	merged := newTestSketch(t, 256)
+	for shard := 0; shard < 10; shard++ {
+		s := newTestSketch(t, 256)
+		addRange(s, shard*10000, (shard+1)*10000)
+		if err := merged.Merge(s); err != nil {
+			t.Fatalf("Merge() returned error %v", err)
+		}
+	}
+
+	if !reflect.DeepEqual(merged.Sample(), all.Sample()) {
+		t.Error("Sample() of merged sketch differs from sketch of all keys")
+	}
+	if merged.Estimate() != all.Estimate() {
+		t.Errorf("Estimate() of merged sketch = %v; want %v", merged.Estimate(), all.Estimate())
+	}
+}
+
+func TestMergeSmallerK(t *testing.T) {
+	s := newTestSketch(t, 256)
+	o := newTestSketch(t, 64)
+	addRange(s, 0, 1000)
+	addRange(o, 1000, 2000)
+
+	all := newTestSketch(t, 64)
+	addRange(all, 0, 2000)
+
+	if err := s.Merge(o); err != nil {
+		t.Fatalf("Merge() returned error %v", err)
+	}
+	if s.K() != 64 {
+		t.Errorf("K() of merged sketch = %d; want 64", s.K())
+	}
+	if !reflect.DeepEqual(s.Sample(), all.Sample()) {
+		t.Error("Sample() of merged sketch differs from sketch of all keys")
+	}
+}
+
+func TestSeedMismatch(t *testing.T) {
+	a := newTestSketch(t, 10)
+	b, _ := New(10, testSeed+1)
+
+	if err := a.Merge(b); !errors.Is(err, ErrSeedMismatch) {
+		t.Errorf("Merge() returned error %v; want %v", err, ErrSeedMismatch)
+	}
+	if _, err := Union(a, b); !errors.Is(err, ErrSeedMismatch) {
+		t.Errorf("Union() returned error %v; want %v", err, ErrSeedMismatch)
+	}
+	for name, fn := range map[string]func(a, b *Sketch) (float64, error){
+		"EstimateUnion":        EstimateUnion,
+		"EstimateIntersection": EstimateIntersection,
+		"EstimateDifference":   EstimateDifference,
+		"EstimateJaccard":      EstimateJaccard,
+	} {
+		if _, err := fn(a, b); !errors.Is(err, ErrSeedMismatch) {
+			t.Errorf("%s() returned error %v; want %v", name, err, ErrSeedMismatch)
+		}
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	for _, n := range []int{0, 50, 10000} {
+		s := newTestSketch(t, 100)
+		addRange(s, 0, n)
+
+		data, err := s.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary() returned error %v", err)
+		}
+
+		var s2 Sketch
+		if err := s2.UnmarshalBinary(data); err != nil {
+			t.Fatalf("UnmarshalBinary() returned error %v", err)
+		}
+		if s2.K() != s.K() || s2.Seed() != s.Seed() || s2.Estimate() != s.Estimate() {
+			t.Errorf("UnmarshalBinary() returned sketch with k=%d, seed=0x%x, estimate %v; want %d, 0x%x, %v",
+				s2.K(), s2.Seed(), s2.Estimate(), s.K(), s.Seed(), s.Estimate())
+		}
+		if !reflect.DeepEqual(s2.Sample(), s.Sample()) {
+			t.Error("Sample() of unmarshaled sketch differs")
+		}
+
+		// Unmarshaled sketch continues to work.
+		addRange(s, n, n+100)
+		addRange(&s2, n, n+100)
+		if !reflect.DeepEqual(s2.Sample(), s.Sample()) {
+			t.Error("Sample() of unmarshaled sketch differs after adding keys")
+		}
+
+		data, _ = s.MarshalBinary()
+		data2, _ := s2.MarshalBinary()
+		if !bytes.Equal(data, data2) {
+			t.Error("MarshalBinary() of unmarshaled sketch returned different data")
+		}
+	}
+}
+
+func TestUnmarshalBinaryInvalid(t *testing.T) {
+	s := newTestSketch(t, 10)
+	addRange(s, 0, 3)
+	valid, _ := s.MarshalBinary()
+
+	modify := func(fn func(b []byte)) []byte {
+		b := append([]byte(nil), valid...)
+		fn(b)
+		return b
+	}
+
+	// Keys in wrong order.
+	entries := s.sorted()
+	unsorted, _ := s.MarshalBinary()
+	unsorted = unsorted[:headerSize]
+	for _, e := range []entry{entries[1], entries[0], entries[2]} {
+		unsorted = append(unsorted, byte(len(e.key)), 0, 0, 0)
+		unsorted = append(unsorted, e.key...)
+	}
+
+	testCases := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"short header", valid[:headerSize-1]},
+		{"bad magic", modify(func(b []byte) { b[0] = 'X' })},
+		{"bad version", modify(func(b []byte) { b[4] = 2 })},
+		{"bad algorithm", modify(func(b []byte) { b[5] = 0 })},
+		{"bad k", modify(func(b []byte) { b[8] = 1 })},
+		{"more entries than k", modify(func(b []byte) { b[12] = 11 })},
+		{"different seed", modify(func(b []byte) { b[16]++ })},
+		{"unsorted keys", unsorted},
+		{"truncated", valid[:len(valid)-1]},
+		{"extra data", append(append([]byte(nil), valid...), 0)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var s Sketch
+			if err := s.UnmarshalBinary(tc.data); err == nil {
+				t.Error("UnmarshalBinary() didn't return error")
+			}
+		})
+	}
+}
+
+func BenchmarkAdd(b *testing.B) {
+	s := newTestSketch(b, 1024)
+	keys := make([]string, 4096)
+	for i := range keys {
+		keys[i] = "user" + strconv.Itoa(i)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.AddString(keys[i%len(keys)])
+	}
+}
+
+func BenchmarkEstimateIntersection(b *testing.B) {
+	s1 := newTestSketch(b, 1024)
+	s2 := newTestSketch(b, 1024)
+	addRange(s1, 0, 100000)
+	addRange(s2, 50000, 150000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = EstimateIntersection(s1, s2)
+	}
+}
@@ -0,0 +1,193 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package maglev implements Maglev consistent hashing lookup tables using
+// CircleHash64f.  See "Maglev: A Fast and Reliable Software Network Load
+// Balancer" by Daniel E. Eisenbud et al.
+//
+// Each backend has a permutation of table entries defined by offset and skip:
+//
+//	offsetSeed = circlehash.Hash64Uint64x2(seed, 0, seed)
+//	skipSeed   = circlehash.Hash64Uint64x2(seed, 1, seed)
+//	offset     = circlehash.Hash64String(name, offsetSeed) mod size
+//	skip       = circlehash.Hash64String(name, skipSeed) mod (size-1) + 1
+//
+// Backends take turns claiming their next unclaimed entry until the table
+// is full.  With weights, backend with the largest weight takes a turn in
+// every round, and other backends take turns in proportion to their weights.
+//
+// Keys are hashed with circlehash.Hash64 (or circlehash.Hash64Uint64x2 for
+// flows encoded as two uint64) and looked up at digest mod size.
+package maglev
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+
+	"github.com/fxamacker/circlehash"
+)
+
+const (
+	// DefaultTableSize is a prime table size suitable for up to several
+	// hundred backends.  Table size should be at least 100 times number
+	// of backends for even distribution.
+	DefaultTableSize = 65537
+
+	// MaxTableSize is maximum table size.
+	MaxTableSize = 1 << 26
+)
+
+// Backend is a backend server.
+type Backend struct {
+	// Name identifies backend, and must be unique and stable.
+	Name string
+
+	// Weight is relative capacity of backend.  Backends with weight 0
+	// receive no entries.
+	Weight int
+}
+
+// Table is a Maglev lookup table.  It is immutable and safe for concurrent use.
+type Table struct {
+	seed     uint64
+	backends []Backend
+	entries  []uint32 // index of backend for each entry
+}
+
+// New returns a Table of size entries for backends.  Size must be a prime
+// greater than or equal to number of backends with positive weight.
+func New(backends []Backend, size int, seed uint64) (*Table, error) {
+	if size < 2 || size > MaxTableSize || !isPrime(size) {
+		return nil, fmt.Errorf("maglev: table size %d isn't a prime in range [2, %d]", size, MaxTableSize)
+	}
+
+	maxWeight := 0
+	names := make(map[string]struct{}, len(backends))
+	var active []int
+	for i, b := range backends {
+		if b.Weight < 0 {
+			return nil, fmt.Errorf("maglev: backend %q has negative weight %d", b.Name, b.Weight)
+		}
+		if _, ok := names[b.Name]; ok {
+			return nil, fmt.Errorf("maglev: duplicate backend %q", b.Name)
+		}
+		names[b.Name] = struct{}{}
+		if b.Weight > 0 {
+			active = append(active, i)
+		}
+		if b.Weight > maxWeight {
+			maxWeight = b.Weight
+		}
+	}
+	if len(active) == 0 {
+		return nil, errors.New("maglev: no backends with positive weight")
+	}
+	if len(active) > size {
+		return nil, fmt.Errorf("maglev: table size %d is less than number of backends %d", size, len(active))
+	}
+
+	m := uint64(size)
+	offsetSeed := circlehash.Hash64Uint64x2(seed, 0, seed)
+	skipSeed := circlehash.Hash64Uint64x2(seed, 1, seed)
+
+	pos := make([]uint64, len(active))
+	skip := make([]uint64, len(active))
+	for j, i := range active {
+		pos[j] = circlehash.Hash64String(backends[i].Name, offsetSeed) % m
+		skip[j] = circlehash.Hash64String(backends[i].Name, skipSeed)%(m-1) + 1
+	}
+
+	const empty = ^uint32(0)
+	entries := make([]uint32, size)
+	for i := range entries {
+		entries[i] = empty
+	}
+
+	claimed := make([]uint64, len(active))
+	filled := 0
+	for round := uint64(1); filled < size; round++ {
+		for j, i := range active {
+			// Backend takes a turn if it has claimed less than its share
+			// of round * weight / maxWeight entries.  Products are
+			// compared as 128-bit integers so large weights don't overflow.
+			hi1, lo1 := bits.Mul64(claimed[j], uint64(maxWeight))
+			hi2, lo2 := bits.Mul64(round, uint64(backends[i].Weight))
+			if hi1 > hi2 || (hi1 == hi2 && lo1 >= lo2) {
+				continue
+			}
+
+			for entries[pos[j]] != empty {
+				pos[j] = (pos[j] + skip[j]) % m
+			}
+			entries[pos[j]] = uint32(i)
+			pos[j] = (pos[j] + skip[j]) % m
+			claimed[j]++
+
+			filled++
+			if filled == size {
+				break
+			}
+		}
+	}
+
+	return &Table{
+		seed:     seed,
+		backends: append([]Backend(nil), backends...),
+		entries:  entries,
+	}, nil
+}
+
+func isPrime(n int) bool {
+	if n < 2 {
+		return false
+	}
+	for d := 2; d*d <= n; d++ {
+		if n%d == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Size returns number of entries in t.
+func (t *Table) Size() int {
+	return len(t.entries)
+}
+
+// Backends returns backends of t.
+func (t *Table) Backends() []Backend {
+	return append([]Backend(nil), t.backends...)
+}
+
+// Lookup returns index of backend for key with digest h.
+func (t *Table) Lookup(h uint64) int {
+	return int(t.entries[h%uint64(len(t.entries))])
+}
+
+// Get returns name of backend for key b.
+func (t *Table) Get(b []byte) string {
+	return t.backends[t.Lookup(circlehash.Hash64(b, t.seed))].Name
+}
+
+// GetString returns name of backend for key s.
+func (t *Table) GetString(s string) string {
+	return t.backends[t.Lookup(circlehash.Hash64String(s, t.seed))].Name
+}
+
+// GetUint64x2 returns name of backend for key encoded as a and b,
+// such as a flow with addresses in a and ports and protocol in b.
+func (t *Table) GetUint64x2(a, b uint64) string {
+	return t.backends[t.Lookup(circlehash.Hash64Uint64x2(a, b, t.seed))].Name
+}
@@ -0,0 +1,197 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package maglev
+
+import (
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/fxamacker/circlehash"
+)
+
+const testSeed = uint64(0x9E3779B97F4A7C15)
+
+func testBackends(n int) []Backend {
+	backends := make([]Backend, n)
+	for i := range backends {
+		backends[i] = Backend{Name: "10.0.0." + strconv.Itoa(i+1) + ":80", Weight: 1}
+	}
+	return backends
+}
+
+func newTestTable(t *testing.T, backends []Backend, size int) *Table {
+	tbl, err := New(backends, size, testSeed)
+	if err != nil {
+		t.Fatalf("New() returned error %v", err)
+	}
+	return tbl
+}
+
+// entryCounts returns number of entries per backend name.
+func entryCounts(tbl *Table) map[string]int {
+	counts := make(map[string]int)
+	for _, i := range tbl.entries {
+		counts[tbl.backends[i].Name]++
+	}
+	return counts
+}
+
+func TestNewInvalid(t *testing.T) {
+	testCases := []struct {
+		name     string
+		backends []Backend
+		size     int
+	}{
+		{"no backends", nil, DefaultTableSize},
+		{"zero weights", []Backend{{"a", 0}, {"b", 0}}, DefaultTableSize},
+		{"negative weight", []Backend{{"a", 1}, {"b", -1}}, DefaultTableSize},
+		{"duplicate names", []Backend{{"a", 1}, {"a", 1}}, DefaultTableSize},
+		{"size not prime", testBackends(3), 65536},
+		{"size too small", testBackends(3), 2},
+		{"size too large", testBackends(3), MaxTableSize + 1},
+	}
+	for _, tc := range testCases {
+		if _, err := New(tc.backends, tc.size, testSeed); err == nil {
+			t.Errorf("%s: New() didn't return error", tc.name)
+		}
+	}
+}
+
+func TestEvenDistribution(t *testing.T) {
+	// Backends with equal weights have entries differing by at most 1.
+	for _, n := range []int{1, 2, 7, 10, 100} {
+		tbl := newTestTable(t, testBackends(n), DefaultTableSize)
+		counts := entryCounts(tbl)
+		if len(counts) != n {
+			t.Fatalf("%d backends: table has entries for %d backends", n, len(counts))
+		}
+		lo, hi := math.MaxInt32, 0
+		for _, c := range counts {
+			if c < lo {
+				lo = c
+			}
+			if c > hi {
+				hi = c
+			}
+		}
+		if hi-lo > 1 {
+			t.Errorf("%d backends: entries per backend in range [%d, %d]; want difference at most 1", n, lo, hi)
+		}
+	}
+}
+
+func TestWeights(t *testing.T) {
+	backends := []Backend{{"a", 1}, {"b", 2}, {"c", 5}, {"d", 0}}
+	tbl := newTestTable(t, backends, DefaultTableSize)
+	counts := entryCounts(tbl)
+
+	for _, b := range backends {
+		want := float64(DefaultTableSize) * float64(b.Weight) / 8
+		if math.Abs(float64(counts[b.Name])-want) > 1+want*0.001 {
+			t.Errorf("backend %s with weight %d has %d entries; want about %.0f", b.Name, b.Weight, counts[b.Name], want)
+		}
+	}
+}
+
+func TestLargeWeights(t *testing.T) {
+	// Products of entries claimed and weights overflow uint64.
+	const w = math.MaxInt / 4
+	backends := []Backend{{"a", w}, {"b", 2 * w}, {"c", 4 * w}, {"d", 1}}
+	tbl := newTestTable(t, backends, DefaultTableSize)
+	counts := entryCounts(tbl)
+
+	for _, b := range backends {
+		want := float64(DefaultTableSize) * float64(b.Weight) / float64(7*w)
+		if math.Abs(float64(counts[b.Name])-want) > 1+want*0.001 {
+			t.Errorf("backend %s with weight %d has %d entries; want about %.0f", b.Name, b.Weight, counts[b.Name], want)
+		}
+	}
+}
+
+func TestDisruption(t *testing.T) {
+	const size = DefaultTableSize
+
+	for _, n := range []int{5, 10, 50} {
+		backends := testBackends(n)
+		before := newTestTable(t, backends, size)
+
+		// Remove one backend.
+		removed := backends[n/2].Name
+		remaining := append(append([]Backend(nil), backends[:n/2]...), backends[n/2+1:]...)
+		after := newTestTable(t, remaining, size)
+
+		changed := 0
+		for e := range before.entries {
+			b1 := before.backends[before.entries[e]].Name
+			b2 := after.backends[after.entries[e]].Name
+			if b1 != removed && b1 != b2 {
+				changed++
+			}
+		}
+
+		// Entries of remaining backends rarely change.
+		if got := float64(changed) / size; got > 0.02 {
+			t.Errorf("%d backends: %v of entries of remaining backends changed; want at most 0.02", n, got)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	tbl := newTestTable(t, testBackends(5), 251)
+	if tbl.Size() != 251 {
+		t.Errorf("Size() = %d; want 251", tbl.Size())
+	}
+
+	for i := 0; i < 1000; i++ {
+		key := strconv.Itoa(i)
+		want := tbl.backends[tbl.entries[circlehash.Hash64String(key, testSeed)%251]].Name
+		if got := tbl.GetString(key); got != want {
+			t.Errorf("GetString(%q) = %s; want %s", key, got, want)
+		}
+		if got := tbl.Get([]byte(key)); got != want {
+			t.Errorf("Get(%q) = %s; want %s", key, got, want)
+		}
+
+		a, b := uint64(i), uint64(i)*31
+		want = tbl.backends[tbl.entries[circlehash.Hash64Uint64x2(a, b, testSeed)%251]].Name
+		if got := tbl.GetUint64x2(a, b); got != want {
+			t.Errorf("GetUint64x2(%d, %d) = %s; want %s", a, b, got, want)
+		}
+	}
+}
+
+func TestBackendsCopied(t *testing.T) {
+	backends := testBackends(3)
+	tbl := newTestTable(t, backends, 101)
+	backends[0].Name = "changed"
+	if tbl.Backends()[0].Name == "changed" {
+		t.Error("Table uses backends slice passed to New()")
+	}
+}
+
+func BenchmarkNew(b *testing.B) {
+	backends := testBackends(100)
+	for i := 0; i < b.N; i++ {
+		_, _ = New(backends, DefaultTableSize, testSeed)
+	}
+}
+
+func BenchmarkGetUint64x2(b *testing.B) {
+	tbl, _ := New(testBackends(100), DefaultTableSize, testSeed)
+	for i := 0; i < b.N; i++ {
+		_ = tbl.GetUint64x2(uint64(i), 0x0050c000)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package minhash
+
+import (
+	"errors"
+	"fmt"
+)
+
+// BBitSignature is a MinHash signature with the lowest b bits of each value.
+// See "b-Bit Minwise Hashing" by Ping Li and Arnd Christian König.
+type BBitSignature struct {
+	b     uint
+	k     int
+	words []uint64 // packed values, 64/b values per word
+}
+
+// Compress returns b-bit signature of s, with b in range [1, 32].
+func (s Signature) Compress(b int) (*BBitSignature, error) {
+	if b < 1 || b > 32 {
+		return nil, fmt.Errorf("minhash: b %d is not in range [1, 32]", b)
+	}
+
+	perWord := 64 / b
+	bs := &BBitSignature{
+		b:     uint(b),
+		k:     len(s),
+		words: make([]uint64, (len(s)+perWord-1)/perWord),
+	}
+	mask := uint64(1)<<uint(b) - 1
+	for i, v := range s {
+		bs.words[i/perWord] |= (v & mask) << (uint(i%perWord) * bs.b)
+	}
+	return bs, nil
+}
+
+// B returns number of bits per value.
+func (bs *BBitSignature) B() int {
+	return int(bs.b)
+}
+
+// K returns number of values.
+func (bs *BBitSignature) K() int {
+	return bs.k
+}
+
+// Words returns packed values of bs.  Value i is bits [(i%p)*b, (i%p+1)*b)
+// of word i/p, where p is 64/b.
+func (bs *BBitSignature) Words() []uint64 {
+	return bs.words
+}
+
+// BBitJaccard returns estimated Jaccard similarity of sets with b-bit
+// signatures a and b.  Values of different sets are equal by chance with
+// probability 1/2^b, so estimate is corrected for it.  This correction
+// assumes sets are large relative to 2^b.
+func BBitJaccard(a, b *BBitSignature) (float64, error) {
+	if a.k != b.k {
+		return 0, ErrLengthMismatch
+	}
+	if a.b != b.b {
+		return 0, errors.New("minhash: b-bit signatures have different b")
+	}
+	if a.k == 0 {
+		return 0, nil
+	}
+
+	perWord := 64 / int(a.b)
+	mask := uint64(1)<<a.b - 1
+	equal := 0
+	for i := 0; i < a.k; i++ {
+		shift := uint(i%perWord) * a.b
+		if (a.words[i/perWord]>>shift)&mask == (b.words[i/perWord]>>shift)&mask {
+			equal++
+		}
+	}
+
+	chance := 1 / float64(uint64(1)<<a.b)
+	j := (float64(equal)/float64(a.k) - chance) / (1 - chance)
+	if j < 0 {
+		j = 0
+	}
+	return j, nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package minhash
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestCompress(t *testing.T) {
+	sig := Signature{0x1234, 0xffffffffffffffff, 0, 0xabcdef}
+	for _, b := range []int{1, 3, 8, 13, 32} {
+		bs, err := sig.Compress(b)
+		if err != nil {
+			t.Fatalf("Compress(%d) returned error %v", b, err)
+		}
+		if bs.B() != b || bs.K() != len(sig) {
+			t.Errorf("Compress(%d) returned b=%d, k=%d; want b=%d, k=%d", b, bs.B(), bs.K(), b, len(sig))
+		}
+
+		perWord := 64 / b
+		mask := uint64(1)<<uint(b) - 1
+		for i, v := range sig {
+			got := bs.Words()[i/perWord] >> (uint(i%perWord) * uint(b)) & mask
+			if got != v&mask {
+				t.Errorf("Compress(%d) value %d = 0x%x; want 0x%x", b, i, got, v&mask)
+			}
+		}
+	}
+
+	for _, b := range []int{0, 33} {
+		if _, err := sig.Compress(b); err == nil {
+			t.Errorf("Compress(%d) didn't return error", b)
+		}
+	}
+}
+
+func TestBBitJaccardAccuracy(t *testing.T) {
+	const k = 1024
+
+	m := newTestMinHasher(t, k, OnePermutation)
+	for _, b := range []int{2, 4, 8} {
+		for _, target := range []float64{0.1, 0.5, 0.9} {
+			t.Run(fmt.Sprintf("b=%d/j=%v", b, target), func(t *testing.T) {
+				s1, s2, exact := syntheticSets(5000, target, "bbit")
+				a, _ := m.SignatureStrings(s1).Compress(b)
+				c, _ := m.SignatureStrings(s2).Compress(b)
+
+				got, err := BBitJaccard(a, c)
+				if err != nil {
+					t.Fatalf("BBitJaccard() returned error %v", err)
+				}
+
+				// Variance of b-bit estimate is larger by about 1/(1-1/2^b)^2.
+				chance := 1 / float64(uint64(1)<<uint(b))
+				stdErr := math.Sqrt((exact+chance*(1-exact))*(1-exact)*(1-chance)/k) / (1 - chance)
+				if math.Abs(got-exact) > 4*stdErr+0.01 {
+					t.Errorf("BBitJaccard() = %v; want %v (standard error %v)", got, exact, stdErr)
+				}
+			})
+		}
+	}
+}
+
+func TestBBitJaccardMismatch(t *testing.T) {
+	sig := make(Signature, 10)
+	a, _ := sig.Compress(4)
+	b, _ := sig.Compress(8)
+	c, _ := sig[:9].Compress(4)
+
+	if _, err := BBitJaccard(a, b); err == nil {
+		t.Error("BBitJaccard() with different b didn't return error")
+	}
+	if _, err := BBitJaccard(a, c); !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("BBitJaccard() with different k returned error %v; want %v", err, ErrLengthMismatch)
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package minhash
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/fxamacker/circlehash"
+)
+
+// Index is a locality-sensitive hashing index of signatures using banding.
+// Signature is split into bands of rows values, and each band is hashed
+// with circlehash.CombineOrdered64.  Signatures sharing any band hash
+// are candidates.  Index is not safe for concurrent use.
+type Index struct {
+	bands  int
+	rows   int
+	seed   uint64
+	tables []map[uint64][]string // band hash to ids, per band
+	ids    map[string][]uint64   // id to band hashes
+}
+
+// NewIndex returns an Index for signatures with at least bands*rows values.
+// Pairs with Jaccard similarity above about (1/bands)^(1/rows) are likely
+// to be candidates.
+func NewIndex(bands, rows int, seed uint64) (*Index, error) {
+	if bands < 1 || rows < 1 {
+		return nil, fmt.Errorf("minhash: bands %d and rows %d must be at least 1", bands, rows)
+	}
+	tables := make([]map[uint64][]string, bands)
+	for i := range tables {
+		tables[i] = make(map[uint64][]string)
+	}
+	return &Index{
+		bands:  bands,
+		rows:   rows,
+		seed:   seed,
+		tables: tables,
+		ids:    make(map[string][]uint64),
+	}, nil
+}
+
+// Threshold returns approximate Jaccard similarity at which probability
+// of becoming candidates is steepest.
+func (x *Index) Threshold() float64 {
+	return math.Pow(1/float64(x.bands), 1/float64(x.rows))
+}
+
+// Len returns number of signatures in x.
+func (x *Index) Len() int {
+	return len(x.ids)
+}
+
+// Add adds signature with id to x, replacing previous signature with id.
+func (x *Index) Add(id string, sig Signature) error {
+	keys, err := x.bandHashes(sig)
+	if err != nil {
+		return err
+	}
+	x.Remove(id)
+	for band, key := range keys {
+		x.tables[band][key] = append(x.tables[band][key], id)
+	}
+	x.ids[id] = keys
+	return nil
+}
+
+// Remove removes signature with id from x.
+func (x *Index) Remove(id string) {
+	keys, ok := x.ids[id]
+	if !ok {
+		return
+	}
+	for band, key := range keys {
+		ids := x.tables[band][key]
+		for i, v := range ids {
+			if v == id {
+				ids = append(ids[:i], ids[i+1:]...)
+				break
+			}
+		}
+		if len(ids) == 0 {
+			delete(x.tables[band], key)
+		} else {
+			x.tables[band][key] = ids
+		}
+	}
+	delete(x.ids, id)
+}
+
+// Query returns sorted ids of candidate signatures sharing a band with sig.
+func (x *Index) Query(sig Signature) ([]string, error) {
+	keys, err := x.bandHashes(sig)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{})
+	var candidates []string
+	for band, key := range keys {
+		for _, id := range x.tables[band][key] {
+			if _, ok := seen[id]; !ok {
+				seen[id] = struct{}{}
+				candidates = append(candidates, id)
+			}
+		}
+	}
+	sort.Strings(candidates)
+	return candidates, nil
+}
+
+func (x *Index) bandHashes(sig Signature) ([]uint64, error) {
+	if len(sig) < x.bands*x.rows {
+		return nil, fmt.Errorf("minhash: signature has %d values; want at least %d", len(sig), x.bands*x.rows)
+	}
+	keys := make([]uint64, x.bands)
+	for band := range keys {
+		keys[band] = circlehash.CombineOrdered64(x.seed, sig[band*x.rows:(band+1)*x.rows]...)
+	}
+	return keys, nil
+}
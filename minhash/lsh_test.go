@@ -0,0 +1,120 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package minhash
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestIndex(t *testing.T) {
+	const bands, rows = 20, 5
+
+	m := newTestMinHasher(t, bands*rows, OnePermutation)
+	x, err := NewIndex(bands, rows, testSeed)
+	if err != nil {
+		t.Fatalf("NewIndex() returned error %v", err)
+	}
+	if th := x.Threshold(); math.Abs(th-0.549) > 0.001 {
+		t.Errorf("Threshold() = %v; want about 0.549", th)
+	}
+
+	// Each query has Jaccard similarity of about 0.9 with the document
+	// with the same id, and is dissimilar to other documents.
+	queries := make(map[string]Signature)
+	for i := 0; i < 100; i++ {
+		a, b, _ := syntheticSets(200, 0.9, "doc"+strconv.Itoa(i))
+		if err := x.Add(strconv.Itoa(i), m.SignatureStrings(a)); err != nil {
+			t.Fatalf("Add() returned error %v", err)
+		}
+		queries[strconv.Itoa(i)] = m.SignatureStrings(b)
+	}
+	if x.Len() != 100 {
+		t.Errorf("Len() = %d; want 100", x.Len())
+	}
+
+	falsePositives := 0
+	for id, sig := range queries {
+		candidates, err := x.Query(sig)
+		if err != nil {
+			t.Fatalf("Query() returned error %v", err)
+		}
+		found := false
+		for _, c := range candidates {
+			if c == id {
+				found = true
+			} else {
+				falsePositives++
+			}
+		}
+		if !found {
+			t.Errorf("Query() of near duplicate of %s returned %v", id, candidates)
+		}
+	}
+	if falsePositives > 0 {
+		t.Errorf("Query() returned %d dissimilar candidates", falsePositives)
+	}
+}
+
+func TestIndexAddRemove(t *testing.T) {
+	m := newTestMinHasher(t, 16, KPermutation)
+	x, _ := NewIndex(4, 4, testSeed)
+
+	sig1 := m.SignatureStrings([]string{"a", "b", "c"})
+	sig2 := m.SignatureStrings([]string{"x", "y", "z"})
+
+	_ = x.Add("doc", sig1)
+	_ = x.Add("doc", sig2) // replaces sig1
+	if got, _ := x.Query(sig1); len(got) != 0 {
+		t.Errorf("Query() of replaced signature returned %v", got)
+	}
+	if got, _ := x.Query(sig2); len(got) != 1 || got[0] != "doc" {
+		t.Errorf("Query() = %v; want [doc]", got)
+	}
+
+	_ = x.Add("copy", sig2)
+	if got, _ := x.Query(sig2); len(got) != 2 || got[0] != "copy" || got[1] != "doc" {
+		t.Errorf("Query() = %v; want [copy doc]", got)
+	}
+
+	x.Remove("doc")
+	x.Remove("missing")
+	if got, _ := x.Query(sig2); len(got) != 1 || got[0] != "copy" {
+		t.Errorf("Query() after Remove() = %v; want [copy]", got)
+	}
+	x.Remove("copy")
+	if x.Len() != 0 {
+		t.Errorf("Len() = %d; want 0", x.Len())
+	}
+	for band, table := range x.tables {
+		if len(table) != 0 {
+			t.Errorf("band %d has %d entries after removing all signatures", band, len(table))
+		}
+	}
+}
+
+func TestIndexInvalid(t *testing.T) {
+	if _, err := NewIndex(0, 4, testSeed); err == nil {
+		t.Error("NewIndex(0, 4) didn't return error")
+	}
+	x, _ := NewIndex(4, 4, testSeed)
+	if err := x.Add("short", make(Signature, 15)); err == nil {
+		t.Error("Add() with short signature didn't return error")
+	}
+	if _, err := x.Query(make(Signature, 15)); err == nil {
+		t.Error("Query() with short signature didn't return error")
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package minhash implements MinHash signatures for estimating Jaccard
+// similarity of sets using CircleHash64f.
+//
+// Each shingle (set element) is hashed once with circlehash.Hash64 or
+// circlehash.Hash64String.  MinHasher then computes signature with one of:
+//
+//   - KPermutation: value i of signature is minimum of
+//     circlehash.Hash64Uint64x2(h, i, seed) over shingle digests h.
+//     It costs k hashes per shingle.
+//
+//   - OnePermutation: shingle digests are split into k bins by value, and
+//     value i of signature is minimum digest in bin i.  Empty bins are
+//     filled with optimal densification: bin i copies bin
+//     circlehash.Hash64Uint64x2(i, attempt, seed) mod k for attempt 1, 2, ...
+//     until a non-empty bin is found.  It costs one hash per shingle.
+//     See "Optimal Densification for Fast and Accurate Minwise Hashing"
+//     by Anshumali Shrivastava.
+//
+// Signatures can be compressed to b bits per value (b-bit MinHash), and
+// indexed with locality-sensitive hashing (LSH) to find candidate pairs.
+package minhash
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/fxamacker/circlehash"
+)
+
+// Method is a method of computing MinHash signatures.
+type Method int
+
+const (
+	// KPermutation uses k independent hash functions.
+	KPermutation Method = iota
+
+	// OnePermutation uses one hash function and k bins with densification.
+	OnePermutation
+)
+
+func (m Method) String() string {
+	switch m {
+	case KPermutation:
+		return "KPermutation"
+	case OnePermutation:
+		return "OnePermutation"
+	default:
+		return fmt.Sprintf("Method(%d)", int(m))
+	}
+}
+
+// emptyValue is signature value of empty set.
+const emptyValue = math.MaxUint64
+
+// ErrLengthMismatch is returned when comparing signatures of different lengths.
+var ErrLengthMismatch = errors.New("minhash: signatures have different lengths")
+
+// Signature is a MinHash signature.  Signatures can be compared only if
+// they are computed by MinHashers with the same k, method, and seed.
+type Signature []uint64
+
+// MinHasher computes MinHash signatures.  It is safe for concurrent use.
+type MinHasher struct {
+	k      int
+	method Method
+	seed   uint64
+}
+
+// New returns a MinHasher that computes signatures with k values.
+// Standard error of estimated Jaccard similarity J is about sqrt(J(1-J)/k).
+func New(k int, method Method, seed uint64) (*MinHasher, error) {
+	if k < 1 {
+		return nil, fmt.Errorf("minhash: k %d is less than 1", k)
+	}
+	if method != KPermutation && method != OnePermutation {
+		return nil, fmt.Errorf("minhash: unsupported method %d", int(method))
+	}
+	return &MinHasher{k: k, method: method, seed: seed}, nil
+}
+
+// K returns number of values in signatures.
+func (m *MinHasher) K() int {
+	return m.k
+}
+
+// Method returns method used to compute signatures.
+func (m *MinHasher) Method() Method {
+	return m.method
+}
+
+// Seed returns the seed used by m.
+func (m *MinHasher) Seed() uint64 {
+	return m.seed
+}
+
+// Signature returns signature of set of shingles.  Duplicate shingles are allowed.
+func (m *MinHasher) Signature(shingles [][]byte) Signature {
+	sig := m.newSignature()
+	for _, s := range shingles {
+		m.update(sig, circlehash.Hash64(s, m.seed))
+	}
+	return m.finish(sig)
+}
+
+// SignatureStrings returns signature of set of shingles.  Duplicate shingles are allowed.
+func (m *MinHasher) SignatureStrings(shingles []string) Signature {
+	sig := m.newSignature()
+	for _, s := range shingles {
+		m.update(sig, circlehash.Hash64String(s, m.seed))
+	}
+	return m.finish(sig)
+}
+
+func (m *MinHasher) newSignature() Signature {
+	sig := make(Signature, m.k)
+	for i := range sig {
+		sig[i] = emptyValue
+	}
+	return sig
+}
+
+func (m *MinHasher) update(sig Signature, h uint64) {
+	if m.method == OnePermutation {
+		bin, _ := bits.Mul64(h, uint64(m.k))
+		if h < sig[bin] {
+			sig[bin] = h
+		}
+		return
+	}
+
+	for i := range sig {
+		if v := circlehash.Hash64Uint64x2(h, uint64(i), m.seed); v < sig[i] {
+			sig[i] = v
+		}
+	}
+}
+
+// finish densifies one permutation signature.
+func (m *MinHasher) finish(sig Signature) Signature {
+	if m.method != OnePermutation {
+		return sig
+	}
+
+	nonEmpty := false
+	for _, v := range sig {
+		if v != emptyValue {
+			nonEmpty = true
+			break
+		}
+	}
+	if !nonEmpty {
+		return sig
+	}
+
+	// Empty bins copy non-empty bins of original (not densified) signature.
+	var empty []int
+	for i, v := range sig {
+		if v == emptyValue {
+			empty = append(empty, i)
+		}
+	}
+	for _, i := range empty {
+		for attempt := uint64(1); ; attempt++ {
+			j, _ := bits.Mul64(circlehash.Hash64Uint64x2(uint64(i), attempt, m.seed), uint64(m.k))
+			if !isEmptyBin(empty, int(j)) {
+				sig[i] = sig[j]
+				break
+			}
+		}
+	}
+	return sig
+}
+
+// isEmptyBin returns true if bin i is in sorted list of empty bins.
+func isEmptyBin(empty []int, i int) bool {
+	lo, hi := 0, len(empty)
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		switch {
+		case empty[mid] == i:
+			return true
+		case empty[mid] < i:
+			lo = mid + 1
+		default:
+			hi = mid
+		}
+	}
+	return false
+}
+
+// Jaccard returns estimated Jaccard similarity of sets with signatures a and b.
+func Jaccard(a, b Signature) (float64, error) {
+	if len(a) != len(b) {
+		return 0, ErrLengthMismatch
+	}
+	if len(a) == 0 {
+		return 0, nil
+	}
+	equal := 0
+	for i := range a {
+		if a[i] == b[i] {
+			equal++
+		}
+	}
+	return float64(equal) / float64(len(a)), nil
+}
@@ -0,0 +1,158 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package minhash
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"testing"
+)
+
+const testSeed = uint64(0x9E3779B97F4A7C15)
+
+// syntheticSets returns two sets of n elements each with Jaccard similarity
+// of about j.  Sets share c elements, where j = c/(2n-c).
+func syntheticSets(n int, j float64, prefix string) ([]string, []string, float64) {
+	c := int(math.Round(2 * float64(n) * j / (1 + j)))
+	a := make([]string, 0, n)
+	b := make([]string, 0, n)
+	for i := 0; i < c; i++ {
+		a = append(a, prefix+"shared"+strconv.Itoa(i))
+		b = append(b, prefix+"shared"+strconv.Itoa(i))
+	}
+	for i := c; i < n; i++ {
+		a = append(a, prefix+"a"+strconv.Itoa(i))
+		b = append(b, prefix+"b"+strconv.Itoa(i))
+	}
+	return a, b, float64(c) / float64(2*n-c)
+}
+
+func newTestMinHasher(t testing.TB, k int, method Method) *MinHasher {
+	m, err := New(k, method, testSeed)
+	if err != nil {
+		t.Fatalf("New(%d, %s) returned error %v", k, method, err)
+	}
+	return m
+}
+
+func TestNewInvalid(t *testing.T) {
+	if _, err := New(0, KPermutation, testSeed); err == nil {
+		t.Error("New(0) didn't return error")
+	}
+	if _, err := New(10, Method(2), testSeed); err == nil {
+		t.Error("New() with unsupported method didn't return error")
+	}
+}
+
+func TestJaccardAccuracy(t *testing.T) {
+	const k = 512
+
+	for _, method := range []Method{KPermutation, OnePermutation} {
+		m := newTestMinHasher(t, k, method)
+
+		for _, n := range []int{50, 1000, 10000} {
+			for _, target := range []float64{0, 0.1, 0.3, 0.5, 0.8, 0.95, 1} {
+				t.Run(fmt.Sprintf("%s/n=%d/j=%v", method, n, target), func(t *testing.T) {
+					a, b, exact := syntheticSets(n, target, strconv.Itoa(n))
+
+					got, err := Jaccard(m.SignatureStrings(a), m.SignatureStrings(b))
+					if err != nil {
+						t.Fatalf("Jaccard() returned error %v", err)
+					}
+
+					stdErr := math.Sqrt(exact * (1 - exact) / k)
+					if math.Abs(got-exact) > 4*stdErr+0.01 {
+						t.Errorf("Jaccard() = %v; want %v (standard error %v)", got, exact, stdErr)
+					}
+				})
+			}
+		}
+	}
+}
+
+func TestSignatureBytesAndStrings(t *testing.T) {
+	shingles := []string{"the quick", "quick brown", "brown fox"}
+	byteShingles := make([][]byte, len(shingles))
+	for i, s := range shingles {
+		byteShingles[i] = []byte(s)
+	}
+
+	for _, method := range []Method{KPermutation, OnePermutation} {
+		m := newTestMinHasher(t, 64, method)
+		sig1 := m.SignatureStrings(shingles)
+		sig2 := m.Signature(byteShingles)
+		if j, _ := Jaccard(sig1, sig2); j != 1 {
+			t.Errorf("%s: Signature() differs from SignatureStrings()", method)
+		}
+
+		// Order and duplicates don't matter.
+		sig3 := m.SignatureStrings([]string{"brown fox", "the quick", "brown fox", "quick brown"})
+		if j, _ := Jaccard(sig1, sig3); j != 1 {
+			t.Errorf("%s: signature depends on order or duplicates of shingles", method)
+		}
+	}
+}
+
+func TestOnePermutationDensified(t *testing.T) {
+	// Set with fewer elements than bins has no empty values after densification.
+	m := newTestMinHasher(t, 128, OnePermutation)
+	sig := m.SignatureStrings([]string{"a", "b", "c"})
+	for i, v := range sig {
+		if v == emptyValue {
+			t.Fatalf("signature value %d is empty after densification", i)
+		}
+	}
+
+	// Empty set has all empty values.
+	for i, v := range m.SignatureStrings(nil) {
+		if v != emptyValue {
+			t.Fatalf("signature value %d of empty set = 0x%x; want 0x%x", i, v, uint64(emptyValue))
+		}
+	}
+}
+
+func TestSeed(t *testing.T) {
+	for _, method := range []Method{KPermutation, OnePermutation} {
+		m1, _ := New(128, method, 1)
+		m2, _ := New(128, method, 2)
+		shingles := []string{"a", "b", "c", "d"}
+		if j, _ := Jaccard(m1.SignatureStrings(shingles), m2.SignatureStrings(shingles)); j > 0.1 {
+			t.Errorf("%s: signatures with different seeds are similar (%v)", method, j)
+		}
+	}
+}
+
+func TestJaccardLengthMismatch(t *testing.T) {
+	if _, err := Jaccard(make(Signature, 3), make(Signature, 4)); !errors.Is(err, ErrLengthMismatch) {
+		t.Errorf("Jaccard() returned error %v; want %v", err, ErrLengthMismatch)
+	}
+}
+
+func BenchmarkSignature(b *testing.B) {
+	shingles := make([][]byte, 200)
+	for i := range shingles {
+		shingles[i] = []byte("shingle " + strconv.Itoa(i))
+	}
+	for _, method := range []Method{KPermutation, OnePermutation} {
+		m := newTestMinHasher(b, 128, method)
+		b.Run(method.String(), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_ = m.Signature(shingles)
+			}
+		})
+	}
+}
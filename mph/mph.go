@@ -0,0 +1,452 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package mph implements minimal perfect hash functions (MPHF) for static
+// key sets using CircleHash64f.
+//
+// MPHF maps n distinct keys to distinct indices in [0, n).  It is built with
+// BBHash algorithm described in "Fast and Scalable Minimal Perfect Hashing
+// for Massive Key Sets" by Antoine Limasset et al.  Keys are placed in levels
+// of bit arrays.  At level l, key is hashed to a position in bit array of
+// size about gamma times number of keys remaining at level l:
+//
+//	levelSeed_l = circlehash.Hash64Uint64x2(seed, l, seed)
+//	h           = circlehash.Hash64(key, levelSeed_l)
+//	position    = high 64 bits of 128-bit product h * size_l
+//
+// Keys without collisions at level l set their bits, and other keys move to
+// level l+1.  Index of key is number of set bits before its bit in all levels
+// (rank).  With gamma 2, MPHF uses about 3.7 bits per key for bit arrays, and
+// 0.5 bits per key for rank samples.
+//
+// Levels are built in parallel.  Result doesn't depend on number of workers.
+// If keys aren't placed within MaxLevels levels, Build retries with seed i
+// derived from base seed:
+//
+//	seed_i = circlehash.Hash64Uint64x2(baseSeed, i, baseSeed)
+//
+// Seed used by the successful attempt is recorded in serialized MPHF.
+package mph
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/bits"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/fxamacker/circlehash"
+)
+
+const (
+	// DefaultGamma is default ratio of bit array size to number of keys
+	// in each level.
+	DefaultGamma = 2.0
+
+	// MaxLevels is maximum number of levels.
+	MaxLevels = 64
+
+	// MaxAttempts is maximum number of construction attempts by Build.
+	MaxAttempts = 10
+
+	// MaxKeys is maximum number of keys.
+	MaxKeys = 1 << 32
+
+	formatVersion = 1
+
+	// algorithmCircleHash64fBBHash is BBHash with positions derived from
+	// CircleHash64f digest with level seeds.
+	algorithmCircleHash64fBBHash = 1
+
+	// headerSize is the size of serialized MPHF header:
+	// magic (4), version (1), algorithm (1), reserved (2),
+	// number of keys (8), seed (8), number of levels (4), reserved (4).
+	// Header is followed by level sizes (8 bytes each) and bit array words.
+	headerSize = 32
+
+	// wordsPerRankSample is number of words between rank samples.
+	wordsPerRankSample = 8
+
+	// minKeysPerWorker is minimum number of keys processed by a worker.
+	minKeysPerWorker = 1 << 14
+)
+
+var magic = [4]byte{'C', 'H', 'M', 'P'}
+
+var (
+	// ErrConstructionFailed is returned when Build fails to construct MPHF
+	// after MaxAttempts attempts.
+	ErrConstructionFailed = errors.New("mph: failed to construct MPHF")
+
+	// ErrDuplicateKeys is returned when keys contain duplicates.
+	ErrDuplicateKeys = errors.New("mph: duplicate keys")
+
+	// ErrInvalidData is returned by UnmarshalBinary when data isn't a valid
+	// serialized MPHF.
+	ErrInvalidData = errors.New("mph: invalid serialized MPHF")
+)
+
+// MPH is a minimal perfect hash function.  It is immutable and safe for
+// concurrent use.
+type MPH struct {
+	seed   uint64
+	n      uint64
+	levels []level
+	bits   []uint64 // bit arrays of all levels
+	ranks  []uint64 // number of set bits before every wordsPerRankSample words
+}
+
+type level struct {
+	seed   uint64
+	offset uint64 // offset of level's bit array in bits
+	size   uint64 // size of level's bit array in bits, a multiple of 64
+}
+
+// Build returns MPH of keys with gamma (ratio of bit array size to number
+// of keys, at least 1).  Larger gamma makes construction and lookup faster
+// and uses more memory.  Keys must be distinct.
+func Build(keys [][]byte, gamma float64, seed uint64) (*MPH, error) {
+	return build(len(keys), func(i int, seed uint64) uint64 {
+		return circlehash.Hash64(keys[i], seed)
+	}, gamma, runtime.GOMAXPROCS(0), MaxLevels, seed)
+}
+
+// BuildStrings returns MPH of keys with gamma (ratio of bit array size to
+// number of keys, at least 1).  Keys must be distinct.
+func BuildStrings(keys []string, gamma float64, seed uint64) (*MPH, error) {
+	return build(len(keys), func(i int, seed uint64) uint64 {
+		return circlehash.Hash64String(keys[i], seed)
+	}, gamma, runtime.GOMAXPROCS(0), MaxLevels, seed)
+}
+
+// Lookup returns index of key b in [0, Len()).  For keys not in set,
+// Lookup returns -1 or an arbitrary index.
+func (m *MPH) Lookup(b []byte) int {
+	for i := range m.levels {
+		l := &m.levels[i]
+		if p, ok := m.position(l, circlehash.Hash64(b, l.seed)); ok {
+			return int(m.rank(p))
+		}
+	}
+	return -1
+}
+
+// LookupString returns index of key s in [0, Len()).  For keys not in set,
+// LookupString returns -1 or an arbitrary index.
+func (m *MPH) LookupString(s string) int {
+	for i := range m.levels {
+		l := &m.levels[i]
+		if p, ok := m.position(l, circlehash.Hash64String(s, l.seed)); ok {
+			return int(m.rank(p))
+		}
+	}
+	return -1
+}
+
+// Len returns number of keys.
+func (m *MPH) Len() int {
+	return int(m.n)
+}
+
+// Seed returns seed used by m, which is derived from seed passed to Build.
+func (m *MPH) Seed() uint64 {
+	return m.seed
+}
+
+// Levels returns number of levels.
+func (m *MPH) Levels() int {
+	return len(m.levels)
+}
+
+// BitsPerKey returns memory used by bit arrays and rank samples in bits
+// per key.
+func (m *MPH) BitsPerKey() float64 {
+	if m.n == 0 {
+		return 0
+	}
+	return float64(64*(len(m.bits)+len(m.ranks))) / float64(m.n)
+}
+
+// position returns position of digest h in l, and true if bit is set.
+func (m *MPH) position(l *level, h uint64) (uint64, bool) {
+	hi, _ := bits.Mul64(h, l.size)
+	p := l.offset + hi
+	return p, m.bits[p/64]&(1<<(p%64)) != 0
+}
+
+// rank returns number of set bits before position p.
+func (m *MPH) rank(p uint64) uint64 {
+	w := p / 64
+	r := m.ranks[w/wordsPerRankSample]
+	for i := w &^ (wordsPerRankSample - 1); i < w; i++ {
+		r += uint64(bits.OnesCount64(m.bits[i]))
+	}
+	return r + uint64(bits.OnesCount64(m.bits[w]&(1<<(p%64)-1)))
+}
+
+func (m *MPH) computeRanks() {
+	m.ranks = make([]uint64, (len(m.bits)+wordsPerRankSample-1)/wordsPerRankSample)
+	r := uint64(0)
+	for i, w := range m.bits {
+		if i%wordsPerRankSample == 0 {
+			m.ranks[i/wordsPerRankSample] = r
+		}
+		r += uint64(bits.OnesCount64(w))
+	}
+}
+
+func levelSeed(seed uint64, l int) uint64 {
+	return circlehash.Hash64Uint64x2(seed, uint64(l), seed)
+}
+
+func levelSize(n int, gamma float64) uint64 {
+	size := uint64(math.Ceil(gamma * float64(n)))
+	if size < 64 {
+		size = 64
+	}
+	return (size + 63) &^ 63
+}
+
+func build(n int, hash func(i int, seed uint64) uint64, gamma float64, workers int, maxLevels int, baseSeed uint64) (*MPH, error) {
+	if !(gamma >= 1) || math.IsInf(gamma, 1) {
+		return nil, fmt.Errorf("mph: gamma %v is less than 1", gamma)
+	}
+	if uint64(n) > MaxKeys {
+		return nil, fmt.Errorf("mph: number of keys %d exceeds %d", n, uint64(MaxKeys))
+	}
+	if workers < 1 {
+		workers = 1
+	}
+
+	for attempt := uint64(0); attempt < MaxAttempts; attempt++ {
+		seed := circlehash.Hash64Uint64x2(baseSeed, attempt, baseSeed)
+
+		m, remaining := buildLevels(n, hash, gamma, workers, maxLevels, seed)
+		if len(remaining) == 0 {
+			m.computeRanks()
+			return m, nil
+		}
+
+		// Keys with the same digest at every level are likely duplicates.
+		if hasDuplicateDigests(remaining, hash, seed) {
+			return nil, ErrDuplicateKeys
+		}
+	}
+
+	return nil, ErrConstructionFailed
+}
+
+// buildLevels builds levels of MPH and returns indices of keys that
+// weren't placed in maxLevels levels.
+func buildLevels(n int, hash func(i int, seed uint64) uint64, gamma float64, workers int, maxLevels int, seed uint64) (*MPH, []uint32) {
+	m := &MPH{seed: seed, n: uint64(n)}
+
+	remaining := make([]uint32, n)
+	for i := range remaining {
+		remaining[i] = uint32(i)
+	}
+	positions := make([]uint64, n)
+
+	for l := 0; l < maxLevels && len(remaining) > 0; l++ {
+		lv := level{
+			seed:   levelSeed(seed, l),
+			offset: uint64(len(m.bits)) * 64,
+			size:   levelSize(len(remaining), gamma),
+		}
+		m.levels = append(m.levels, lv)
+
+		seen := make([]uint64, lv.size/64)
+		collided := make([]uint64, lv.size/64)
+		positions = positions[:len(remaining)]
+
+		// Mark positions of remaining keys, and positions hit by more than
+		// one key as collided.  Result doesn't depend on order of keys.
+		parallel(len(remaining), workers, func(start, end int) {
+			for i := start; i < end; i++ {
+				p, _ := bits.Mul64(hash(int(remaining[i]), lv.seed), lv.size)
+				positions[i] = p
+				if setBit(seen, p) {
+					setBit(collided, p)
+				}
+			}
+		})
+
+		// Keep keys at collided positions for next level, in original order.
+		next := remaining[:0]
+		for i, k := range remaining {
+			p := positions[i]
+			if collided[p/64]&(1<<(p%64)) != 0 {
+				next = append(next, k)
+			}
+		}
+		remaining = next
+
+		for i := range seen {
+			seen[i] &^= collided[i]
+		}
+		m.bits = append(m.bits, seen...)
+	}
+
+	return m, remaining
+}
+
+// parallel calls fn with ranges of [0, n) in up to workers goroutines.
+func parallel(n int, workers int, fn func(start, end int)) {
+	if maxWorkers := (n + minKeysPerWorker - 1) / minKeysPerWorker; workers > maxWorkers {
+		workers = maxWorkers
+	}
+	if workers <= 1 {
+		fn(0, n)
+		return
+	}
+
+	var wg sync.WaitGroup
+	chunk := (n + workers - 1) / workers
+	for start := 0; start < n; start += chunk {
+		end := start + chunk
+		if end > n {
+			end = n
+		}
+		wg.Add(1)
+		go func(start, end int) {
+			defer wg.Done()
+			fn(start, end)
+		}(start, end)
+	}
+	wg.Wait()
+}
+
+// setBit atomically sets bit p in words and returns true if it was already set.
+func setBit(words []uint64, p uint64) bool {
+	w := &words[p/64]
+	mask := uint64(1) << (p % 64)
+	for {
+		old := atomic.LoadUint64(w)
+		if old&mask != 0 {
+			return true
+		}
+		if atomic.CompareAndSwapUint64(w, old, old|mask) {
+			return false
+		}
+	}
+}
+
+// hasDuplicateDigests returns true if any two keys have the same digests
+// at two independent seeds.
+func hasDuplicateDigests(keys []uint32, hash func(i int, seed uint64) uint64, seed uint64) bool {
+	type digests struct{ h1, h2 uint64 }
+
+	s1 := circlehash.Hash64Uint64x2(seed, 1, ^seed)
+	s2 := circlehash.Hash64Uint64x2(seed, 2, ^seed)
+
+	ds := make([]digests, len(keys))
+	for i, k := range keys {
+		ds[i] = digests{hash(int(k), s1), hash(int(k), s2)}
+	}
+	sort.Slice(ds, func(i, j int) bool {
+		if ds[i].h1 != ds[j].h1 {
+			return ds[i].h1 < ds[j].h1
+		}
+		return ds[i].h2 < ds[j].h2
+	})
+	for i := 1; i < len(ds); i++ {
+		if ds[i] == ds[i-1] {
+			return true
+		}
+	}
+	return false
+}
+
+// MarshalBinary returns serialized m.  Serialized MPHF records format
+// version, algorithm, seed, level sizes, and bit arrays.  Rank samples
+// aren't serialized.
+func (m *MPH) MarshalBinary() ([]byte, error) {
+	data := make([]byte, headerSize+8*len(m.levels)+8*len(m.bits))
+	copy(data, magic[:])
+	data[4] = formatVersion
+	data[5] = algorithmCircleHash64fBBHash
+	binary.LittleEndian.PutUint64(data[8:], m.n)
+	binary.LittleEndian.PutUint64(data[16:], m.seed)
+	binary.LittleEndian.PutUint32(data[24:], uint32(len(m.levels)))
+
+	off := headerSize
+	for _, l := range m.levels {
+		binary.LittleEndian.PutUint64(data[off:], l.size)
+		off += 8
+	}
+	for _, w := range m.bits {
+		binary.LittleEndian.PutUint64(data[off:], w)
+		off += 8
+	}
+	return data, nil
+}
+
+// UnmarshalBinary decodes MPHF serialized by MarshalBinary into m.
+func (m *MPH) UnmarshalBinary(data []byte) error {
+	if len(data) < headerSize || [4]byte{data[0], data[1], data[2], data[3]} != magic {
+		return ErrInvalidData
+	}
+	if data[4] != formatVersion {
+		return fmt.Errorf("mph: unsupported format version %d", data[4])
+	}
+	if data[5] != algorithmCircleHash64fBBHash {
+		return fmt.Errorf("mph: unsupported algorithm %d", data[5])
+	}
+
+	t := &MPH{
+		n:    binary.LittleEndian.Uint64(data[8:]),
+		seed: binary.LittleEndian.Uint64(data[16:]),
+	}
+	numLevels := binary.LittleEndian.Uint32(data[24:])
+	if t.n > MaxKeys || numLevels > MaxLevels || (numLevels == 0) != (t.n == 0) {
+		return ErrInvalidData
+	}
+
+	data = data[headerSize:]
+	if uint64(len(data)) < 8*uint64(numLevels) {
+		return ErrInvalidData
+	}
+	t.levels = make([]level, numLevels)
+	offset := uint64(0)
+	for i := range t.levels {
+		size := binary.LittleEndian.Uint64(data[8*i:])
+		if size == 0 || size%64 != 0 || size > uint64(len(data))*8 {
+			return ErrInvalidData
+		}
+		t.levels[i] = level{seed: levelSeed(t.seed, i), offset: offset, size: size}
+		offset += size
+	}
+
+	data = data[8*numLevels:]
+	if uint64(len(data)) != offset/8 {
+		return ErrInvalidData
+	}
+	t.bits = make([]uint64, len(data)/8)
+	count := uint64(0)
+	for i := range t.bits {
+		t.bits[i] = binary.LittleEndian.Uint64(data[8*i:])
+		count += uint64(bits.OnesCount64(t.bits[i]))
+	}
+	if count != t.n {
+		return ErrInvalidData
+	}
+
+	t.computeRanks()
+	*m = *t
+	return nil
+}
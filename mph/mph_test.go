@@ -0,0 +1,291 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mph
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/fxamacker/circlehash"
+)
+
+const testSeed = uint64(0x9E3779B97F4A7C15)
+
+func testKeys(n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = "key" + strconv.Itoa(i)
+	}
+	return keys
+}
+
+func buildTestMPH(t testing.TB, keys []string, workers int, maxLevels int) *MPH {
+	t.Helper()
+	m, err := build(len(keys), func(i int, seed uint64) uint64 {
+		return circlehash.Hash64String(keys[i], seed)
+	}, DefaultGamma, workers, maxLevels, testSeed)
+	if err != nil {
+		t.Fatalf("build() returned error %v", err)
+	}
+	return m
+}
+
+// checkMinimalPerfect checks that keys are mapped to distinct indices in [0, len(keys)).
+func checkMinimalPerfect(t *testing.T, m *MPH, keys []string) {
+	t.Helper()
+	if m.Len() != len(keys) {
+		t.Fatalf("Len() = %d; want %d", m.Len(), len(keys))
+	}
+	used := make([]bool, len(keys))
+	for _, k := range keys {
+		i := m.LookupString(k)
+		if i < 0 || i >= len(keys) {
+			t.Fatalf("LookupString(%q) = %d; want index in [0, %d)", k, i, len(keys))
+		}
+		if used[i] {
+			t.Fatalf("LookupString(%q) = %d is used by another key", k, i)
+		}
+		used[i] = true
+
+		if j := m.Lookup([]byte(k)); j != i {
+			t.Fatalf("Lookup(%q) = %d; LookupString() = %d", k, j, i)
+		}
+	}
+}
+
+func TestBuild(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 10, 1000, 100000} {
+		t.Run(strconv.Itoa(n), func(t *testing.T) {
+			keys := testKeys(n)
+			m, err := BuildStrings(keys, DefaultGamma, testSeed)
+			if err != nil {
+				t.Fatalf("BuildStrings() returned error %v", err)
+			}
+			checkMinimalPerfect(t, m, keys)
+
+			bkeys := make([][]byte, n)
+			for i, k := range keys {
+				bkeys[i] = []byte(k)
+			}
+			m2, err := Build(bkeys, DefaultGamma, testSeed)
+			if err != nil {
+				t.Fatalf("Build() returned error %v", err)
+			}
+			for _, k := range keys {
+				if m.LookupString(k) != m2.LookupString(k) {
+					t.Fatalf("Build() and BuildStrings() map %q to different indices", k)
+				}
+			}
+		})
+	}
+}
+
+func TestGolden(t *testing.T) {
+	// Indices must not change between versions.
+	keys := []string{"apple", "banana", "cherry", "date", "elderberry", "fig", "grape"}
+	m, err := BuildStrings(keys, DefaultGamma, testSeed)
+	if err != nil {
+		t.Fatalf("BuildStrings() returned error %v", err)
+	}
+
+	want := map[string]int{
+		"apple":      2,
+		"banana":     6,
+		"cherry":     4,
+		"date":       0,
+		"elderberry": 5,
+		"fig":        3,
+		"grape":      1,
+	}
+	for k, i := range want {
+		if got := m.LookupString(k); got != i {
+			t.Errorf("LookupString(%q) = %d; want %d", k, got, i)
+		}
+	}
+	if m.Seed() != circlehash.Hash64Uint64x2(testSeed, 0, testSeed) {
+		t.Errorf("Seed() = 0x%016x; want seed of first attempt", m.Seed())
+	}
+}
+
+func TestBuildInvalid(t *testing.T) {
+	for _, gamma := range []float64{0, 0.5, -1} {
+		if _, err := BuildStrings(testKeys(10), gamma, testSeed); err == nil {
+			t.Errorf("BuildStrings() with gamma %v didn't return error", gamma)
+		}
+	}
+}
+
+func TestBuildDuplicates(t *testing.T) {
+	keys := append(testKeys(1000), "key500")
+	if _, err := BuildStrings(keys, DefaultGamma, testSeed); !errors.Is(err, ErrDuplicateKeys) {
+		t.Errorf("BuildStrings() with duplicate keys returned error %v; want %v", err, ErrDuplicateKeys)
+	}
+}
+
+func TestParallelDeterministic(t *testing.T) {
+	// Result doesn't depend on number of workers.
+	keys := testKeys(200000)
+
+	m1 := buildTestMPH(t, keys, 1, MaxLevels)
+	want, _ := m1.MarshalBinary()
+	for _, workers := range []int{2, 3, 8} {
+		m := buildTestMPH(t, keys, workers, MaxLevels)
+		got, _ := m.MarshalBinary()
+		if !bytes.Equal(got, want) {
+			t.Errorf("build() with %d workers differs from build() with 1 worker", workers)
+		}
+	}
+}
+
+func TestRetryDeterministic(t *testing.T) {
+	// With 1 level of 64 bits, 12 keys usually need retries.
+	keys := testKeys(12)
+
+	m1 := buildTestMPH(t, keys, 1, 1)
+	m2 := buildTestMPH(t, keys, 1, 1)
+	if m1.Seed() != m2.Seed() {
+		t.Errorf("Seed() = 0x%016x and 0x%016x; want the same seed", m1.Seed(), m2.Seed())
+	}
+	checkMinimalPerfect(t, m1, keys)
+
+	found := false
+	for attempt := uint64(0); attempt < MaxAttempts; attempt++ {
+		if m1.Seed() == circlehash.Hash64Uint64x2(testSeed, attempt, testSeed) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Errorf("Seed() = 0x%016x isn't derived from base seed", m1.Seed())
+	}
+}
+
+func TestConstructionFailed(t *testing.T) {
+	// With 1 level, 1000 keys can't be placed.
+	keys := testKeys(1000)
+	_, err := build(len(keys), func(i int, seed uint64) uint64 {
+		return circlehash.Hash64String(keys[i], seed)
+	}, DefaultGamma, 1, 1, testSeed)
+	if !errors.Is(err, ErrConstructionFailed) {
+		t.Errorf("build() returned error %v; want %v", err, ErrConstructionFailed)
+	}
+}
+
+func TestBitsPerKey(t *testing.T) {
+	m := buildTestMPH(t, testKeys(100000), 4, MaxLevels)
+	if bpk := m.BitsPerKey(); bpk > 4.5 {
+		t.Errorf("BitsPerKey() = %v; want at most 4.5", bpk)
+	}
+}
+
+func TestLookupMissing(t *testing.T) {
+	m := buildTestMPH(t, testKeys(1000), 1, MaxLevels)
+	for i := 0; i < 1000; i++ {
+		k := "missing" + strconv.Itoa(i)
+		if got := m.LookupString(k); got < -1 || got >= m.Len() {
+			t.Fatalf("LookupString(%q) = %d; want -1 or index in [0, %d)", k, got, m.Len())
+		}
+	}
+
+	var empty MPH
+	if got := empty.LookupString("key"); got != -1 {
+		t.Errorf("LookupString() of empty MPH = %d; want -1", got)
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	for _, n := range []int{0, 1, 1000} {
+		keys := testKeys(n)
+		m := buildTestMPH(t, keys, 1, MaxLevels)
+
+		data, err := m.MarshalBinary()
+		if err != nil {
+			t.Fatalf("MarshalBinary() returned error %v", err)
+		}
+
+		var m2 MPH
+		if err := m2.UnmarshalBinary(data); err != nil {
+			t.Fatalf("UnmarshalBinary() returned error %v", err)
+		}
+		if m2.Len() != n || m2.Seed() != m.Seed() || m2.Levels() != m.Levels() {
+			t.Errorf("UnmarshalBinary() = (%d keys, seed 0x%x, %d levels); want (%d, 0x%x, %d)",
+				m2.Len(), m2.Seed(), m2.Levels(), n, m.Seed(), m.Levels())
+		}
+		for _, k := range keys {
+			if m2.LookupString(k) != m.LookupString(k) {
+				t.Fatalf("LookupString(%q) after UnmarshalBinary() = %d; want %d", k, m2.LookupString(k), m.LookupString(k))
+			}
+		}
+	}
+}
+
+func TestUnmarshalBinaryInvalid(t *testing.T) {
+	m := buildTestMPH(t, testKeys(1000), 1, MaxLevels)
+	valid, _ := m.MarshalBinary()
+
+	modify := func(fn func(b []byte)) []byte {
+		b := append([]byte(nil), valid...)
+		fn(b)
+		return b
+	}
+
+	testCases := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"short header", valid[:headerSize-1]},
+		{"bad magic", modify(func(b []byte) { b[0] = 'X' })},
+		{"bad version", modify(func(b []byte) { b[4] = 2 })},
+		{"bad algorithm", modify(func(b []byte) { b[5] = 0 })},
+		{"bad number of keys", modify(func(b []byte) { b[8]++ })},
+		{"too many keys", modify(func(b []byte) { b[15] = 1 })},
+		{"too many levels", modify(func(b []byte) { b[24] = MaxLevels + 1 })},
+		{"zero levels", modify(func(b []byte) { b[24] = 0 })},
+		{"bad level size", modify(func(b []byte) { b[headerSize]++ })},
+		{"level size too large", modify(func(b []byte) { b[headerSize+1]++ })},
+		{"bad bits", modify(func(b []byte) { b[len(b)-1] ^= 0x80 })},
+		{"truncated", valid[:len(valid)-1]},
+		{"extra data", append(append([]byte(nil), valid...), 0)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var m MPH
+			if err := m.UnmarshalBinary(tc.data); err == nil {
+				t.Error("UnmarshalBinary() didn't return error")
+			}
+		})
+	}
+}
+
+func BenchmarkBuild(b *testing.B) {
+	keys := testKeys(1000000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = BuildStrings(keys, DefaultGamma, testSeed)
+	}
+}
+
+func BenchmarkLookup(b *testing.B) {
+	keys := testKeys(1000000)
+	m, _ := BuildStrings(keys, DefaultGamma, testSeed)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = m.LookupString(keys[i%len(keys)])
+	}
+}
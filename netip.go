@@ -0,0 +1,91 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file is for Go versions >= 1.18, which added net/netip.
+//go:build go1.18
+// +build go1.18
+
+package circlehash
+
+import (
+	"encoding/binary"
+	"net/netip"
+)
+
+// FlowKey is a network flow 5-tuple.
+type FlowKey struct {
+	Src     netip.Addr
+	Dst     netip.Addr
+	SrcPort uint16
+	DstPort uint16
+	Proto   uint8
+}
+
+// addrWords returns 16-byte form of addr as two little-endian words.
+func addrWords(addr netip.Addr) (uint64, uint64) {
+	b := addr.As16()
+	return binary.LittleEndian.Uint64(b[:8]), binary.LittleEndian.Uint64(b[8:])
+}
+
+// HashAddr returns a 64-bit digest of addr.
+// Digest is compatible with Hash64 with addr.As16(), so IPv4 address and
+// its IPv4-mapped IPv6 address have the same digest.  Zone is ignored.
+func HashAddr(addr netip.Addr, seed uint64) uint64 {
+	a, b := addrWords(addr)
+	return circle64fUint64x2(a, b, seed)
+}
+
+// HashAddrPort returns a 64-bit digest of ap.
+// Digest is compatible with Hash64 with 24 bytes containing
+// ap.Addr().As16() followed by port as little-endian uint64.
+func HashAddrPort(ap netip.AddrPort, seed uint64) uint64 {
+	a, b := addrWords(ap.Addr())
+	words := [3]uint64{a, b, uint64(ap.Port())}
+	return circle64fUint64s(words[:], seed)
+}
+
+// HashFlow returns a 64-bit digest of flow.
+// Digest is compatible with Hash64 with 40 bytes containing
+// flow.Src.As16(), flow.Dst.As16(), and little-endian uint64 of
+// SrcPort | DstPort<<16 | Proto<<32.
+func HashFlow(flow FlowKey, seed uint64) uint64 {
+	srcA, srcB := addrWords(flow.Src)
+	dstA, dstB := addrWords(flow.Dst)
+	return hashFlowWords(srcA, srcB, dstA, dstB, flow.SrcPort, flow.DstPort, flow.Proto, seed)
+}
+
+// HashFlowSymmetric returns a 64-bit digest of flow that is the same for
+// both directions of flow, so packets from A to B and from B to A have
+// the same digest.  Digest is HashFlow of flow with source and destination
+// swapped if source is greater than destination.
+func HashFlowSymmetric(flow FlowKey, seed uint64) uint64 {
+	srcA, srcB := addrWords(flow.Src)
+	dstA, dstB := addrWords(flow.Dst)
+	srcPort, dstPort := flow.SrcPort, flow.DstPort
+
+	if srcA > dstA || (srcA == dstA && (srcB > dstB || (srcB == dstB && srcPort > dstPort))) {
+		srcA, srcB, dstA, dstB = dstA, dstB, srcA, srcB
+		srcPort, dstPort = dstPort, srcPort
+	}
+	return hashFlowWords(srcA, srcB, dstA, dstB, srcPort, dstPort, flow.Proto, seed)
+}
+
+func hashFlowWords(srcA, srcB, dstA, dstB uint64, srcPort, dstPort uint16, proto uint8, seed uint64) uint64 {
+	words := [5]uint64{
+		srcA, srcB,
+		dstA, dstB,
+		uint64(srcPort) | uint64(dstPort)<<16 | uint64(proto)<<32,
+	}
+	return circle64fUint64s(words[:], seed)
+}
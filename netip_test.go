@@ -0,0 +1,172 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.18
+// +build go1.18
+
+package circlehash
+
+import (
+	"encoding/binary"
+	"net/netip"
+	"testing"
+)
+
+var netipTestAddrs = []string{
+	"0.0.0.0",
+	"1.2.3.4",
+	"192.168.1.1",
+	"255.255.255.255",
+	"::",
+	"::1",
+	"2001:db8::1",
+	"fe80::1%eth0",
+	"ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff",
+}
+
+func TestHashAddr(t *testing.T) {
+	for _, seed := range []uint64{numsAllZeros, numsAllFFs, numsGoldenRatio} {
+		for _, s := range netipTestAddrs {
+			addr := netip.MustParseAddr(s)
+			b := addr.As16()
+
+			got := HashAddr(addr, seed)
+			want := Hash64(b[:], seed)
+			if got != want {
+				t.Errorf("HashAddr(%s, 0x%x) = 0x%016x; want 0x%016x", addr, seed, got, want)
+			}
+		}
+	}
+
+	// IPv4 address and IPv4-mapped IPv6 address have the same digest.
+	v4 := netip.MustParseAddr("1.2.3.4")
+	v6 := netip.MustParseAddr("::ffff:1.2.3.4")
+	if HashAddr(v4, numsGoldenRatio) != HashAddr(v6, numsGoldenRatio) {
+		t.Errorf("HashAddr(%s) != HashAddr(%s)", v4, v6)
+	}
+}
+
+func TestHashAddrPort(t *testing.T) {
+	for _, seed := range []uint64{numsAllZeros, numsAllFFs, numsGoldenRatio} {
+		for _, s := range netipTestAddrs {
+			for _, port := range []uint16{0, 80, 443, 65535} {
+				ap := netip.AddrPortFrom(netip.MustParseAddr(s), port)
+
+				var b [24]byte
+				addr := ap.Addr().As16()
+				copy(b[:], addr[:])
+				binary.LittleEndian.PutUint64(b[16:], uint64(port))
+
+				got := HashAddrPort(ap, seed)
+				want := Hash64(b[:], seed)
+				if got != want {
+					t.Errorf("HashAddrPort(%s, 0x%x) = 0x%016x; want 0x%016x", ap, seed, got, want)
+				}
+			}
+		}
+	}
+}
+
+func netipTestFlows() []FlowKey {
+	return []FlowKey{
+		{},
+		{netip.MustParseAddr("10.0.0.1"), netip.MustParseAddr("10.0.0.2"), 12345, 80, 6},
+		{netip.MustParseAddr("10.0.0.2"), netip.MustParseAddr("10.0.0.1"), 80, 12345, 6},
+		{netip.MustParseAddr("10.0.0.1"), netip.MustParseAddr("10.0.0.2"), 12345, 80, 17},
+		{netip.MustParseAddr("10.0.0.1"), netip.MustParseAddr("10.0.0.1"), 1, 2, 6},
+		{netip.MustParseAddr("2001:db8::1"), netip.MustParseAddr("2001:db8::2"), 443, 50000, 6},
+		{netip.MustParseAddr("2001:db8::1"), netip.MustParseAddr("2001:db8:1::1"), 443, 50000, 6},
+	}
+}
+
+func TestHashFlow(t *testing.T) {
+	for _, seed := range []uint64{numsAllZeros, numsAllFFs, numsGoldenRatio} {
+		for _, flow := range netipTestFlows() {
+			var b [40]byte
+			src, dst := flow.Src.As16(), flow.Dst.As16()
+			copy(b[:], src[:])
+			copy(b[16:], dst[:])
+			binary.LittleEndian.PutUint64(b[32:], uint64(flow.SrcPort)|uint64(flow.DstPort)<<16|uint64(flow.Proto)<<32)
+
+			got := HashFlow(flow, seed)
+			want := Hash64(b[:], seed)
+			if got != want {
+				t.Errorf("HashFlow(%v, 0x%x) = 0x%016x; want 0x%016x", flow, seed, got, want)
+			}
+		}
+	}
+}
+
+func TestHashFlowDistinct(t *testing.T) {
+	seen := make(map[uint64]FlowKey)
+	for _, flow := range netipTestFlows() {
+		h := HashFlow(flow, numsGoldenRatio)
+		if prev, ok := seen[h]; ok {
+			t.Errorf("HashFlow(%v) == HashFlow(%v)", flow, prev)
+		}
+		seen[h] = flow
+	}
+}
+
+func TestHashFlowSymmetric(t *testing.T) {
+	for _, flow := range netipTestFlows() {
+		reversed := FlowKey{flow.Dst, flow.Src, flow.DstPort, flow.SrcPort, flow.Proto}
+
+		h1 := HashFlowSymmetric(flow, numsGoldenRatio)
+		h2 := HashFlowSymmetric(reversed, numsGoldenRatio)
+		if h1 != h2 {
+			t.Errorf("HashFlowSymmetric(%v) = 0x%016x; HashFlowSymmetric(%v) = 0x%016x", flow, h1, reversed, h2)
+		}
+
+		// Digest is HashFlow of one of the directions.
+		if h1 != HashFlow(flow, numsGoldenRatio) && h1 != HashFlow(reversed, numsGoldenRatio) {
+			t.Errorf("HashFlowSymmetric(%v) isn't HashFlow of either direction", flow)
+		}
+	}
+
+	// Different protocols and ports have different digests.
+	flows := netipTestFlows()
+	if HashFlowSymmetric(flows[1], numsGoldenRatio) == HashFlowSymmetric(flows[3], numsGoldenRatio) {
+		t.Error("HashFlowSymmetric() of flows with different protocols are equal")
+	}
+}
+
+func TestNetipAllocs(t *testing.T) {
+	flow := netipTestFlows()[5]
+	ap := netip.AddrPortFrom(flow.Src, flow.SrcPort)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		_ = HashAddr(flow.Src, numsGoldenRatio)
+		_ = HashAddrPort(ap, numsGoldenRatio)
+		_ = HashFlow(flow, numsGoldenRatio)
+		_ = HashFlowSymmetric(flow, numsGoldenRatio)
+	})
+	if allocs != 0 {
+		t.Errorf("netip hash functions allocated %v times; want 0", allocs)
+	}
+}
+
+func BenchmarkHashFlow(b *testing.B) {
+	flow := netipTestFlows()[5]
+	for i := 0; i < b.N; i++ {
+		_ = HashFlow(flow, numsGoldenRatio)
+	}
+}
+
+func BenchmarkHashFlowSymmetric(b *testing.B) {
+	flow := netipTestFlows()[5]
+	for i := 0; i < b.N; i++ {
+		_ = HashFlowSymmetric(flow, numsGoldenRatio)
+	}
+}
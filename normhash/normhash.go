@@ -0,0 +1,122 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package normhash provides string hashers that normalize strings to
+// Unicode Normalization Form C while hashing, so canonically equivalent
+// strings, such as "Cafe\u0301" and "Caf\u00e9", have the same digest.
+//
+// It's a separate package because normalization requires
+// golang.org/x/text/unicode/norm, and package circlehash has no
+// dependencies.
+package normhash
+
+import (
+	"sync"
+	"unicode/utf8"
+	"unsafe"
+
+	"github.com/fxamacker/circlehash"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Hash64String returns a 64-bit digest of s normalized to Unicode
+// Normalization Form C, without allocating a normalized copy of s.
+// Digest is the same as circlehash.Hash64String of norm.NFC.String(s).
+func Hash64String(s string, seed uint64) uint64 {
+	if norm.NFC.QuickSpanString(s) == len(s) {
+		// Already normalized.
+		return circlehash.Hash64String(s, seed)
+	}
+
+	d := circlehash.NewDigest64(seed)
+
+	it := iterPool.Get().(*norm.Iter)
+	it.InitString(norm.NFC, s)
+	for !it.Done() {
+		d.Write(it.Next())
+	}
+	iterPool.Put(it)
+	return d.Sum64()
+}
+
+// Hash64StringFold returns a 64-bit digest of s normalized to Unicode
+// Normalization Form C and then case folded, without allocating a
+// normalized or folded copy of s.  Digest is the same as
+// circlehash.Hash64StringFold of norm.NFC.String(s).
+//
+// Strings that differ only by case and by canonically equivalent
+// sequences, such as "Cafe\u0301" and "CAF\u00c9", have the same digest.
+func Hash64StringFold(s string, seed uint64) uint64 {
+	if norm.NFC.QuickSpanString(s) == len(s) {
+		// Already normalized.
+		return circlehash.Hash64StringFold(s, seed)
+	}
+
+	d := circlehash.NewDigest64(seed)
+
+	w := foldWriter{d: d}
+	it := iterPool.Get().(*norm.Iter)
+	it.InitString(norm.NFC, s)
+	for !it.Done() {
+		// Segments are whole runes, so they can be folded separately.
+		seg := it.Next()
+		w.writeString(*(*string)(unsafe.Pointer(&seg)))
+	}
+	iterPool.Put(it)
+	w.flush()
+	return d.Sum64()
+}
+
+// iterPool reuses norm.Iter, which is too large to allocate on the stack.
+var iterPool = sync.Pool{
+	New: func() interface{} { return new(norm.Iter) },
+}
+
+// foldWriter folds runes into buf and writes buf to digest when it's
+// nearly full.
+type foldWriter struct {
+	d   *circlehash.Digest64
+	buf [64]byte
+	n   int
+}
+
+func (w *foldWriter) writeString(s string) {
+	for i := 0; i < len(s); {
+		if w.n > len(w.buf)-utf8.UTFMax {
+			w.flush()
+		}
+
+		c := s[i]
+		if c < utf8.RuneSelf {
+			w.buf[w.n] = byte(circlehash.FoldRune(rune(c)))
+			w.n++
+			i++
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError {
+			// Invalid UTF-8 byte or U+FFFD is unchanged.
+			w.n += copy(w.buf[w.n:], s[i:i+size])
+		} else {
+			w.n += utf8.EncodeRune(w.buf[w.n:], circlehash.FoldRune(r))
+		}
+		i += size
+	}
+}
+
+func (w *foldWriter) flush() {
+	w.d.Write(w.buf[:w.n])
+	w.n = 0
+}
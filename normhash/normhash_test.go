@@ -0,0 +1,143 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package normhash
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/fxamacker/circlehash"
+	"golang.org/x/text/unicode/norm"
+)
+
+const testSeed = uint64(0x9E3779B97F4A7C15)
+
+var testStrings = []string{
+	"",
+	"a",
+	"Example.COM",
+	"ALL UPPER CASE STRING THAT IS LONGER THAN SIXTY-FOUR BYTES TO TEST CHUNKS",
+	"Stra\u00dfe",
+	"\u03a3\u038a\u03a3\u03a5\u03a6\u039f\u03a3",
+	"Kelvin \u212a",
+	"\xff\xfeInvalid UTF-8 \xc3",
+	"\ufffd replacement char",
+	"Cafe\u0301",
+	"CAF\u00c9",
+	"A\u030a\u0301 \u212b",
+	"\u1e9b\u0323",
+	"\u0071\u0307\u0323",
+	"\ud55c\uad6d\uc5b4 \u1112\u1161\u11ab",
+	strings.Repeat("E\u0301", 40),
+	"\u0301 leading combining mark",
+	"\xcc\x81\xff invalid after combining mark e\u0301",
+}
+
+// foldString returns s with each rune folded by circlehash.FoldRune
+// and invalid UTF-8 bytes unchanged.
+func foldString(s string) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError {
+			sb.WriteString(s[i : i+size])
+		} else {
+			sb.WriteRune(circlehash.FoldRune(r))
+		}
+		i += size
+	}
+	return sb.String()
+}
+
+func TestHash64String(t *testing.T) {
+	for _, seed := range []uint64{0, 0xFFFFFFFFFFFFFFFF, testSeed} {
+		for _, s := range testStrings {
+			got := Hash64String(s, seed)
+			want := circlehash.Hash64String(norm.NFC.String(s), seed)
+			if got != want {
+				t.Errorf("Hash64String(%q, 0x%x) = 0x%016x; want 0x%016x", s, seed, got, want)
+			}
+
+			got = Hash64StringFold(s, seed)
+			want = circlehash.Hash64String(foldString(norm.NFC.String(s)), seed)
+			if got != want {
+				t.Errorf("Hash64StringFold(%q, 0x%x) = 0x%016x; want 0x%016x", s, seed, got, want)
+			}
+
+			got = Hash64StringFold(s, seed)
+			want = circlehash.Hash64StringFold(norm.NFC.String(s), seed)
+			if got != want {
+				t.Errorf("Hash64StringFold(%q, 0x%x) = 0x%016x; want circlehash.Hash64StringFold = 0x%016x", s, seed, got, want)
+			}
+		}
+	}
+}
+
+func TestHash64StringEqual(t *testing.T) {
+	testCases := []struct {
+		s1   string
+		s2   string
+		fold bool
+	}{
+		{"Cafe\u0301", "Caf\u00e9", false},
+		{"A\u030a", "\u212b", false},
+		{"\u1e0b\u0323", "\u1e0d\u0307", false},
+		{"\u1112\u1161\u11ab", "\ud55c", false},
+		{"Cafe\u0301", "CAF\u00c9", true},
+		{"A\u030a", "\u00e5", true},
+	}
+
+	for _, tc := range testCases {
+		hash := Hash64String
+		name := "Hash64String"
+		if tc.fold {
+			hash = Hash64StringFold
+			name = "Hash64StringFold"
+		}
+
+		h1 := hash(tc.s1, testSeed)
+		h2 := hash(tc.s2, testSeed)
+		if h1 != h2 {
+			t.Errorf("%s(%q) = 0x%016x; %s(%q) = 0x%016x", name, tc.s1, h1, name, tc.s2, h2)
+		}
+	}
+}
+
+func TestHash64StringAllocs(t *testing.T) {
+	for _, s := range testStrings {
+		allocs := testing.AllocsPerRun(10, func() {
+			_ = Hash64String(s, testSeed)
+			_ = Hash64StringFold(s, testSeed)
+		})
+		if allocs != 0 {
+			t.Errorf("Hash64String(%q) allocated %v times; want 0", s, allocs)
+		}
+	}
+}
+
+func BenchmarkHash64String(b *testing.B) {
+	s := "Cafe\u0301 user.Name+Tag@Example.com"
+	for i := 0; i < b.N; i++ {
+		_ = Hash64String(s, testSeed)
+	}
+}
+
+func BenchmarkHash64StringFold(b *testing.B) {
+	s := "Cafe\u0301 user.Name+Tag@Example.com"
+	for i := 0; i < b.N; i++ {
+		_ = Hash64StringFold(s, testSeed)
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package circlehash
+
+import (
+	"math"
+	"sort"
+)
+
+// RendezvousNode is a node for rendezvous hashing.
+type RendezvousNode struct {
+	// ID identifies the node, and must be unique and stable.
+	ID uint64
+
+	// Weight is relative capacity of the node.  Nodes with weight
+	// less than or equal to 0 are never chosen.
+	Weight float64
+}
+
+// RendezvousScore returns weighted rendezvous (highest random weight)
+// score of node for key with digest keyDigest:
+//
+//	u = Hash64Uint64x2(keyDigest, node.ID, seed) mapped to (0, 1)
+//	score = -node.Weight / ln(u)
+//
+// RendezvousNode with the highest score is chosen, so each node is chosen for
+// fraction of keys proportional to its weight.  See "Weighted Distributed
+// Hash Tables" by Christian Schindelhauer and Gunnar Schomaker.
+func RendezvousScore(keyDigest uint64, node RendezvousNode, seed uint64) float64 {
+	if node.Weight <= 0 {
+		return 0
+	}
+	h := circle64fUint64x2(keyDigest, node.ID, seed)
+	u := (float64(h>>11) + 0.5) / (1 << 53)
+	return -node.Weight / math.Log(u)
+}
+
+// Rendezvous returns index of node in nodes with the highest score for
+// key with digest keyDigest.  Adding or removing a node only moves keys
+// to or from that node.  Ties are broken by the smaller node ID, so the
+// chosen node doesn't depend on order of nodes.  If no node has positive
+// weight, Rendezvous returns -1.
+func Rendezvous(keyDigest uint64, nodes []RendezvousNode, seed uint64) int {
+	best, bestScore := -1, 0.0
+	for i, node := range nodes {
+		score := RendezvousScore(keyDigest, node, seed)
+		if score > bestScore || (score == bestScore && best >= 0 && node.ID < nodes[best].ID) {
+			best, bestScore = i, score
+		}
+	}
+	return best
+}
+
+// RendezvousBytes returns Rendezvous(Hash64(b, seed), nodes, seed).
+func RendezvousBytes(b []byte, nodes []RendezvousNode, seed uint64) int {
+	return Rendezvous(Hash64(b, seed), nodes, seed)
+}
+
+// RendezvousString returns Rendezvous(Hash64String(s, seed), nodes, seed).
+func RendezvousString(s string, nodes []RendezvousNode, seed uint64) int {
+	return Rendezvous(Hash64String(s, seed), nodes, seed)
+}
+
+// RendezvousTop returns indexes of up to n nodes with the highest scores
+// for key with digest keyDigest, in descending order of score.  It can be
+// used to choose replicas.  Ties are broken by the smaller node ID, like
+// Rendezvous.  Nodes with weight less than or equal to 0 are excluded.
+func RendezvousTop(keyDigest uint64, nodes []RendezvousNode, n int, seed uint64) []int {
+	type scored struct {
+		index int
+		id    uint64
+		score float64
+	}
+
+	candidates := make([]scored, 0, len(nodes))
+	for i, node := range nodes {
+		if score := RendezvousScore(keyDigest, node, seed); score > 0 {
+			candidates = append(candidates, scored{i, node.ID, score})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].score != candidates[j].score {
+			return candidates[i].score > candidates[j].score
+		}
+		if candidates[i].id != candidates[j].id {
+			return candidates[i].id < candidates[j].id
+		}
+		return candidates[i].index < candidates[j].index
+	})
+
+	if n > len(candidates) {
+		n = len(candidates)
+	}
+	if n < 0 {
+		n = 0
+	}
+	top := make([]int, n)
+	for i := range top {
+		top[i] = candidates[i].index
+	}
+	return top
+}
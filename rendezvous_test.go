@@ -0,0 +1,181 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package circlehash
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func testNodes(weights ...float64) []RendezvousNode {
+	nodes := make([]RendezvousNode, len(weights))
+	for i, w := range weights {
+		nodes[i] = RendezvousNode{ID: uint64(1000 + i), Weight: w}
+	}
+	return nodes
+}
+
+func TestRendezvousUniform(t *testing.T) {
+	const numKeys = 100000
+
+	nodes := testNodes(1, 1, 1, 1, 1, 1, 1, 1)
+	counts := make([]int, len(nodes))
+	for i := 0; i < numKeys; i++ {
+		counts[RendezvousString("key"+strconv.Itoa(i), nodes, numsGoldenRatio)]++
+	}
+
+	expected := make([]float64, len(nodes))
+	for i := range expected {
+		expected[i] = float64(numKeys) / float64(len(nodes))
+	}
+	checkUniform(t, "equal weights", counts, expected)
+}
+
+func TestRendezvousWeighted(t *testing.T) {
+	const numKeys = 200000
+
+	nodes := testNodes(1, 2, 3, 4, 0, -1)
+	counts := make([]int, len(nodes))
+	for i := 0; i < numKeys; i++ {
+		counts[RendezvousBytes([]byte("key"+strconv.Itoa(i)), nodes, numsGoldenRatio)]++
+	}
+
+	expected := []float64{0.1 * numKeys, 0.2 * numKeys, 0.3 * numKeys, 0.4 * numKeys, 0, 0}
+	checkUniform(t, "weights", counts, expected)
+}
+
+func TestRendezvousMinimalMovement(t *testing.T) {
+	const numKeys = 50000
+
+	nodes := testNodes(1, 2, 1, 3, 1)
+
+	assignments := make([]uint64, numKeys)
+	for i := range assignments {
+		key := Hash64String(strconv.Itoa(i), numsGoldenRatio)
+		assignments[i] = nodes[Rendezvous(key, nodes, numsGoldenRatio)].ID
+	}
+
+	// Removing node 1 only moves its keys.
+	removed := append(append([]RendezvousNode(nil), nodes[:1]...), nodes[2:]...)
+	for i := range assignments {
+		key := Hash64String(strconv.Itoa(i), numsGoldenRatio)
+		after := removed[Rendezvous(key, removed, numsGoldenRatio)].ID
+		if after != assignments[i] && assignments[i] != nodes[1].ID {
+			t.Fatalf("key %d moved from node %d to %d when removing node %d", i, assignments[i], after, nodes[1].ID)
+		}
+	}
+
+	// Adding a node only moves keys to it.
+	added := append(append([]RendezvousNode(nil), nodes...), RendezvousNode{ID: 2000, Weight: 2})
+	moved := 0
+	for i := range assignments {
+		key := Hash64String(strconv.Itoa(i), numsGoldenRatio)
+		after := added[Rendezvous(key, added, numsGoldenRatio)].ID
+		if after != assignments[i] {
+			if after != 2000 {
+				t.Fatalf("key %d moved from node %d to %d when adding node 2000", i, assignments[i], after)
+			}
+			moved++
+		}
+	}
+	// New node has 2/10 of total weight.
+	checkUniform(t, "added node", []int{moved}, []float64{0.2 * numKeys})
+}
+
+func TestRendezvousNoNodes(t *testing.T) {
+	if got := Rendezvous(numsGoldenRatio, nil, numsGoldenRatio); got != -1 {
+		t.Errorf("Rendezvous() with no nodes = %d; want -1", got)
+	}
+	if got := Rendezvous(numsGoldenRatio, testNodes(0, -1), numsGoldenRatio); got != -1 {
+		t.Errorf("Rendezvous() with no positive weights = %d; want -1", got)
+	}
+}
+
+func TestRendezvousTop(t *testing.T) {
+	nodes := testNodes(1, 1, 1, 0, 1, 1)
+
+	for i := 0; i < 1000; i++ {
+		key := Hash64String(strconv.Itoa(i), numsGoldenRatio)
+
+		top := RendezvousTop(key, nodes, 3, numsGoldenRatio)
+		if len(top) != 3 {
+			t.Fatalf("RendezvousTop() returned %d nodes; want 3", len(top))
+		}
+		if top[0] != Rendezvous(key, nodes, numsGoldenRatio) {
+			t.Fatalf("RendezvousTop()[0] = %d; want %d", top[0], Rendezvous(key, nodes, numsGoldenRatio))
+		}
+		for j := 1; j < len(top); j++ {
+			if RendezvousScore(key, nodes[top[j-1]], numsGoldenRatio) < RendezvousScore(key, nodes[top[j]], numsGoldenRatio) {
+				t.Fatalf("RendezvousTop() = %v isn't sorted by score", top)
+			}
+		}
+
+		all := RendezvousTop(key, nodes, 10, numsGoldenRatio)
+		if len(all) != 5 {
+			t.Fatalf("RendezvousTop() with n > number of nodes returned %d nodes; want 5", len(all))
+		}
+		if !reflect.DeepEqual(all[:3], top) {
+			t.Fatalf("RendezvousTop(n=10)[:3] = %v; want %v", all[:3], top)
+		}
+		for _, idx := range all {
+			if idx == 3 {
+				t.Fatal("RendezvousTop() returned node with weight 0")
+			}
+		}
+	}
+
+	if got := RendezvousTop(numsGoldenRatio, nodes, -1, numsGoldenRatio); len(got) != 0 {
+		t.Errorf("RendezvousTop() with n = -1 returned %v", got)
+	}
+}
+
+func TestRendezvousTies(t *testing.T) {
+	// Infinite weights give equal scores, which are broken by node ID
+	// regardless of order of nodes.
+	nodes := []RendezvousNode{
+		{ID: 7, Weight: math.Inf(1)},
+		{ID: 3, Weight: math.Inf(1)},
+		{ID: 5, Weight: 1},
+	}
+	reversed := []RendezvousNode{nodes[2], nodes[1], nodes[0]}
+
+	for i := 0; i < 100; i++ {
+		key := Hash64String(strconv.Itoa(i), numsGoldenRatio)
+
+		for _, ns := range [][]RendezvousNode{nodes, reversed} {
+			if got := ns[Rendezvous(key, ns, numsGoldenRatio)].ID; got != 3 {
+				t.Fatalf("Rendezvous() chose node %d; want 3", got)
+			}
+
+			var ids []uint64
+			for _, idx := range RendezvousTop(key, ns, 3, numsGoldenRatio) {
+				ids = append(ids, ns[idx].ID)
+			}
+			if want := []uint64{3, 7, 5}; !reflect.DeepEqual(ids, want) {
+				t.Fatalf("RendezvousTop() chose nodes %v; want %v", ids, want)
+			}
+		}
+	}
+}
+
+func BenchmarkRendezvous(b *testing.B) {
+	nodes := testNodes(1, 1, 1, 1, 1, 1, 1, 1, 1, 1)
+	key := []byte("user.name@example.com")
+	for i := 0; i < b.N; i++ {
+		_ = RendezvousBytes(key, nodes, numsGoldenRatio)
+	}
+}
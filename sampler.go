@@ -0,0 +1,93 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package circlehash
+
+import "math"
+
+// Sampler is a deterministic hash-based sampler.  Samplers with the same
+// Rate and Seed keep the same keys, so services sharing them sample the
+// same traces or logs without coordination.
+//
+// Key is kept if its digest h is less than threshold Rate * 2^64.
+// Comparing h with threshold maps digest to [0, 1) without modulo bias,
+// and keys kept at a rate are also kept at any higher rate with the
+// same Seed.
+type Sampler struct {
+	// Rate is fraction of keys kept, in range [0, 1].  Keys are never kept
+	// if Rate <= 0 and always kept if Rate >= 1.
+	Rate float64
+
+	// Seed is seed for Hash64.
+	Seed uint64
+}
+
+// Keep returns true if key b is kept.
+func (s Sampler) Keep(b []byte) bool {
+	return s.KeepDigest(Hash64(b, s.Seed))
+}
+
+// KeepString returns true if key str is kept.
+func (s Sampler) KeepString(str string) bool {
+	return s.KeepDigest(Hash64String(str, s.Seed))
+}
+
+// KeepDigest returns true if key with digest h is kept.  Digest should be
+// produced with s.Seed, such as HashFlow(flow, s.Seed).
+func (s Sampler) KeepDigest(h uint64) bool {
+	if s.Rate >= 1 {
+		return true
+	}
+	return h < s.threshold()
+}
+
+// threshold returns Rate * 2^64 for Rate < 1.
+func (s Sampler) threshold() uint64 {
+	if !(s.Rate > 0) {
+		// Rate is <= 0 or NaN.
+		return 0
+	}
+	// Rate * 2^64 < 2^64 because Rate <= 1 - 2^-53.
+	return uint64(s.Rate * (1 << 64))
+}
+
+// ComposeRates returns effective rate of nested sampling with rates,
+// such as a service keeping rates[0] of keys and downstream services
+// keeping rates[1] of those.  Effective rate is product of rates, which
+// assumes nested samplers use different seeds.
+//
+// Nested samplers with the same seed keep the same keys, so their
+// effective rate is minimum of rates instead.
+func ComposeRates(rates ...float64) float64 {
+	r := 1.0
+	for _, rate := range rates {
+		r *= math.Max(0, math.Min(rate, 1))
+	}
+	return r
+}
+
+// EstimateTotal returns estimated number of keys in population from
+// number of kept keys and sampling rate, with standard error of estimate.
+// Estimate is count / rate (Horvitz-Thompson estimator) and is unbiased.
+// If rate <= 0, EstimateTotal returns NaN for total and standard error.
+func EstimateTotal(count uint64, rate float64) (total float64, stdErr float64) {
+	if !(rate > 0) {
+		return math.NaN(), math.NaN()
+	}
+	if rate >= 1 {
+		return float64(count), 0
+	}
+	n := float64(count)
+	return n / rate, math.Sqrt(n*(1-rate)) / rate
+}
@@ -0,0 +1,189 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package circlehash
+
+import (
+	"math"
+	"strconv"
+	"testing"
+)
+
+func TestSamplerThreshold(t *testing.T) {
+	testCases := []struct {
+		rate float64
+		want uint64
+	}{
+		{math.NaN(), 0},
+		{-1, 0},
+		{0, 0},
+		{0.25, 1 << 62},
+		{0.5, 1 << 63},
+		{0.75, 3 << 62},
+		{1 - 0x1p-53, 1<<64 - 1<<11},
+	}
+	for _, tc := range testCases {
+		s := Sampler{Rate: tc.rate}
+		if got := s.threshold(); got != tc.want {
+			t.Errorf("Sampler{Rate: %v}.threshold() = 0x%016x; want 0x%016x", tc.rate, got, tc.want)
+		}
+	}
+}
+
+func TestSamplerKeepDigest(t *testing.T) {
+	testCases := []struct {
+		rate float64
+		h    uint64
+		want bool
+	}{
+		{0, 0, false},
+		{-1, 0, false},
+		{math.NaN(), 0, false},
+		{0.5, 0, true},
+		{0.5, 1<<63 - 1, true},
+		{0.5, 1 << 63, false},
+		{0.5, math.MaxUint64, false},
+		{1, math.MaxUint64, true},
+		{2, math.MaxUint64, true},
+	}
+	for _, tc := range testCases {
+		s := Sampler{Rate: tc.rate}
+		if got := s.KeepDigest(tc.h); got != tc.want {
+			t.Errorf("Sampler{Rate: %v}.KeepDigest(0x%016x) = %t; want %t", tc.rate, tc.h, got, tc.want)
+		}
+	}
+}
+
+func TestSamplerKeep(t *testing.T) {
+	const numKeys = 200000
+
+	for _, rate := range []float64{0.001, 0.01, 0.1, 0.5, 0.9} {
+		s := Sampler{Rate: rate, Seed: numsGoldenRatio}
+
+		kept := 0
+		for i := 0; i < numKeys; i++ {
+			key := "trace-" + strconv.Itoa(i)
+			keep := s.KeepString(key)
+			if keep != s.Keep([]byte(key)) {
+				t.Fatalf("Sampler{Rate: %v}.KeepString(%q) != Keep()", rate, key)
+			}
+			if keep != s.KeepDigest(Hash64String(key, numsGoldenRatio)) {
+				t.Fatalf("Sampler{Rate: %v}.KeepString(%q) != KeepDigest()", rate, key)
+			}
+			if keep {
+				kept++
+			}
+		}
+
+		want := rate * numKeys
+		if math.Abs(float64(kept)-want) > 5*math.Sqrt(want) {
+			t.Errorf("Sampler{Rate: %v} kept %d keys; want about %.0f", rate, kept, want)
+		}
+	}
+}
+
+func TestSamplerConsistent(t *testing.T) {
+	// Keys kept at a rate are kept at higher rates with the same seed.
+	low := Sampler{Rate: 0.01, Seed: numsGoldenRatio}
+	high := Sampler{Rate: 0.1, Seed: numsGoldenRatio}
+	other := Sampler{Rate: 0.1, Seed: numsGoldenRatio + 1}
+
+	keptLow, keptBoth := 0, 0
+	for i := 0; i < 100000; i++ {
+		key := "trace-" + strconv.Itoa(i)
+		if !low.KeepString(key) {
+			continue
+		}
+		keptLow++
+		if !high.KeepString(key) {
+			t.Errorf("KeepString(%q) is true at rate 0.01 and false at rate 0.1", key)
+		}
+		if other.KeepString(key) {
+			keptBoth++
+		}
+	}
+
+	// Sampler with a different seed is independent.
+	want := float64(keptLow) * 0.1
+	if math.Abs(float64(keptBoth)-want) > 5*math.Sqrt(want) {
+		t.Errorf("Sampler with different seed kept %d of %d keys; want about %.0f", keptBoth, keptLow, want)
+	}
+}
+
+func TestComposeRates(t *testing.T) {
+	testCases := []struct {
+		rates []float64
+		want  float64
+	}{
+		{nil, 1},
+		{[]float64{0.5}, 0.5},
+		{[]float64{0.1, 0.5}, 0.05},
+		{[]float64{0.5, 0.5, 0.5}, 0.125},
+		{[]float64{0.5, 2}, 0.5},
+		{[]float64{0.5, -1}, 0},
+	}
+	for _, tc := range testCases {
+		if got := ComposeRates(tc.rates...); got != tc.want {
+			t.Errorf("ComposeRates(%v) = %v; want %v", tc.rates, got, tc.want)
+		}
+	}
+}
+
+func TestEstimateTotal(t *testing.T) {
+	testCases := []struct {
+		count      uint64
+		rate       float64
+		wantTotal  float64
+		wantStdErr float64
+	}{
+		{0, 0.5, 0, 0},
+		{100, 1, 100, 0},
+		{100, 0.5, 200, math.Sqrt(50) / 0.5},
+		{10, 0.01, 1000, math.Sqrt(10*0.99) / 0.01},
+	}
+	for _, tc := range testCases {
+		total, stdErr := EstimateTotal(tc.count, tc.rate)
+		if total != tc.wantTotal || stdErr != tc.wantStdErr {
+			t.Errorf("EstimateTotal(%d, %v) = %v, %v; want %v, %v", tc.count, tc.rate, total, stdErr, tc.wantTotal, tc.wantStdErr)
+		}
+	}
+
+	for _, rate := range []float64{0, -1, math.NaN()} {
+		total, stdErr := EstimateTotal(100, rate)
+		if !math.IsNaN(total) || !math.IsNaN(stdErr) {
+			t.Errorf("EstimateTotal(100, %v) = %v, %v; want NaN, NaN", rate, total, stdErr)
+		}
+	}
+
+	// Estimate from sampled keys is close to population size.
+	const numKeys = 100000
+	s := Sampler{Rate: 0.05, Seed: numsGoldenRatio}
+	kept := uint64(0)
+	for i := 0; i < numKeys; i++ {
+		if s.KeepString("log-" + strconv.Itoa(i)) {
+			kept++
+		}
+	}
+	total, stdErr := EstimateTotal(kept, s.Rate)
+	if math.Abs(total-numKeys) > 5*stdErr {
+		t.Errorf("EstimateTotal(%d, %v) = %v ± %v; want about %d", kept, s.Rate, total, stdErr, numKeys)
+	}
+}
+
+func BenchmarkSamplerKeepString(b *testing.B) {
+	s := Sampler{Rate: 0.01, Seed: numsGoldenRatio}
+	for i := 0; i < b.N; i++ {
+		_ = s.KeepString("0af7651916cd43dd8448eb211c80319c")
+	}
+}
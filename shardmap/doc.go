@@ -0,0 +1,34 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package shardmap implements a concurrent map sharded by CircleHash64f
+// digests of keys.  It requires Go 1.21 or later.
+//
+// ShardedMap has a power of two number of shards, each with its own lock
+// and hash table with separate chaining.  Digest h of key selects shard
+// using its high bits and bucket in shard using its low bits:
+//
+//	shard  = h >> (64 - log2(number of shards))
+//	bucket = h & (number of buckets in shard - 1)
+//
+// Keys are hashed with a random seed chosen for each map, so digests can't
+// be predicted by clients supplying keys.  Hash function is chosen by kind
+// of key type: strings are hashed with circlehash.Hash64String, integers,
+// bools, pointers, and channels with circlehash.Hash64Uint64x2, floats and
+// complex numbers with circlehash.HashValueFloat and
+// circlehash.HashValueComplex, and structs, arrays, and interfaces by
+// writing their fields to circlehash.Digest64.  Pointers and channels are
+// hashed by address, including in fields, because == compares them by
+// address.
+package shardmap
@@ -0,0 +1,394 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.21
+// +build go1.21
+
+package shardmap
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"math"
+	"reflect"
+	"runtime"
+	"sync"
+	"unsafe"
+
+	"github.com/fxamacker/circlehash"
+)
+
+const (
+	// MaxShards is maximum number of shards.
+	MaxShards = 1 << 16
+
+	// minBuckets is initial number of buckets in a shard.
+	minBuckets = 8
+)
+
+// ShardedMap is a concurrent map with keys of type K and values of type V.
+// It is safe for concurrent use and must be created with New or
+// NewWithHasher.
+type ShardedMap[K comparable, V any] struct {
+	seed   uint64
+	shift  uint // 64 - log2(len(shards))
+	hash   func(key K, seed uint64) uint64
+	shards []shard[K, V]
+}
+
+type shard[K comparable, V any] struct {
+	mu      sync.RWMutex
+	buckets []*entry[K, V]
+	count   int
+
+	// Padding prevents false sharing between locks of adjacent shards.
+	_ [64]byte
+}
+
+type entry[K comparable, V any] struct {
+	hash  uint64
+	key   K
+	value V
+	next  *entry[K, V]
+}
+
+// New returns an empty ShardedMap with shards rounded up to a power of two.
+// If shards is 0, New uses 4 times GOMAXPROCS.  Hash function is chosen by
+// kind of K.
+//
+// Keys of struct, array, and interface types are hashed field by field
+// using reflection.  Use NewWithHasher for better performance with such keys.
+func New[K comparable, V any](shards int) *ShardedMap[K, V] {
+	return NewWithHasher[K, V](shards, defaultHasher[K]())
+}
+
+// NewWithHasher returns an empty ShardedMap using hash function hasher.
+// Hasher must return the same digest for equal keys with the same seed.
+func NewWithHasher[K comparable, V any](shards int, hasher func(key K, seed uint64) uint64) *ShardedMap[K, V] {
+	if shards <= 0 {
+		shards = 4 * runtime.GOMAXPROCS(0)
+	}
+	if shards > MaxShards {
+		shards = MaxShards
+	}
+
+	bits := uint(0)
+	for 1<<bits < shards {
+		bits++
+	}
+
+	m := &ShardedMap[K, V]{
+		seed:   randomSeed(),
+		shift:  64 - bits,
+		hash:   hasher,
+		shards: make([]shard[K, V], 1<<bits),
+	}
+	for i := range m.shards {
+		m.shards[i].buckets = make([]*entry[K, V], minBuckets)
+	}
+	return m
+}
+
+func randomSeed() uint64 {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic("shardmap: failed to generate seed: " + err.Error())
+	}
+	return binary.LittleEndian.Uint64(b[:])
+}
+
+// Shards returns number of shards.
+func (m *ShardedMap[K, V]) Shards() int {
+	return len(m.shards)
+}
+
+func (m *ShardedMap[K, V]) shard(h uint64) *shard[K, V] {
+	// Shift by 64 (with 1 shard) is 0.
+	return &m.shards[h>>m.shift]
+}
+
+// Load returns value stored for key, and true if key is present.
+func (m *ShardedMap[K, V]) Load(key K) (V, bool) {
+	h := m.hash(key, m.seed)
+	s := m.shard(h)
+
+	s.mu.RLock()
+	e := s.find(h, key)
+	s.mu.RUnlock()
+
+	if e == nil {
+		var zero V
+		return zero, false
+	}
+	return e.value, true
+}
+
+// Store sets value for key.
+func (m *ShardedMap[K, V]) Store(key K, value V) {
+	h := m.hash(key, m.seed)
+	s := m.shard(h)
+
+	s.mu.Lock()
+	if e := s.find(h, key); e != nil {
+		e.value = value
+	} else {
+		s.insert(&entry[K, V]{hash: h, key: key, value: value})
+	}
+	s.mu.Unlock()
+}
+
+// LoadOrStore returns existing value for key if present.  Otherwise, it
+// stores and returns value.  Loaded is true if value was loaded.
+func (m *ShardedMap[K, V]) LoadOrStore(key K, value V) (actual V, loaded bool) {
+	h := m.hash(key, m.seed)
+	s := m.shard(h)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if e := s.find(h, key); e != nil {
+		return e.value, true
+	}
+	s.insert(&entry[K, V]{hash: h, key: key, value: value})
+	return value, false
+}
+
+// LoadAndDelete deletes value for key, returning previous value if any.
+// Loaded is true if key was present.
+func (m *ShardedMap[K, V]) LoadAndDelete(key K) (value V, loaded bool) {
+	h := m.hash(key, m.seed)
+	s := m.shard(h)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p := &s.buckets[h&uint64(len(s.buckets)-1)]
+	for e := *p; e != nil; p, e = &e.next, e.next {
+		if e.hash == h && e.key == key {
+			*p = e.next
+			s.count--
+			return e.value, true
+		}
+	}
+	return value, false
+}
+
+// Delete deletes value for key.
+func (m *ShardedMap[K, V]) Delete(key K) {
+	m.LoadAndDelete(key)
+}
+
+// Range calls f for each key and value in m.  If f returns false, Range
+// stops.  Like sync.Map, Range doesn't correspond to a consistent snapshot
+// of m: each shard is copied under its lock and f is called without locks,
+// so f can modify m.
+func (m *ShardedMap[K, V]) Range(f func(key K, value V) bool) {
+	var entries []entry[K, V]
+	for i := range m.shards {
+		s := &m.shards[i]
+
+		entries = entries[:0]
+		s.mu.RLock()
+		for _, e := range s.buckets {
+			for ; e != nil; e = e.next {
+				entries = append(entries, entry[K, V]{key: e.key, value: e.value})
+			}
+		}
+		s.mu.RUnlock()
+
+		for _, e := range entries {
+			if !f(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
+
+// Len returns number of keys in m.
+func (m *ShardedMap[K, V]) Len() int {
+	n := 0
+	for i := range m.shards {
+		s := &m.shards[i]
+		s.mu.RLock()
+		n += s.count
+		s.mu.RUnlock()
+	}
+	return n
+}
+
+// find returns entry for key with digest h, or nil.
+func (s *shard[K, V]) find(h uint64, key K) *entry[K, V] {
+	for e := s.buckets[h&uint64(len(s.buckets)-1)]; e != nil; e = e.next {
+		if e.hash == h && e.key == key {
+			return e
+		}
+	}
+	return nil
+}
+
+// insert adds e, growing buckets if average chain length exceeds 1.
+func (s *shard[K, V]) insert(e *entry[K, V]) {
+	if s.count >= len(s.buckets) {
+		s.grow()
+	}
+	i := e.hash & uint64(len(s.buckets)-1)
+	e.next = s.buckets[i]
+	s.buckets[i] = e
+	s.count++
+}
+
+func (s *shard[K, V]) grow() {
+	buckets := make([]*entry[K, V], 2*len(s.buckets))
+	mask := uint64(len(buckets) - 1)
+	for _, e := range s.buckets {
+		for e != nil {
+			next := e.next
+			i := e.hash & mask
+			e.next = buckets[i]
+			buckets[i] = e
+			e = next
+		}
+	}
+	s.buckets = buckets
+}
+
+// defaultHasher returns hash function for kind of K.
+func defaultHasher[K comparable]() func(key K, seed uint64) uint64 {
+	t := reflect.TypeOf((*K)(nil)).Elem()
+
+	switch t.Kind() {
+	case reflect.String:
+		return func(key K, seed uint64) uint64 {
+			return circlehash.Hash64String(*(*string)(unsafe.Pointer(&key)), seed)
+		}
+
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Ptr, reflect.Chan, reflect.UnsafePointer:
+		// Equal keys of these kinds have equal bits, and pointers and
+		// channels are hashed by address.
+		switch t.Size() {
+		case 1:
+			return func(key K, seed uint64) uint64 {
+				return circlehash.Hash64Uint64x2(uint64(*(*uint8)(unsafe.Pointer(&key))), 0, seed)
+			}
+		case 2:
+			return func(key K, seed uint64) uint64 {
+				return circlehash.Hash64Uint64x2(uint64(*(*uint16)(unsafe.Pointer(&key))), 0, seed)
+			}
+		case 4:
+			return func(key K, seed uint64) uint64 {
+				return circlehash.Hash64Uint64x2(uint64(*(*uint32)(unsafe.Pointer(&key))), 0, seed)
+			}
+		case 8:
+			return func(key K, seed uint64) uint64 {
+				return circlehash.Hash64Uint64x2(*(*uint64)(unsafe.Pointer(&key)), 0, seed)
+			}
+		}
+
+	case reflect.Float32:
+		return func(key K, seed uint64) uint64 {
+			return circlehash.HashValueFloat(float64(*(*float32)(unsafe.Pointer(&key))), seed)
+		}
+
+	case reflect.Float64:
+		return func(key K, seed uint64) uint64 {
+			return circlehash.HashValueFloat(*(*float64)(unsafe.Pointer(&key)), seed)
+		}
+
+	case reflect.Complex64:
+		return func(key K, seed uint64) uint64 {
+			return circlehash.HashValueComplex(complex128(*(*complex64)(unsafe.Pointer(&key))), seed)
+		}
+
+	case reflect.Complex128:
+		return func(key K, seed uint64) uint64 {
+			return circlehash.HashValueComplex(*(*complex128)(unsafe.Pointer(&key)), seed)
+		}
+	}
+
+	// Other keys are structs, arrays, and interfaces.
+	return func(key K, seed uint64) uint64 {
+		d := circlehash.NewDigest64(seed)
+		writeValue(d, reflect.ValueOf(&key).Elem())
+		return d.Sum64()
+	}
+}
+
+// writeValue writes v to d, so that values equal by == write the same
+// bytes.  Pointers and channels are written by address, and blank struct
+// fields are skipped, like == compares them.  Funcs, maps, and slices in
+// interfaces are skipped, because == panics for them.
+func writeValue(d *circlehash.Digest64, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.String:
+		s := v.String()
+		d.WriteUint64(uint64(len(s)))
+		d.WriteString(s)
+
+	case reflect.Bool:
+		if v.Bool() {
+			d.WriteByte(1)
+		} else {
+			d.WriteByte(0)
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		d.WriteUint64(uint64(v.Int()))
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		d.WriteUint64(v.Uint())
+
+	case reflect.Float32, reflect.Float64:
+		d.WriteUint64(floatBits(v.Float()))
+
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		d.WriteUint64(floatBits(real(c)))
+		d.WriteUint64(floatBits(imag(c)))
+
+	case reflect.Ptr, reflect.Chan, reflect.UnsafePointer:
+		d.WriteUint64(uint64(v.Pointer()))
+
+	case reflect.Interface:
+		if v.IsNil() {
+			d.WriteByte(0)
+			return
+		}
+		e := v.Elem()
+		d.WriteByte(byte(e.Kind()))
+		writeValue(d, e)
+
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			writeValue(d, v.Index(i))
+		}
+
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField(); i++ {
+			if t.Field(i).Name != "_" {
+				writeValue(d, v.Field(i))
+			}
+		}
+	}
+}
+
+// floatBits returns bits of f with -0 as +0, because -0 == +0.
+func floatBits(f float64) uint64 {
+	if f == 0 {
+		return 0
+	}
+	return math.Float64bits(f)
+}
@@ -0,0 +1,569 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.21
+// +build go1.21
+
+package shardmap
+
+import (
+	"math"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestShardedMapBasic(t *testing.T) {
+	m := New[string, int](0)
+
+	if _, ok := m.Load("a"); ok {
+		t.Errorf("Load(%q) of empty map returned true", "a")
+	}
+
+	m.Store("a", 1)
+	m.Store("b", 2)
+	if v, ok := m.Load("a"); !ok || v != 1 {
+		t.Errorf("Load(%q) = %d, %t; want 1, true", "a", v, ok)
+	}
+
+	m.Store("a", 3)
+	if v, ok := m.Load("a"); !ok || v != 3 {
+		t.Errorf("Load(%q) after overwrite = %d, %t; want 3, true", "a", v, ok)
+	}
+
+	if v, loaded := m.LoadOrStore("a", 4); !loaded || v != 3 {
+		t.Errorf("LoadOrStore(%q, 4) = %d, %t; want 3, true", "a", v, loaded)
+	}
+	if v, loaded := m.LoadOrStore("c", 5); loaded || v != 5 {
+		t.Errorf("LoadOrStore(%q, 5) = %d, %t; want 5, false", "c", v, loaded)
+	}
+	if n := m.Len(); n != 3 {
+		t.Errorf("Len() = %d; want 3", n)
+	}
+
+	if v, loaded := m.LoadAndDelete("b"); !loaded || v != 2 {
+		t.Errorf("LoadAndDelete(%q) = %d, %t; want 2, true", "b", v, loaded)
+	}
+	if _, loaded := m.LoadAndDelete("b"); loaded {
+		t.Errorf("LoadAndDelete(%q) of deleted key returned true", "b")
+	}
+	m.Delete("a")
+	m.Delete("missing")
+	if _, ok := m.Load("a"); ok {
+		t.Errorf("Load(%q) of deleted key returned true", "a")
+	}
+	if n := m.Len(); n != 1 {
+		t.Errorf("Len() = %d; want 1", n)
+	}
+}
+
+func TestShardedMapShards(t *testing.T) {
+	testCases := []struct {
+		shards int
+		want   int
+	}{
+		{1, 1},
+		{2, 2},
+		{3, 4},
+		{64, 64},
+		{100, 128},
+		{MaxShards + 1, MaxShards},
+	}
+
+	for _, tc := range testCases {
+		t.Run(strconv.Itoa(tc.shards), func(t *testing.T) {
+			m := New[int, int](tc.shards)
+			if got := m.Shards(); got != tc.want {
+				t.Errorf("Shards() = %d; want %d", got, tc.want)
+			}
+
+			// Every shard count maps all keys to valid shards.
+			for i := 0; i < 1000; i++ {
+				m.Store(i, i)
+			}
+			for i := 0; i < 1000; i++ {
+				if v, ok := m.Load(i); !ok || v != i {
+					t.Fatalf("Load(%d) = %d, %t; want %d, true", i, v, ok, i)
+				}
+			}
+		})
+	}
+
+	if got, want := New[int, int](0).Shards(), 4*runtime.GOMAXPROCS(0); got < want || got >= 2*want {
+		t.Errorf("Shards() with default = %d; want power of two in range [%d, %d)", got, want, 2*want)
+	}
+}
+
+func TestShardedMapGrow(t *testing.T) {
+	const numKeys = 100000
+
+	m := New[int, int](4)
+	for i := 0; i < numKeys; i++ {
+		m.Store(i, -i)
+	}
+	if n := m.Len(); n != numKeys {
+		t.Fatalf("Len() = %d; want %d", n, numKeys)
+	}
+	for i := 0; i < numKeys; i++ {
+		if v, ok := m.Load(i); !ok || v != -i {
+			t.Fatalf("Load(%d) = %d, %t; want %d, true", i, v, ok, -i)
+		}
+	}
+	for i := 0; i < numKeys; i += 2 {
+		m.Delete(i)
+	}
+	for i := 0; i < numKeys; i++ {
+		if _, ok := m.Load(i); ok != (i%2 == 1) {
+			t.Fatalf("Load(%d) returned %t after deleting even keys", i, ok)
+		}
+	}
+}
+
+func TestShardedMapUniform(t *testing.T) {
+	const (
+		numKeys   = 100000
+		numShards = 64
+	)
+
+	m := New[string, struct{}](numShards)
+	for i := 0; i < numKeys; i++ {
+		m.Store("key"+strconv.Itoa(i), struct{}{})
+	}
+
+	// Shards are chosen by high bits and buckets by low bits, so keys
+	// are spread evenly across shards and across buckets in each shard.
+	expected := float64(numKeys) / numShards
+	for i := range m.shards {
+		s := &m.shards[i]
+		if math.Abs(float64(s.count)-expected) > 5*math.Sqrt(expected) {
+			t.Errorf("shard %d has %d keys; want about %.0f", i, s.count, expected)
+		}
+
+		empty := 0
+		for _, e := range s.buckets {
+			if e == nil {
+				empty++
+			}
+		}
+		// Expected fraction of empty buckets is exp(-load), and load is
+		// more than 0.5 after growth.
+		if frac := float64(empty) / float64(len(s.buckets)); frac > 0.65 {
+			t.Errorf("shard %d has %d of %d buckets empty", i, empty, len(s.buckets))
+		}
+	}
+}
+
+func TestShardedMapSeed(t *testing.T) {
+	// Each map has its own seed, so key placement isn't predictable.
+	m1 := New[string, int](0)
+	m2 := New[string, int](0)
+	if m1.seed == m2.seed {
+		t.Errorf("maps have the same seed 0x%016x", m1.seed)
+	}
+}
+
+func TestShardedMapKeyKinds(t *testing.T) {
+	t.Run("named string", func(t *testing.T) {
+		type name string
+		m := New[name, int](0)
+		m.Store("x", 1)
+		if v, ok := m.Load(name("x")); !ok || v != 1 {
+			t.Errorf("Load(%q) = %d, %t; want 1, true", "x", v, ok)
+		}
+	})
+
+	t.Run("small integers", func(t *testing.T) {
+		m8 := New[int8, int](0)
+		m16 := New[uint16, int](0)
+		m32 := New[int32, int](0)
+		for i := -128; i < 128; i++ {
+			m8.Store(int8(i), i)
+			m16.Store(uint16(i), i)
+			m32.Store(int32(i), i)
+		}
+		if m8.Len() != 256 || m16.Len() != 256 || m32.Len() != 256 {
+			t.Fatalf("Len() = %d, %d, %d; want 256", m8.Len(), m16.Len(), m32.Len())
+		}
+		for i := -128; i < 128; i++ {
+			if v, ok := m8.Load(int8(i)); !ok || v != i {
+				t.Errorf("Load(int8(%d)) = %d, %t", i, v, ok)
+			}
+			if v, ok := m16.Load(uint16(i)); !ok || v != i {
+				t.Errorf("Load(uint16(%d)) = %d, %t", i, v, ok)
+			}
+			if v, ok := m32.Load(int32(i)); !ok || v != i {
+				t.Errorf("Load(int32(%d)) = %d, %t", i, v, ok)
+			}
+		}
+	})
+
+	t.Run("bool", func(t *testing.T) {
+		m := New[bool, string](0)
+		m.Store(true, "t")
+		m.Store(false, "f")
+		if v, _ := m.Load(true); v != "t" {
+			t.Errorf("Load(true) = %q; want %q", v, "t")
+		}
+		if v, _ := m.Load(false); v != "f" {
+			t.Errorf("Load(false) = %q; want %q", v, "f")
+		}
+	})
+
+	t.Run("float zero", func(t *testing.T) {
+		// -0 == +0, so they must be the same key.
+		m := New[float64, int](0)
+		m.Store(math.Copysign(0, -1), 1)
+		if v, ok := m.Load(0); !ok || v != 1 {
+			t.Errorf("Load(+0) after Store(-0) = %d, %t; want 1, true", v, ok)
+		}
+
+		m32 := New[float32, int](0)
+		m32.Store(float32(math.Copysign(0, -1)), 1)
+		if v, ok := m32.Load(0); !ok || v != 1 {
+			t.Errorf("Load(float32(+0)) after Store(-0) = %d, %t; want 1, true", v, ok)
+		}
+	})
+
+	t.Run("pointer", func(t *testing.T) {
+		// Pointers are hashed by address, not by values they point to.
+		a, b := new(int), new(int)
+		m := New[*int, string](0)
+		m.Store(a, "a")
+		m.Store(b, "b")
+		*a = 42
+		if v, ok := m.Load(a); !ok || v != "a" {
+			t.Errorf("Load(a) after modifying *a = %q, %t; want %q, true", v, ok, "a")
+		}
+		if v, ok := m.Load(b); !ok || v != "b" {
+			t.Errorf("Load(b) = %q, %t; want %q, true", v, ok, "b")
+		}
+	})
+
+	t.Run("struct", func(t *testing.T) {
+		type key struct {
+			A string
+			B int
+		}
+		m := New[key, int](0)
+		for i := 0; i < 100; i++ {
+			m.Store(key{strconv.Itoa(i), i}, i)
+		}
+		for i := 0; i < 100; i++ {
+			if v, ok := m.Load(key{strconv.Itoa(i), i}); !ok || v != i {
+				t.Errorf("Load(%d) = %d, %t; want %d, true", i, v, ok, i)
+			}
+		}
+		if _, ok := m.Load(key{"1", 2}); ok {
+			t.Errorf("Load() of missing struct key returned true")
+		}
+		checkSpread(t, m)
+	})
+
+	t.Run("struct with pointer", func(t *testing.T) {
+		// Pointer fields are hashed by address, so keys are found after
+		// values they point to change.
+		type key struct {
+			Name string
+			P    *int
+		}
+		keys := make([]key, 100)
+		m := New[key, int](0)
+		for i := range keys {
+			keys[i] = key{"k", new(int)}
+			m.Store(keys[i], i)
+		}
+		for i, k := range keys {
+			*k.P = i + 1
+		}
+		for i, k := range keys {
+			if v, ok := m.Load(k); !ok || v != i {
+				t.Errorf("Load(keys[%d]) after modifying *P = %d, %t; want %d, true", i, v, ok, i)
+			}
+		}
+		if _, ok := m.Load(key{"k", new(int)}); ok {
+			t.Error("Load() of key with different pointer returned true")
+		}
+		checkSpread(t, m)
+	})
+
+	t.Run("struct with channel", func(t *testing.T) {
+		type key struct {
+			C chan int
+		}
+		m := New[key, int](0)
+		keys := []key{{make(chan int)}, {make(chan int)}, {nil}}
+		for i, k := range keys {
+			m.Store(k, i)
+		}
+		for i, k := range keys {
+			if v, ok := m.Load(k); !ok || v != i {
+				t.Errorf("Load(keys[%d]) = %d, %t; want %d, true", i, v, ok, i)
+			}
+		}
+	})
+
+	t.Run("struct with blank field and floats", func(t *testing.T) {
+		// Blank fields are ignored and -0 == +0, like ==.
+		type key struct {
+			A float64
+			_ int
+			C [2]complex64
+		}
+		m := New[key, int](0)
+		k1 := key{A: math.Copysign(0, -1), C: [2]complex64{complex(float32(math.Copysign(0, -1)), 1)}}
+		m.Store(k1, 1)
+		k2 := key{C: [2]complex64{complex(0, 1)}}
+		if v, ok := m.Load(k2); !ok || v != 1 {
+			t.Errorf("Load(%v) after Store(%v) = %d, %t; want 1, true", k2, k1, v, ok)
+		}
+	})
+
+	t.Run("interface", func(t *testing.T) {
+		p := new(int)
+		keys := []interface{}{nil, 1, int64(1), "1", p, [2]string{"a", "b"}, struct{ X *int }{p}}
+		m := New[interface{}, int](0)
+		for i, k := range keys {
+			m.Store(k, i)
+		}
+		*p = 42
+		if n := m.Len(); n != len(keys) {
+			t.Fatalf("Len() = %d; want %d", n, len(keys))
+		}
+		for i, k := range keys {
+			if v, ok := m.Load(k); !ok || v != i {
+				t.Errorf("Load(%v) = %d, %t; want %d, true", k, v, ok, i)
+			}
+		}
+		checkSpread(t, m)
+	})
+}
+
+// checkSpread checks that keys of m have distinct digests, so they aren't
+// stored in one bucket.
+func checkSpread[K comparable, V any](t *testing.T, m *ShardedMap[K, V]) {
+	t.Helper()
+	digests := make(map[uint64]struct{})
+	n := 0
+	for i := range m.shards {
+		for _, e := range m.shards[i].buckets {
+			for ; e != nil; e = e.next {
+				digests[e.hash] = struct{}{}
+				n++
+			}
+		}
+	}
+	if len(digests) != n {
+		t.Errorf("%d keys have %d distinct digests", n, len(digests))
+	}
+}
+
+func TestShardedMapHasher(t *testing.T) {
+	// All keys collide, so every operation walks one chain.
+	m := NewWithHasher[int, int](8, func(key int, seed uint64) uint64 { return 0 })
+	for i := 0; i < 1000; i++ {
+		m.Store(i, i)
+	}
+	for i := 0; i < 1000; i += 3 {
+		m.Delete(i)
+	}
+	for i := 0; i < 1000; i++ {
+		v, ok := m.Load(i)
+		if ok != (i%3 != 0) || (ok && v != i) {
+			t.Fatalf("Load(%d) = %d, %t", i, v, ok)
+		}
+	}
+}
+
+func TestShardedMapRange(t *testing.T) {
+	const numKeys = 1000
+
+	m := New[int, int](16)
+	for i := 0; i < numKeys; i++ {
+		m.Store(i, i*2)
+	}
+
+	seen := make(map[int]bool)
+	m.Range(func(k, v int) bool {
+		if v != k*2 {
+			t.Errorf("Range() visited %d with value %d; want %d", k, v, k*2)
+		}
+		if seen[k] {
+			t.Errorf("Range() visited %d twice", k)
+		}
+		seen[k] = true
+		return true
+	})
+	if len(seen) != numKeys {
+		t.Errorf("Range() visited %d keys; want %d", len(seen), numKeys)
+	}
+
+	n := 0
+	m.Range(func(k, v int) bool {
+		n++
+		return n < 10
+	})
+	if n != 10 {
+		t.Errorf("Range() returning false after 10 keys visited %d keys", n)
+	}
+
+	// f can modify the map without deadlock.
+	m.Range(func(k, v int) bool {
+		m.Delete(k)
+		m.Store(k+numKeys, v)
+		return true
+	})
+	for i := 0; i < numKeys; i++ {
+		if _, ok := m.Load(i); ok {
+			t.Fatalf("Load(%d) returned true after deleting in Range()", i)
+		}
+	}
+}
+
+func TestShardedMapConcurrent(t *testing.T) {
+	const (
+		numGoroutines = 8
+		numKeys       = 1000
+	)
+
+	m := New[int, int](0)
+	var stored int64
+	var wg sync.WaitGroup
+	for g := 0; g < numGoroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < numKeys; i++ {
+				if _, loaded := m.LoadOrStore(i, g); !loaded {
+					atomic.AddInt64(&stored, 1)
+				}
+				k := g*numKeys + numKeys + i
+				m.Store(k, i)
+				if v, ok := m.Load(k); !ok || v != i {
+					t.Errorf("Load(%d) = %d, %t; want %d, true", k, v, ok, i)
+				}
+				m.Delete(k)
+			}
+			m.Range(func(k, v int) bool { return true })
+		}(g)
+	}
+	wg.Wait()
+
+	if stored != numKeys {
+		t.Errorf("LoadOrStore() stored %d keys; want %d", stored, numKeys)
+	}
+	if n := m.Len(); n != numKeys {
+		t.Errorf("Len() = %d; want %d", n, numKeys)
+	}
+}
+
+// Parallel benchmarks compare ShardedMap with sync.Map and a map guarded by
+// one sync.RWMutex.  Each goroutine reads and writes keys from keySet.
+
+const benchKeys = 1 << 14
+
+var keySet = func() []string {
+	keys := make([]string, benchKeys)
+	for i := range keys {
+		keys[i] = "key" + strconv.Itoa(i)
+	}
+	return keys
+}()
+
+type benchMap interface {
+	Load(key string) (int, bool)
+	Store(key string, value int)
+}
+
+type syncMap struct{ m sync.Map }
+
+func (m *syncMap) Load(key string) (int, bool) {
+	v, ok := m.m.Load(key)
+	if !ok {
+		return 0, false
+	}
+	return v.(int), true
+}
+
+func (m *syncMap) Store(key string, value int) { m.m.Store(key, value) }
+
+type rwMutexMap struct {
+	mu sync.RWMutex
+	m  map[string]int
+}
+
+func (m *rwMutexMap) Load(key string) (int, bool) {
+	m.mu.RLock()
+	v, ok := m.m[key]
+	m.mu.RUnlock()
+	return v, ok
+}
+
+func (m *rwMutexMap) Store(key string, value int) {
+	m.mu.Lock()
+	m.m[key] = value
+	m.mu.Unlock()
+}
+
+func benchmarkMaps(b *testing.B, writePercent int) {
+	maps := []struct {
+		name string
+		new  func() benchMap
+	}{
+		{"ShardedMap", func() benchMap { return New[string, int](0) }},
+		{"sync.Map", func() benchMap { return &syncMap{} }},
+		{"RWMutex", func() benchMap { return &rwMutexMap{m: make(map[string]int)} }},
+	}
+
+	for _, bm := range maps {
+		b.Run(bm.name, func(b *testing.B) {
+			m := bm.new()
+			for i, k := range keySet {
+				m.Store(k, i)
+			}
+
+			var seq uint64
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := int(atomic.AddUint64(&seq, 1) * 7919)
+				for pb.Next() {
+					k := keySet[i&(benchKeys-1)]
+					if i%100 < writePercent {
+						m.Store(k, i)
+					} else {
+						m.Load(k)
+					}
+					i++
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkLoad(b *testing.B) {
+	benchmarkMaps(b, 0)
+}
+
+func BenchmarkMostlyLoad(b *testing.B) {
+	benchmarkMaps(b, 10)
+}
+
+func BenchmarkBalanced(b *testing.B) {
+	benchmarkMaps(b, 50)
+}
+
+func BenchmarkStore(b *testing.B) {
+	benchmarkMaps(b, 100)
+}
@@ -0,0 +1,151 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simhash
+
+import (
+	"fmt"
+	"sort"
+)
+
+// MaxIndexDistance is maximum Hamming distance supported by Index.
+const MaxIndexDistance = 15
+
+// block is a range of bits [start, start+width) of fingerprint.
+type block struct {
+	start uint
+	width uint
+}
+
+// permute moves bits of b to the top of fp, keeping order of other bits.
+func (b block) permute(fp uint64) uint64 {
+	low := fp & (1<<b.start - 1)
+	mid := (fp >> b.start) & (1<<b.width - 1)
+	high := fp >> (b.start + b.width) // bits above block
+	return mid<<(64-b.width) | high<<b.start | low
+}
+
+// unpermute is inverse of permute.
+func (b block) unpermute(key uint64) uint64 {
+	low := key & (1<<b.start - 1)
+	mid := key >> (64 - b.width)
+	high := (key << b.width) >> (b.start + b.width)
+	return high<<(b.start+b.width) | mid<<b.start | low
+}
+
+// Index finds fingerprints within Hamming distance k of a query.
+//
+// Fingerprints are split into k+1 blocks, so any fingerprint within
+// distance k matches query exactly in at least one block.  For each
+// block, Index keeps a table of fingerprints permuted to put the block
+// in the top bits, sorted.  Query finds candidates in each table with
+// binary search and checks their distance.
+//
+// Index is immutable and safe for concurrent use.
+type Index struct {
+	k      int
+	blocks []block
+	tables [][]uint64 // sorted permuted fingerprints, one table per block
+}
+
+// NewIndex returns an Index of fingerprints for queries within Hamming
+// distance k, with k in range [0, MaxIndexDistance].  Duplicate
+// fingerprints are removed.
+func NewIndex(fingerprints []uint64, k int) (*Index, error) {
+	if k < 0 || k > MaxIndexDistance {
+		return nil, fmt.Errorf("simhash: distance %d is not in range [0, %d]", k, MaxIndexDistance)
+	}
+
+	numBlocks := uint(k + 1)
+	blocks := make([]block, numBlocks)
+	start := uint(0)
+	for i := range blocks {
+		width := 64 / numBlocks
+		if uint(i) < 64%numBlocks {
+			width++
+		}
+		blocks[i] = block{start: start, width: width}
+		start += width
+	}
+
+	tables := make([][]uint64, numBlocks)
+	for i, b := range blocks {
+		table := make([]uint64, len(fingerprints))
+		for j, fp := range fingerprints {
+			table[j] = b.permute(fp)
+		}
+		sort.Slice(table, func(x, y int) bool { return table[x] < table[y] })
+		tables[i] = dedup(table)
+	}
+
+	return &Index{k: k, blocks: blocks, tables: tables}, nil
+}
+
+func dedup(sorted []uint64) []uint64 {
+	n := 0
+	for i, v := range sorted {
+		if i == 0 || v != sorted[n-1] {
+			sorted[n] = v
+			n++
+		}
+	}
+	return sorted[:n]
+}
+
+// K returns maximum Hamming distance of queries.
+func (x *Index) K() int {
+	return x.k
+}
+
+// Len returns number of distinct fingerprints in x.
+func (x *Index) Len() int {
+	return len(x.tables[0])
+}
+
+// Query returns sorted fingerprints within Hamming distance K of fp.
+func (x *Index) Query(fp uint64) []uint64 {
+	var matches []uint64
+	for i, b := range x.blocks {
+		table := x.tables[i]
+		key := b.permute(fp)
+		shift := 64 - b.width
+		prefix := key >> shift
+
+		j := sort.Search(len(table), func(j int) bool { return table[j]>>shift >= prefix })
+		for ; j < len(table) && table[j]>>shift == prefix; j++ {
+			candidate := b.unpermute(table[j])
+			if Distance(candidate, fp) > x.k {
+				continue
+			}
+			// Skip fingerprints already found in earlier blocks,
+			// which are those matching an earlier block exactly.
+			if x.matchesEarlierBlock(candidate, fp, i) {
+				continue
+			}
+			matches = append(matches, candidate)
+		}
+	}
+	sort.Slice(matches, func(a, b int) bool { return matches[a] < matches[b] })
+	return matches
+}
+
+func (x *Index) matchesEarlierBlock(a, b uint64, i int) bool {
+	for _, blk := range x.blocks[:i] {
+		mask := (uint64(1)<<blk.width - 1) << blk.start
+		if a&mask == b&mask {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simhash
+
+import (
+	"math/rand"
+	"reflect"
+	"sort"
+	"strconv"
+	"testing"
+)
+
+func randomFingerprints(n int, seed int64) []uint64 {
+	r := rand.New(rand.NewSource(seed)) //nolint:gosec
+	fps := make([]uint64, n)
+	for i := range fps {
+		fps[i] = r.Uint64()
+	}
+	return fps
+}
+
+// flipBits returns fp with d random bits flipped.
+func flipBits(r *rand.Rand, fp uint64, d int) uint64 {
+	for _, j := range r.Perm(64)[:d] {
+		fp ^= 1 << uint(j)
+	}
+	return fp
+}
+
+func bruteForce(fps []uint64, fp uint64, k int) []uint64 {
+	seen := make(map[uint64]bool)
+	var matches []uint64
+	for _, v := range fps {
+		if Distance(v, fp) <= k && !seen[v] {
+			seen[v] = true
+			matches = append(matches, v)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i] < matches[j] })
+	return matches
+}
+
+func TestBlockPermute(t *testing.T) {
+	fps := randomFingerprints(100, 1)
+	for _, k := range []int{0, 1, 3, 5, 7, MaxIndexDistance} {
+		x, _ := NewIndex(nil, k)
+		total := uint(0)
+		for _, b := range x.blocks {
+			total += b.width
+			for _, fp := range fps {
+				key := b.permute(fp)
+				if got := b.unpermute(key); got != fp {
+					t.Fatalf("k=%d: unpermute(permute(0x%016x)) = 0x%016x", k, fp, got)
+				}
+				if want := (fp >> b.start) & (1<<b.width - 1); key>>(64-b.width) != want {
+					t.Fatalf("k=%d: permute(0x%016x) top bits = 0x%x; want 0x%x", k, fp, key>>(64-b.width), want)
+				}
+			}
+		}
+		if total != 64 {
+			t.Errorf("k=%d: blocks have %d bits; want 64", k, total)
+		}
+	}
+}
+
+func TestIndexQuery(t *testing.T) {
+	r := rand.New(rand.NewSource(2)) //nolint:gosec
+
+	fps := randomFingerprints(5000, 3)
+	// Add clusters of near duplicates.
+	for i := 0; i < 500; i++ {
+		fps = append(fps, flipBits(r, fps[i], r.Intn(8)))
+	}
+	fps = append(fps, fps[:100]...) // duplicates
+
+	for _, k := range []int{0, 1, 3, 4, 6} {
+		x, err := NewIndex(fps, k)
+		if err != nil {
+			t.Fatalf("NewIndex(%d) returned error %v", k, err)
+		}
+		if x.K() != k {
+			t.Errorf("K() = %d; want %d", x.K(), k)
+		}
+		if x.Len() != len(bruteForce(fps, 0, 64)) {
+			t.Errorf("Len() = %d; want %d", x.Len(), len(bruteForce(fps, 0, 64)))
+		}
+
+		for i := 0; i < 1000; i++ {
+			q := flipBits(r, fps[r.Intn(len(fps))], r.Intn(k+3))
+			got := x.Query(q)
+			want := bruteForce(fps, q, k)
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("k=%d: Query(0x%016x) = %x; want %x", k, q, got, want)
+			}
+		}
+	}
+}
+
+func TestNewIndexInvalid(t *testing.T) {
+	for _, k := range []int{-1, MaxIndexDistance + 1} {
+		if _, err := NewIndex(nil, k); err == nil {
+			t.Errorf("NewIndex(%d) didn't return error", k)
+		}
+	}
+}
+
+func BenchmarkIndexQuery(b *testing.B) {
+	fps := randomFingerprints(1000000, 4)
+	r := rand.New(rand.NewSource(5)) //nolint:gosec
+
+	for _, k := range []int{3, 6} {
+		x, _ := NewIndex(fps, k)
+		queries := make([]uint64, 1024)
+		for i := range queries {
+			queries[i] = flipBits(r, fps[r.Intn(len(fps))], k)
+		}
+
+		b.Run("k="+strconv.Itoa(k), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				_ = x.Query(queries[i%len(queries)])
+			}
+		})
+	}
+}
+
+func BenchmarkLinearScan(b *testing.B) {
+	fps := randomFingerprints(1000000, 4)
+	q := fps[12345]
+	for i := 0; i < b.N; i++ {
+		_ = bruteForce(fps, q, 3)
+	}
+}
+
+func BenchmarkNewIndex(b *testing.B) {
+	fps := randomFingerprints(1000000, 4)
+	for i := 0; i < b.N; i++ {
+		_, _ = NewIndex(fps, 3)
+	}
+}
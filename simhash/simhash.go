@@ -0,0 +1,131 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package simhash implements 64-bit SimHash fingerprints using CircleHash64f,
+// and an index to find fingerprints within a Hamming distance.
+//
+// Each feature is hashed with circlehash.Hash64String.  Bit j of fingerprint
+// is 1 if sum of weights of features with bit j set in their digest is
+// greater than sum of weights of features with bit j unset.  Similar
+// documents have fingerprints with small Hamming distance.
+// See "Similarity Estimation Techniques from Rounding Algorithms"
+// by Moses Charikar, and "Detecting Near-Duplicates for Web Crawling"
+// by Gurmeet Singh Manku, Arvind Jain, and Anish Das Sarma.
+package simhash
+
+import (
+	"math/bits"
+	"strings"
+	"unicode"
+
+	"github.com/fxamacker/circlehash"
+)
+
+// Feature is a weighted feature of a document.
+type Feature struct {
+	Text   string
+	Weight float64
+}
+
+// Builder computes a SimHash fingerprint from features added one at a time.
+// The zero value is a Builder with seed 0.
+type Builder struct {
+	seed uint64
+	v    [64]float64
+}
+
+// NewBuilder returns a Builder that hashes features with seed.
+func NewBuilder(seed uint64) *Builder {
+	return &Builder{seed: seed}
+}
+
+// Add adds feature with weight.
+func (b *Builder) Add(feature string, weight float64) {
+	h := circlehash.Hash64String(feature, b.seed)
+	for j := range b.v {
+		if h&(1<<uint(j)) != 0 {
+			b.v[j] += weight
+		} else {
+			b.v[j] -= weight
+		}
+	}
+}
+
+// Sum64 returns fingerprint of added features.
+func (b *Builder) Sum64() uint64 {
+	var fp uint64
+	for j, w := range b.v {
+		if w > 0 {
+			fp |= 1 << uint(j)
+		}
+	}
+	return fp
+}
+
+// Reset removes all added features.
+func (b *Builder) Reset() {
+	b.v = [64]float64{}
+}
+
+// Fingerprint returns fingerprint of weighted features.
+func Fingerprint(features []Feature, seed uint64) uint64 {
+	b := Builder{seed: seed}
+	for _, f := range features {
+		b.Add(f.Text, f.Weight)
+	}
+	return b.Sum64()
+}
+
+// FingerprintStrings returns fingerprint of features with weight 1.
+// Duplicate features add up.
+func FingerprintStrings(features []string, seed uint64) uint64 {
+	b := Builder{seed: seed}
+	for _, f := range features {
+		b.Add(f, 1)
+	}
+	return b.Sum64()
+}
+
+// Distance returns Hamming distance between fingerprints a and b.
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Tokens returns lower case words in text.  Words are runs of letters
+// and digits, and other characters are separators.
+func Tokens(text string) []string {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, f := range fields {
+		fields[i] = strings.ToLower(f)
+	}
+	return fields
+}
+
+// Shingles returns all sequences of n consecutive tokens joined by space.
+// If there are fewer than n tokens, it returns all tokens as one shingle.
+func Shingles(tokens []string, n int) []string {
+	if len(tokens) == 0 || n < 1 {
+		return nil
+	}
+	if len(tokens) <= n {
+		return []string{strings.Join(tokens, " ")}
+	}
+	shingles := make([]string, 0, len(tokens)-n+1)
+	for i := 0; i+n <= len(tokens); i++ {
+		shingles = append(shingles, strings.Join(tokens[i:i+n], " "))
+	}
+	return shingles
+}
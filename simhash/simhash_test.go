@@ -0,0 +1,147 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package simhash
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fxamacker/circlehash"
+)
+
+const testSeed = uint64(0x9E3779B97F4A7C15)
+
+const testDocument = `Go is an open source programming language that makes it simple
+to build secure, scalable systems. It is expressive, concise, clean, and efficient.
+Its concurrency mechanisms make it easy to write programs that get the most out of
+multicore and networked machines, while its novel type system enables flexible and
+modular program construction.`
+
+func documentFingerprint(text string) uint64 {
+	return FingerprintStrings(Shingles(Tokens(text), 3), testSeed)
+}
+
+func TestFingerprintSingleFeature(t *testing.T) {
+	// Fingerprint of one feature is its digest.
+	for _, s := range []string{"", "a", "hello world"} {
+		got := FingerprintStrings([]string{s}, testSeed)
+		want := circlehash.Hash64String(s, testSeed)
+		if got != want {
+			t.Errorf("FingerprintStrings([%q]) = 0x%016x; want 0x%016x", s, got, want)
+		}
+	}
+}
+
+func TestFingerprintWeights(t *testing.T) {
+	// Feature with the largest weight dominates.
+	features := []Feature{{"heavy", 10}, {"light1", 1}, {"light2", 1}, {"light3", 1}}
+	got := Fingerprint(features, testSeed)
+	want := circlehash.Hash64String("heavy", testSeed)
+	if got != want {
+		t.Errorf("Fingerprint() = 0x%016x; want 0x%016x", got, want)
+	}
+
+	if got := Fingerprint(nil, testSeed); got != 0 {
+		t.Errorf("Fingerprint(nil) = 0x%016x; want 0", got)
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	features := []Feature{{"a", 1.5}, {"b", 2}, {"c", 0.5}}
+
+	b := NewBuilder(testSeed)
+	for _, f := range features {
+		b.Add(f.Text, f.Weight)
+	}
+	if got, want := b.Sum64(), Fingerprint(features, testSeed); got != want {
+		t.Errorf("Sum64() = 0x%016x; want 0x%016x", got, want)
+	}
+
+	b.Reset()
+	b.Add("a", 1)
+	if got, want := b.Sum64(), circlehash.Hash64String("a", testSeed); got != want {
+		t.Errorf("Sum64() after Reset() = 0x%016x; want 0x%016x", got, want)
+	}
+}
+
+func TestNearDuplicates(t *testing.T) {
+	fp := documentFingerprint(testDocument)
+
+	nearDuplicates := []string{
+		strings.ToUpper(testDocument),
+		strings.Replace(testDocument, "simple", "easy", 1),
+		strings.Replace(testDocument, "concise, clean,", "concise, clean, readable,", 1),
+		testDocument + " Learn more.",
+	}
+	for _, doc := range nearDuplicates {
+		if d := Distance(fp, documentFingerprint(doc)); d > 12 {
+			t.Errorf("Distance() of near duplicate = %d; want at most 12", d)
+		}
+	}
+
+	different := "The quick brown fox jumps over the lazy dog, then the dog chases the fox back into the forest."
+	if d := Distance(fp, documentFingerprint(different)); d < 20 {
+		t.Errorf("Distance() of different document = %d; want at least 20", d)
+	}
+}
+
+func TestTokens(t *testing.T) {
+	testCases := []struct {
+		text string
+		want []string
+	}{
+		{"", []string{}},
+		{"  ,. ", []string{}},
+		{"Hello, World!", []string{"hello", "world"}},
+		{"GET /api/v1/users?id=42", []string{"get", "api", "v1", "users", "id", "42"}},
+		{"Straße ÜBER", []string{"straße", "über"}},
+		{"日本語 テキスト", []string{"日本語", "テキスト"}},
+	}
+	for _, tc := range testCases {
+		if got := Tokens(tc.text); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Tokens(%q) = %q; want %q", tc.text, got, tc.want)
+		}
+	}
+}
+
+func TestShingles(t *testing.T) {
+	tokens := []string{"a", "b", "c", "d"}
+	testCases := []struct {
+		tokens []string
+		n      int
+		want   []string
+	}{
+		{tokens, 1, []string{"a", "b", "c", "d"}},
+		{tokens, 2, []string{"a b", "b c", "c d"}},
+		{tokens, 3, []string{"a b c", "b c d"}},
+		{tokens, 4, []string{"a b c d"}},
+		{tokens, 5, []string{"a b c d"}},
+		{tokens, 0, nil},
+		{nil, 2, nil},
+	}
+	for _, tc := range testCases {
+		if got := Shingles(tc.tokens, tc.n); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Shingles(%q, %d) = %q; want %q", tc.tokens, tc.n, got, tc.want)
+		}
+	}
+}
+
+func BenchmarkFingerprint(b *testing.B) {
+	shingles := Shingles(Tokens(testDocument), 3)
+	for i := 0; i < b.N; i++ {
+		_ = FingerprintStrings(shingles, testSeed)
+	}
+}
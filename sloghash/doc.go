@@ -0,0 +1,38 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sloghash implements a log/slog handler that samples and
+// deduplicates log records using CircleHash64f digests.  It requires
+// Go 1.21 or later.
+//
+// Digest of a record is computed with circlehash.Digest64 from the record's
+// message and selected attributes (including attributes added by WithAttrs)
+// without formatting them into a buffer first:
+//
+//	message:   len(msg) as uint64, msg
+//	attribute: len(key) as uint64, key, kind as byte, value
+//
+// where key includes group names separated by ".", such as "req.method".
+// Values are written as len and bytes for strings, as uint64 for numbers,
+// bools, durations, and times (in Unix nanoseconds).  Other values are
+// written as Error() for errors, as marshaled bytes for values with
+// MarshalBinary or MarshalText methods (such as time.Time and big.Int),
+// as String() for fmt.Stringer values, and as circlehash.HashValue digest
+// otherwise.
+//
+// Records are sampled with circlehash.Sampler using the digest, so services
+// with the same options keep the same records.  Duplicates (records with
+// the same digest) within a time window are dropped, using a seen-set with
+// bounded number of entries.
+package sloghash
@@ -0,0 +1,363 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.21
+// +build go1.21
+
+package sloghash
+
+import (
+	"context"
+	"encoding"
+	"fmt"
+	"log/slog"
+	"math"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/fxamacker/circlehash"
+)
+
+// DefaultMaxEntries is default maximum number of digests in seen-set.
+const DefaultMaxEntries = 4096
+
+// Options are options for Handler.
+type Options struct {
+	// Keys are attribute keys included in digest, with group names
+	// separated by ".", such as "req.method".  If Keys is nil, all
+	// attributes are included.  If Keys is empty and not nil, only message
+	// is included.
+	Keys []string
+
+	// Rate is fraction of records kept by sampling, with the same meaning
+	// as circlehash.Sampler.Rate: no records are kept if *Rate is 0, and
+	// all records are kept if *Rate >= 1.  Records aren't sampled if Rate
+	// is nil.
+	Rate *float64
+
+	// Window is time window for dropping duplicates.  A record is dropped
+	// if a record with the same digest was handled within Window before it.
+	// Duplicates aren't dropped if Window is 0.
+	Window time.Duration
+
+	// MaxEntries is maximum number of digests in seen-set.  When seen-set
+	// is full, the oldest digest is removed.  If MaxEntries is 0,
+	// DefaultMaxEntries is used.
+	MaxEntries int
+
+	// Seed is seed for digests.
+	Seed uint64
+}
+
+// Handler is a slog.Handler that samples and deduplicates records before
+// passing them to another handler.
+type Handler struct {
+	next    slog.Handler
+	keys    map[string]struct{} // nil if all attributes are included
+	sampler circlehash.Sampler
+	sample  bool
+	window  time.Duration
+	seen    *seenSet // shared by handlers returned by WithAttrs and WithGroup
+	seed    uint64
+
+	group string      // group prefix for attributes, such as "req."
+	attrs []slog.Attr // attributes from WithAttrs with group prefix in keys
+}
+
+var _ slog.Handler = (*Handler)(nil)
+
+// NewHandler returns a Handler that passes sampled and deduplicated records
+// to next.  If opts is nil, zero Options is used, so records are neither
+// sampled nor deduplicated.
+func NewHandler(next slog.Handler, opts *Options) (*Handler, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	if opts.Rate != nil && !(*opts.Rate >= 0) {
+		return nil, fmt.Errorf("sloghash: invalid rate %v", *opts.Rate)
+	}
+	if opts.Window < 0 {
+		return nil, fmt.Errorf("sloghash: invalid window %v", opts.Window)
+	}
+	if opts.MaxEntries < 0 {
+		return nil, fmt.Errorf("sloghash: invalid max entries %d", opts.MaxEntries)
+	}
+
+	h := &Handler{
+		next:   next,
+		window: opts.Window,
+		seed:   opts.Seed,
+	}
+	if opts.Rate != nil && *opts.Rate < 1 {
+		h.sampler = circlehash.Sampler{Rate: *opts.Rate, Seed: opts.Seed}
+		h.sample = true
+	}
+	if opts.Keys != nil {
+		h.keys = make(map[string]struct{}, len(opts.Keys))
+		for _, k := range opts.Keys {
+			h.keys[k] = struct{}{}
+		}
+	}
+	if opts.Window > 0 {
+		maxEntries := opts.MaxEntries
+		if maxEntries == 0 {
+			maxEntries = DefaultMaxEntries
+		}
+		h.seen = newSeenSet(maxEntries)
+	}
+	return h, nil
+}
+
+// Enabled returns true if next handler handles records with level.
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle passes r to next handler unless r is dropped by sampling or is
+// a duplicate within window.  Time of duplicate is r.Time, or current time
+// if r.Time is zero.
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	if !h.sample && h.seen == nil {
+		return h.next.Handle(ctx, r)
+	}
+
+	digest := h.Digest(r)
+	if h.sample && !h.sampler.KeepDigest(digest) {
+		return nil
+	}
+	if h.seen != nil {
+		t := r.Time
+		if t.IsZero() {
+			t = time.Now()
+		}
+		if !h.seen.add(digest, t.UnixNano(), int64(h.window)) {
+			return nil
+		}
+	}
+	return h.next.Handle(ctx, r)
+}
+
+// WithAttrs returns a Handler with attrs added to next handler and
+// included in digests.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+	h2 := *h
+	h2.next = h.next.WithAttrs(attrs)
+	h2.attrs = make([]slog.Attr, len(h.attrs), len(h.attrs)+len(attrs))
+	copy(h2.attrs, h.attrs)
+	for _, a := range attrs {
+		a.Key = h.group + a.Key
+		h2.attrs = append(h2.attrs, a)
+	}
+	return &h2
+}
+
+// WithGroup returns a Handler with group added to next handler and to keys
+// of attributes in digests.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.next = h.next.WithGroup(name)
+	h2.group = h.group + name + "."
+	return &h2
+}
+
+// Digest returns digest of r used for sampling and deduplication.
+func (h *Handler) Digest(r slog.Record) uint64 {
+	d := circlehash.NewDigest64(h.seed)
+	d.WriteUint64(uint64(len(r.Message)))
+	d.WriteString(r.Message)
+
+	for _, a := range h.attrs {
+		// Keys of attrs already have group prefix.
+		h.writeAttr(d, "", a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		h.writeAttr(d, h.group, a)
+		return true
+	})
+	return d.Sum64()
+}
+
+func (h *Handler) writeAttr(d *circlehash.Digest64, prefix string, a slog.Attr) {
+	v := a.Value.Resolve()
+
+	if v.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range v.Group() {
+			h.writeAttr(d, prefix, ga)
+		}
+		return
+	}
+	if a.Key == "" {
+		// Handlers ignore attributes with empty keys.
+		return
+	}
+
+	key := a.Key
+	if prefix != "" {
+		key = prefix + a.Key
+	}
+	if h.keys != nil {
+		if _, ok := h.keys[key]; !ok {
+			return
+		}
+	}
+
+	d.WriteUint64(uint64(len(key)))
+	d.WriteString(key)
+	d.WriteByte(byte(v.Kind()))
+
+	switch v.Kind() {
+	case slog.KindString:
+		s := v.String()
+		d.WriteUint64(uint64(len(s)))
+		d.WriteString(s)
+	case slog.KindInt64:
+		d.WriteUint64(uint64(v.Int64()))
+	case slog.KindUint64:
+		d.WriteUint64(v.Uint64())
+	case slog.KindFloat64:
+		d.WriteUint64(math.Float64bits(v.Float64()))
+	case slog.KindBool:
+		if v.Bool() {
+			d.WriteUint64(1)
+		} else {
+			d.WriteUint64(0)
+		}
+	case slog.KindDuration:
+		d.WriteUint64(uint64(v.Duration()))
+	case slog.KindTime:
+		d.WriteUint64(uint64(v.Time().UnixNano()))
+	default:
+		writeAny(d, v.Any(), h.seed)
+	}
+}
+
+// Forms of KindAny values written to digest.
+const (
+	anyError     = 'e'
+	anyBinary    = 'b'
+	anyText      = 't'
+	anyStringer  = 's'
+	anyHashValue = 'h'
+	anyType      = 'T'
+)
+
+// writeAny writes form and bytes of x to d.  Errors are written as
+// Error(), values with marshal methods as marshaled bytes, and
+// fmt.Stringer values as String(), because circlehash.HashValue skips
+// their unexported state.  Other values are written as
+// circlehash.HashValue digest, or as type name if HashValue doesn't
+// support their type.  Nil pointers are written as HashValue digest of nil.
+func writeAny(d *circlehash.Digest64, x interface{}, seed uint64) {
+	if rv := reflect.ValueOf(x); rv.Kind() == reflect.Ptr && rv.IsNil() {
+		// Methods can panic with nil receiver.
+		x = nil
+	}
+
+	switch x := x.(type) {
+	case error:
+		writeString(d, anyError, x.Error())
+		return
+	case encoding.BinaryMarshaler:
+		if b, err := x.MarshalBinary(); err == nil {
+			writeBytes(d, anyBinary, b)
+			return
+		}
+	case encoding.TextMarshaler:
+		if b, err := x.MarshalText(); err == nil {
+			writeBytes(d, anyText, b)
+			return
+		}
+	case fmt.Stringer:
+		writeString(d, anyStringer, x.String())
+		return
+	}
+
+	if vh, err := circlehash.HashValue(x, seed); err == nil {
+		d.WriteByte(anyHashValue)
+		d.WriteUint64(vh)
+		return
+	}
+	// Value of unsupported type, such as func, is written as its type.
+	writeString(d, anyType, reflect.TypeOf(x).String())
+}
+
+func writeString(d *circlehash.Digest64, form byte, s string) {
+	d.WriteByte(form)
+	d.WriteUint64(uint64(len(s)))
+	d.WriteString(s)
+}
+
+func writeBytes(d *circlehash.Digest64, form byte, b []byte) {
+	d.WriteByte(form)
+	d.WriteUint64(uint64(len(b)))
+	d.Write(b)
+}
+
+// seenSet is a set of digests with times they were last handled.
+// Digests are removed in insertion order when set is full.
+type seenSet struct {
+	mu    sync.Mutex
+	times map[uint64]int64
+	ring  []uint64 // digests in insertion order
+	next  int      // index of oldest digest in ring when ring is full
+}
+
+func newSeenSet(maxEntries int) *seenSet {
+	return &seenSet{
+		times: make(map[uint64]int64, maxEntries),
+		ring:  make([]uint64, 0, maxEntries),
+	}
+}
+
+// add returns true and records time t for digest if digest wasn't
+// handled within window before t.
+func (s *seenSet) add(digest uint64, t int64, window int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if last, ok := s.times[digest]; ok {
+		if t-last < window && t >= last {
+			return false
+		}
+		s.times[digest] = t
+		return true
+	}
+
+	if len(s.ring) < cap(s.ring) {
+		s.ring = append(s.ring, digest)
+	} else {
+		delete(s.times, s.ring[s.next])
+		s.ring[s.next] = digest
+		s.next = (s.next + 1) % len(s.ring)
+	}
+	s.times[digest] = t
+	return true
+}
+
+// len returns number of digests in s.
+func (s *seenSet) len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.times)
+}
@@ -0,0 +1,472 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.21
+// +build go1.21
+
+package sloghash
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/big"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testSeed = uint64(0x9E3779B97F4A7C15)
+
+var testTime = time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+// recordingHandler records messages of handled records.
+type recordingHandler struct {
+	mu       *sync.Mutex
+	messages *[]string
+}
+
+func newRecordingHandler() *recordingHandler {
+	return &recordingHandler{mu: &sync.Mutex{}, messages: &[]string{}}
+}
+
+func (h *recordingHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *recordingHandler) Handle(_ context.Context, r slog.Record) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	*h.messages = append(*h.messages, r.Message)
+	return nil
+}
+
+func (h *recordingHandler) WithAttrs([]slog.Attr) slog.Handler { return h }
+
+func (h *recordingHandler) WithGroup(string) slog.Handler { return h }
+
+func (h *recordingHandler) len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(*h.messages)
+}
+
+func newTestHandler(t *testing.T, next slog.Handler, opts *Options) *Handler {
+	h, err := NewHandler(next, opts)
+	if err != nil {
+		t.Fatalf("NewHandler() returned error %v", err)
+	}
+	return h
+}
+
+func newRecord(t time.Time, msg string, args ...interface{}) slog.Record {
+	r := slog.NewRecord(t, slog.LevelInfo, msg, 0)
+	r.Add(args...)
+	return r
+}
+
+// rate returns pointer to r for Options.Rate.
+func rate(r float64) *float64 {
+	return &r
+}
+
+func TestNewHandlerInvalid(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts Options
+	}{
+		{"negative rate", Options{Rate: rate(-0.5)}},
+		{"NaN rate", Options{Rate: rate(math.NaN())}},
+		{"negative window", Options{Window: -time.Second}},
+		{"negative max entries", Options{Window: time.Second, MaxEntries: -1}},
+	}
+	for _, tc := range testCases {
+		if _, err := NewHandler(newRecordingHandler(), &tc.opts); err == nil {
+			t.Errorf("%s: NewHandler() didn't return error", tc.name)
+		}
+	}
+}
+
+func TestDigest(t *testing.T) {
+	h := newTestHandler(t, newRecordingHandler(), nil)
+
+	digest := func(r slog.Record) uint64 { return h.Digest(r) }
+
+	base := newRecord(testTime, "request", "method", "GET", "status", 200)
+
+	// Time and level aren't included in digest.
+	same := []slog.Record{
+		newRecord(testTime.Add(time.Hour), "request", "method", "GET", "status", 200),
+		slog.NewRecord(testTime, slog.LevelError, "request", 0),
+	}
+	same[1].Add("method", "GET", "status", 200)
+	for _, r := range same {
+		if digest(r) != digest(base) {
+			t.Errorf("Digest(%v) != Digest(%v)", r, base)
+		}
+	}
+
+	different := []slog.Record{
+		newRecord(testTime, "request"),
+		newRecord(testTime, "request ", "method", "GET", "status", 200),
+		newRecord(testTime, "request", "method", "POST", "status", 200),
+		newRecord(testTime, "request", "method", "GET", "status", 201),
+		newRecord(testTime, "request", "method", "GET", "status", "200"),
+		newRecord(testTime, "request", "method", "GET", "code", 200),
+		newRecord(testTime, "request", "status", 200, "method", "GET"),
+		newRecord(testTime, "request", "method", "GET", "status", 200, "extra", true),
+		newRecord(testTime, "request", "methodGET", "", "status", 200),
+		newRecord(testTime, "request", slog.Group("method", "GET", "status"), "status", 200),
+	}
+	seen := map[uint64]int{digest(base): -1}
+	for i, r := range different {
+		d := digest(r)
+		if j, ok := seen[d]; ok {
+			t.Errorf("Digest() of record %d == Digest() of record %d", i, j)
+		}
+		seen[d] = i
+	}
+}
+
+func TestDigestValueKinds(t *testing.T) {
+	h := newTestHandler(t, newRecordingHandler(), nil)
+
+	type point struct{ X, Y int }
+
+	values := []interface{}{
+		"1", int64(1), uint64(1), 1.0, true, time.Duration(1), time.Unix(0, 1),
+		point{1, 2}, point{2, 1}, []int{1}, func() {},
+	}
+	seen := make(map[uint64]int)
+	for i, v := range values {
+		d := h.Digest(newRecord(testTime, "msg", "v", v))
+		if j, ok := seen[d]; ok {
+			t.Errorf("Digest() with value %#v == Digest() with value %#v", v, values[j])
+		}
+		seen[d] = i
+	}
+
+	// Values of different types with the same kind have the same digest.
+	d1 := h.Digest(newRecord(testTime, "msg", "v", 1))
+	d2 := h.Digest(newRecord(testTime, "msg", "v", int8(1)))
+	if d1 != d2 {
+		t.Errorf("Digest() with int 1 != Digest() with int8 1")
+	}
+}
+
+func TestDigestAnyValues(t *testing.T) {
+	h := newTestHandler(t, newRecordingHandler(), nil)
+
+	t1, t2 := time.Unix(1, 0), time.Unix(2, 0)
+	var nilErr *url.Error
+
+	testCases := []struct {
+		name  string
+		v1    interface{}
+		v2    interface{}
+		equal bool
+	}{
+		{"errors.New", errors.New("disk full"), errors.New("permission denied"), false},
+		{"errors.New same text", errors.New("disk full"), errors.New("disk full"), true},
+		{"fmt.Errorf", fmt.Errorf("write: %w", errors.New("a")), fmt.Errorf("write: %w", errors.New("b")), false},
+		{"error and string", errors.New("a"), "a", false},
+		{"*time.Time", &t1, &t2, false},
+		{"big.Int", big.NewInt(1), big.NewInt(2), false},
+		{"fmt.Stringer", &url.URL{Host: "a"}, &url.URL{Host: "b"}, false},
+		{"nil error", nilErr, (*url.Error)(nil), true},
+		{"only unexported fields", struct{ x int }{1}, struct{ x int }{2}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d1 := h.Digest(newRecord(testTime, "msg", "v", tc.v1))
+			d2 := h.Digest(newRecord(testTime, "msg", "v", tc.v2))
+			if (d1 == d2) != tc.equal {
+				t.Errorf("Digest() with %v = 0x%016x, Digest() with %v = 0x%016x; want equal = %t", tc.v1, d1, tc.v2, d2, tc.equal)
+			}
+		})
+	}
+}
+
+func TestDigestKeys(t *testing.T) {
+	h := newTestHandler(t, newRecordingHandler(), &Options{Keys: []string{"method", "req.path"}})
+
+	r1 := newRecord(testTime, "request", "method", "GET", "id", 1, slog.Group("req", "path", "/", "id", 1))
+	r2 := newRecord(testTime, "request", "method", "GET", "id", 2, slog.Group("req", "path", "/", "id", 2))
+	r3 := newRecord(testTime, "request", "method", "GET", "id", 1, slog.Group("req", "path", "/a", "id", 1))
+	if h.Digest(r1) != h.Digest(r2) {
+		t.Error("Digest() of records differing by unselected attributes are different")
+	}
+	if h.Digest(r1) == h.Digest(r3) {
+		t.Error("Digest() of records differing by selected attributes are equal")
+	}
+
+	// Empty Keys includes only message.
+	h = newTestHandler(t, newRecordingHandler(), &Options{Keys: []string{}})
+	if h.Digest(r1) != h.Digest(newRecord(testTime, "request")) {
+		t.Error("Digest() with empty Keys includes attributes")
+	}
+}
+
+func TestDigestWithAttrsAndGroup(t *testing.T) {
+	h := newTestHandler(t, newRecordingHandler(), nil)
+
+	testCases := []struct {
+		name string
+		h    slog.Handler
+		r    slog.Record
+		want slog.Record
+	}{
+		{
+			"WithAttrs",
+			h.WithAttrs([]slog.Attr{slog.String("service", "api")}),
+			newRecord(testTime, "msg", "k", 1),
+			newRecord(testTime, "msg", "service", "api", "k", 1),
+		},
+		{
+			"WithGroup",
+			h.WithGroup("req"),
+			newRecord(testTime, "msg", "k", 1),
+			newRecord(testTime, "msg", slog.Group("req", "k", 1)),
+		},
+		{
+			"WithGroup and WithAttrs",
+			h.WithAttrs([]slog.Attr{slog.Int("a", 1)}).WithGroup("g").WithAttrs([]slog.Attr{slog.Int("b", 2)}),
+			newRecord(testTime, "msg", "c", 3),
+			newRecord(testTime, "msg", "a", 1, slog.Group("g", "b", 2, "c", 3)),
+		},
+		{
+			"empty group",
+			h.WithGroup(""),
+			newRecord(testTime, "msg", "k", 1),
+			newRecord(testTime, "msg", "k", 1),
+		},
+		{
+			"inline group",
+			h,
+			newRecord(testTime, "msg", slog.Group("", "k", 1)),
+			newRecord(testTime, "msg", "k", 1),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.h.(*Handler).Digest(tc.r)
+			want := h.Digest(tc.want)
+			if got != want {
+				t.Errorf("Digest() = 0x%016x; want 0x%016x", got, want)
+			}
+		})
+	}
+}
+
+func TestSampling(t *testing.T) {
+	const numMessages = 100000
+	const sampleRate = 0.1
+
+	next := newRecordingHandler()
+	logger := slog.New(newTestHandler(t, next, &Options{Rate: rate(sampleRate), Seed: testSeed}))
+	for i := 0; i < numMessages; i++ {
+		logger.Info("message " + strconv.Itoa(i))
+	}
+
+	want := float64(numMessages) * sampleRate
+	if got := float64(next.len()); math.Abs(got-want) > 5*math.Sqrt(want) {
+		t.Errorf("sampling kept %v records; want about %v", got, want)
+	}
+
+	// Handler with the same options keeps the same records.
+	next2 := newRecordingHandler()
+	logger2 := slog.New(newTestHandler(t, next2, &Options{Rate: rate(sampleRate), Seed: testSeed}))
+	for i := numMessages - 1; i >= 0; i-- {
+		logger2.Info("message " + strconv.Itoa(i))
+	}
+	kept := make(map[string]bool)
+	for _, msg := range *next.messages {
+		kept[msg] = true
+	}
+	if len(*next2.messages) != len(kept) {
+		t.Fatalf("second handler kept %d records; want %d", len(*next2.messages), len(kept))
+	}
+	for _, msg := range *next2.messages {
+		if !kept[msg] {
+			t.Errorf("second handler kept %q", msg)
+		}
+	}
+}
+
+func TestSamplingRateLimits(t *testing.T) {
+	// Nil rate disables sampling.  Otherwise, rate has the same meaning as
+	// circlehash.Sampler.Rate.
+	testCases := []struct {
+		name string
+		rate *float64
+		want int
+	}{
+		{"nil", nil, 100},
+		{"0", rate(0), 0},
+		{"1", rate(1), 100},
+		{"2", rate(2), 100},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			next := newRecordingHandler()
+			h := newTestHandler(t, next, &Options{Rate: tc.rate})
+			for i := 0; i < 100; i++ {
+				if err := h.Handle(context.Background(), newRecord(testTime, "msg "+strconv.Itoa(i))); err != nil {
+					t.Fatalf("Handle() returned error %v", err)
+				}
+			}
+			if next.len() != tc.want {
+				t.Errorf("handler kept %d records; want %d", next.len(), tc.want)
+			}
+		})
+	}
+}
+
+func TestDeduplication(t *testing.T) {
+	next := newRecordingHandler()
+	h := newTestHandler(t, next, &Options{Window: time.Minute})
+
+	testCases := []struct {
+		offset time.Duration
+		msg    string
+		want   bool
+	}{
+		{0, "a", true},
+		{0, "b", true},
+		{time.Second, "a", false},
+		{59 * time.Second, "a", false},
+		{59 * time.Second, "b", false},
+		{time.Minute, "a", true},
+		{time.Minute + time.Second, "b", true},
+		{90 * time.Second, "a", false},
+		{2 * time.Minute, "a", true},
+		{2 * time.Minute, "c", true},
+	}
+	for _, tc := range testCases {
+		n := next.len()
+		if err := h.Handle(context.Background(), newRecord(testTime.Add(tc.offset), tc.msg)); err != nil {
+			t.Fatalf("Handle() returned error %v", err)
+		}
+		if got := next.len() > n; got != tc.want {
+			t.Errorf("Handle(%q at %v) passed record = %t; want %t", tc.msg, tc.offset, got, tc.want)
+		}
+	}
+}
+
+func TestDeduplicationErrors(t *testing.T) {
+	next := newRecordingHandler()
+	h := newTestHandler(t, next, &Options{Window: time.Minute})
+
+	for _, err := range []error{errors.New("disk full"), errors.New("permission denied")} {
+		r := newRecord(testTime, "write failed", "err", err)
+		if err := h.Handle(context.Background(), r); err != nil {
+			t.Fatalf("Handle() returned error %v", err)
+		}
+	}
+	if next.len() != 2 {
+		t.Errorf("handler kept %d records with different errors; want 2", next.len())
+	}
+}
+
+func TestDeduplicationZeroTime(t *testing.T) {
+	next := newRecordingHandler()
+	h := newTestHandler(t, next, &Options{Window: time.Hour})
+
+	for i := 0; i < 10; i++ {
+		if err := h.Handle(context.Background(), newRecord(time.Time{}, "msg")); err != nil {
+			t.Fatalf("Handle() returned error %v", err)
+		}
+	}
+	if next.len() != 1 {
+		t.Errorf("handler kept %d records with zero time; want 1", next.len())
+	}
+}
+
+func TestSeenSetBounded(t *testing.T) {
+	const maxEntries = 10
+
+	next := newRecordingHandler()
+	h := newTestHandler(t, next, &Options{Window: time.Hour, MaxEntries: maxEntries})
+
+	handle := func(msg string) {
+		if err := h.Handle(context.Background(), newRecord(testTime, msg)); err != nil {
+			t.Fatalf("Handle() returned error %v", err)
+		}
+	}
+
+	for i := 0; i < 100; i++ {
+		handle("message " + strconv.Itoa(i))
+	}
+	if n := h.seen.len(); n != maxEntries {
+		t.Errorf("seen-set has %d entries; want %d", n, maxEntries)
+	}
+
+	// The newest messages are still duplicates, and the oldest messages
+	// were removed.
+	n := next.len()
+	handle("message 99")
+	if next.len() != n {
+		t.Error("duplicate of newest message wasn't dropped")
+	}
+	handle("message 0")
+	if next.len() != n+1 {
+		t.Error("message removed from seen-set was dropped")
+	}
+}
+
+func TestDefaultMaxEntries(t *testing.T) {
+	h := newTestHandler(t, newRecordingHandler(), &Options{Window: time.Hour})
+	if cap(h.seen.ring) != DefaultMaxEntries {
+		t.Errorf("seen-set capacity = %d; want %d", cap(h.seen.ring), DefaultMaxEntries)
+	}
+}
+
+func TestConcurrentHandle(t *testing.T) {
+	next := newRecordingHandler()
+	logger := slog.New(newTestHandler(t, next, &Options{Rate: rate(0.5), Window: time.Hour, MaxEntries: 1000}))
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			l := logger.With("goroutine", g)
+			for i := 0; i < 1000; i++ {
+				l.Info("message", "i", i%100)
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	// Each goroutine logs 100 distinct records, and about half are sampled.
+	if n := next.len(); n == 0 || n > 800 {
+		t.Errorf("handler kept %d records; want about 400", n)
+	}
+}
+
+func BenchmarkHandle(b *testing.B) {
+	h, _ := NewHandler(slog.NewTextHandler(discard{}, nil), &Options{Rate: rate(0.01), Window: time.Minute, Seed: testSeed})
+	logger := slog.New(h)
+	for i := 0; i < b.N; i++ {
+		logger.Info("request", "method", "GET", "path", "/index.html", "status", 200)
+	}
+}
+
+type discard struct{}
+
+func (discard) Write(b []byte) (int, error) { return len(b), nil }
@@ -0,0 +1,411 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package circlehash
+
+import (
+	"encoding"
+	"errors"
+	"math"
+	"reflect"
+)
+
+// Value encoding is versioned by including the encoding version in every tag.
+// Changing the encoding requires a new version so digests produced by different
+// encodings don't match by accident.
+//
+// Value encoding version 1:
+//   - nil pointer and nil interface: Hash64Uint64x2(tagNil, 0, seed)
+//   - bool: Hash64Uint64x2(tagBool, 0 or 1, seed)
+//   - signed integer: Hash64Uint64x2(tagInt, uint64(int64(v)), seed)
+//   - unsigned integer: Hash64Uint64x2(tagUint, uint64(v), seed)
+//   - float: Hash64Uint64x2(tagFloat, math.Float64bits(float64(v)), seed)
+//     with -0 replaced by +0 and all NaNs replaced by one NaN
+//   - complex: CombineOrdered64(seed, tagComplex, real bits, imag bits)
+//     with float bits normalized as above
+//   - string and byte slice: Hash64Uint64x2(tagString, Hash64(bytes, seed), seed)
+//   - slice and array: CombineOrdered64(seed, tagSeq, len, element digests...)
+//   - struct: CombineOrdered64(seed, tagStruct, number of fields, field digests...)
+//     using exported fields in declaration order
+//   - struct with unexported fields implementing encoding.BinaryMarshaler:
+//     Hash64Uint64x2(tagBinary, Hash64(MarshalBinary(), seed), seed)
+//   - struct with unexported fields implementing encoding.TextMarshaler:
+//     Hash64Uint64x2(tagText, Hash64(MarshalText(), seed), seed)
+//   - map: CombineOrdered64(seed, tagMap, len, sum of Combine64(seed, key digest, value digest))
+//     where sum is modulo 2^64 so map order doesn't matter
+//   - non-nil pointer and interface: digest of the value it points to or contains
+const (
+	valueEncodingVersion = uint64(1)
+
+	valueTagNil     = valueEncodingVersion<<32 | 1
+	valueTagBool    = valueEncodingVersion<<32 | 2
+	valueTagInt     = valueEncodingVersion<<32 | 3
+	valueTagUint    = valueEncodingVersion<<32 | 4
+	valueTagFloat   = valueEncodingVersion<<32 | 5
+	valueTagComplex = valueEncodingVersion<<32 | 6
+	valueTagString  = valueEncodingVersion<<32 | 7
+	valueTagSeq     = valueEncodingVersion<<32 | 8
+	valueTagStruct  = valueEncodingVersion<<32 | 9
+	valueTagMap     = valueEncodingVersion<<32 | 10
+	valueTagBinary  = valueEncodingVersion<<32 | 11
+	valueTagText    = valueEncodingVersion<<32 | 12
+)
+
+// canonicalNaN is used for all NaN values.
+const canonicalNaN = uint64(0x7FF8000000000001)
+
+// ErrCycle is returned by HashValue when v contains a cycle.
+var ErrCycle = errors.New("circlehash: value contains a cycle")
+
+// UnsupportedTypeError is returned by HashValue when v contains
+// a value of unsupported type, such as func, chan, and struct with only
+// unexported fields.
+type UnsupportedTypeError struct {
+	Type reflect.Type
+}
+
+func (e *UnsupportedTypeError) Error() string {
+	return "circlehash: unsupported type: " + e.Type.String()
+}
+
+// HashValue returns a 64-bit digest of v by walking v with reflection.
+// Digest doesn't depend on map iteration order or on the memory layout of v.
+//
+// Structs are hashed using exported fields in declaration order.
+// Fields with struct tag `circlehash:"-"` are skipped.  Structs with
+// unexported fields that implement encoding.BinaryMarshaler or
+// encoding.TextMarshaler (such as time.Time, big.Int, and netip.Addr) are
+// hashed as bytes returned by MarshalBinary, or MarshalText if they don't
+// implement encoding.BinaryMarshaler.  Pointers and
+// interfaces are hashed as the value they point to or contain.  Nil slices
+// and nil maps are hashed as empty slices and empty maps.  Byte slices are
+// hashed like strings.  Signed integers of different sizes with the same value
+// are hashed alike (and the same for unsigned integers and floats).
+//
+// HashValue returns ErrCycle if v contains a cycle, and *UnsupportedTypeError
+// if v contains a func, chan, or unsafe.Pointer, or a struct with unexported
+// fields, no hashed fields, and no marshal methods, such as errors created
+// by errors.New.  Such structs would otherwise all have the same digest.
+//
+// Digest produced by HashValue is stable for the same value encoding version.
+// HashValue uses value encoding version 1.  Typed helpers such as HashValueString
+// and HashValueStruct produce the same digests without reflection.
+func HashValue(v interface{}, seed uint64) (uint64, error) {
+	h := valueHasher{seed: seed}
+	return h.hash(reflect.ValueOf(v))
+}
+
+// HashValueNil returns a 64-bit digest of nil pointer or nil interface.
+// Digest is the same as HashValue with nil pointer or nil interface.
+func HashValueNil(seed uint64) uint64 {
+	return circle64fUint64x2(valueTagNil, 0, seed)
+}
+
+// HashValueBool returns a 64-bit digest of v.
+// Digest is the same as HashValue with bool.
+func HashValueBool(v bool, seed uint64) uint64 {
+	b := uint64(0)
+	if v {
+		b = 1
+	}
+	return circle64fUint64x2(valueTagBool, b, seed)
+}
+
+// HashValueInt returns a 64-bit digest of v.
+// Digest is the same as HashValue with signed integer of any size.
+func HashValueInt(v int64, seed uint64) uint64 {
+	return circle64fUint64x2(valueTagInt, uint64(v), seed)
+}
+
+// HashValueUint returns a 64-bit digest of v.
+// Digest is the same as HashValue with unsigned integer of any size.
+func HashValueUint(v uint64, seed uint64) uint64 {
+	return circle64fUint64x2(valueTagUint, v, seed)
+}
+
+// HashValueFloat returns a 64-bit digest of v.
+// Digest is the same as HashValue with float32 or float64.
+func HashValueFloat(v float64, seed uint64) uint64 {
+	return circle64fUint64x2(valueTagFloat, normalizedFloat64bits(v), seed)
+}
+
+// HashValueComplex returns a 64-bit digest of v.
+// Digest is the same as HashValue with complex64 or complex128.
+func HashValueComplex(v complex128, seed uint64) uint64 {
+	hs := [3]uint64{valueTagComplex, normalizedFloat64bits(real(v)), normalizedFloat64bits(imag(v))}
+	return circle64fUint64s(hs[:], seed)
+}
+
+// HashValueString returns a 64-bit digest of s.
+// Digest is the same as HashValue with string.
+func HashValueString(s string, seed uint64) uint64 {
+	return circle64fUint64x2(valueTagString, Hash64String(s, seed), seed)
+}
+
+// HashValueBytes returns a 64-bit digest of b.
+// Digest is the same as HashValue with byte slice and HashValueString.
+func HashValueBytes(b []byte, seed uint64) uint64 {
+	return circle64fUint64x2(valueTagString, Hash64(b, seed), seed)
+}
+
+// HashValueSlice returns a 64-bit digest of a slice or array of length n.
+// elem returns the digest of the element at index i.
+// Digest is the same as HashValue with slice or array.
+func HashValueSlice(seed uint64, n int, elem func(i int) uint64) uint64 {
+	d := Digest64{seed: seed}
+	d.Reset()
+	d.WriteUint64(valueTagSeq)
+	d.WriteUint64(uint64(n))
+	for i := 0; i < n; i++ {
+		d.WriteUint64(elem(i))
+	}
+	return d.Sum64()
+}
+
+// HashValueStruct returns a 64-bit digest of a struct with field digests
+// in declaration order.  Digest is the same as HashValue with struct.
+func HashValueStruct(seed uint64, fields ...uint64) uint64 {
+	d := Digest64{seed: seed}
+	d.Reset()
+	d.WriteUint64(valueTagStruct)
+	d.WriteUint64(uint64(len(fields)))
+	for _, f := range fields {
+		d.WriteUint64(f)
+	}
+	return d.Sum64()
+}
+
+// HashValueMap returns a 64-bit digest of a map with n entries.  entrySum is
+// the sum (modulo 2^64) of Combine64(seed, key digest, value digest) for every
+// entry.  Digest is the same as HashValue with map.
+func HashValueMap(seed uint64, n int, entrySum uint64) uint64 {
+	hs := [3]uint64{valueTagMap, uint64(n), entrySum}
+	return circle64fUint64s(hs[:], seed)
+}
+
+func normalizedFloat64bits(f float64) uint64 {
+	switch {
+	case f == 0:
+		return 0 // -0 and +0
+	case f != f:
+		return canonicalNaN
+	}
+	return math.Float64bits(f)
+}
+
+type visit struct {
+	ptr uintptr
+	typ reflect.Type
+	len int
+}
+
+// valueHasher hashes values using value encoding version 1.
+type valueHasher struct {
+	seed     uint64
+	visiting map[visit]struct{}
+}
+
+func (h *valueHasher) hash(v reflect.Value) (uint64, error) {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return HashValueNil(h.seed), nil
+
+	case reflect.Bool:
+		return HashValueBool(v.Bool(), h.seed), nil
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return HashValueInt(v.Int(), h.seed), nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return HashValueUint(v.Uint(), h.seed), nil
+
+	case reflect.Float32, reflect.Float64:
+		return HashValueFloat(v.Float(), h.seed), nil
+
+	case reflect.Complex64, reflect.Complex128:
+		return HashValueComplex(v.Complex(), h.seed), nil
+
+	case reflect.String:
+		return HashValueString(v.String(), h.seed), nil
+
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return HashValueBytes(v.Bytes(), h.seed), nil
+		}
+		if v.Len() == 0 {
+			return h.hashSeq(v)
+		}
+		return h.hashVisiting(v, visit{v.Pointer(), v.Type(), v.Len()}, h.hashSeq)
+
+	case reflect.Array:
+		return h.hashSeq(v)
+
+	case reflect.Struct:
+		return h.hashStruct(v)
+
+	case reflect.Map:
+		if v.Len() == 0 {
+			return HashValueMap(h.seed, 0, 0), nil
+		}
+		return h.hashVisiting(v, visit{v.Pointer(), v.Type(), 0}, h.hashMap)
+
+	case reflect.Ptr:
+		if v.IsNil() {
+			return HashValueNil(h.seed), nil
+		}
+		return h.hashVisiting(v, visit{v.Pointer(), v.Type(), 0}, func(v reflect.Value) (uint64, error) {
+			return h.hash(v.Elem())
+		})
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return HashValueNil(h.seed), nil
+		}
+		return h.hash(v.Elem())
+	}
+
+	return 0, &UnsupportedTypeError{Type: v.Type()}
+}
+
+// hashVisiting calls fn with v and returns ErrCycle if v is already being visited.
+func (h *valueHasher) hashVisiting(v reflect.Value, key visit, fn func(reflect.Value) (uint64, error)) (uint64, error) {
+	if h.visiting == nil {
+		h.visiting = make(map[visit]struct{})
+	}
+	if _, ok := h.visiting[key]; ok {
+		return 0, ErrCycle
+	}
+	h.visiting[key] = struct{}{}
+	defer delete(h.visiting, key)
+
+	return fn(v)
+}
+
+func (h *valueHasher) hashSeq(v reflect.Value) (uint64, error) {
+	d := Digest64{seed: h.seed}
+	d.Reset()
+	d.WriteUint64(valueTagSeq)
+	d.WriteUint64(uint64(v.Len()))
+	for i := 0; i < v.Len(); i++ {
+		e, err := h.hash(v.Index(i))
+		if err != nil {
+			return 0, err
+		}
+		d.WriteUint64(e)
+	}
+	return d.Sum64(), nil
+}
+
+func (h *valueHasher) hashStruct(v reflect.Value) (uint64, error) {
+	t := v.Type()
+
+	numFields, unexported := 0, false
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if hashedField(f) {
+			numFields++
+		}
+		if f.PkgPath != "" {
+			unexported = true
+		}
+	}
+
+	if unexported {
+		if tag, b, ok, err := marshalStruct(v); ok {
+			if err != nil {
+				return 0, err
+			}
+			return circle64fUint64x2(tag, Hash64(b, h.seed), h.seed), nil
+		}
+		if numFields == 0 {
+			// State is entirely unexported.
+			return 0, &UnsupportedTypeError{Type: t}
+		}
+	}
+
+	d := Digest64{seed: h.seed}
+	d.Reset()
+
+	d.WriteUint64(valueTagStruct)
+	d.WriteUint64(uint64(numFields))
+	for i := 0; i < t.NumField(); i++ {
+		if !hashedField(t.Field(i)) {
+			continue
+		}
+		f, err := h.hash(v.Field(i))
+		if err != nil {
+			return 0, err
+		}
+		d.WriteUint64(f)
+	}
+	return d.Sum64(), nil
+}
+
+func (h *valueHasher) hashMap(v reflect.Value) (uint64, error) {
+	var sum uint64
+	iter := v.MapRange()
+	for iter.Next() {
+		k, err := h.hash(iter.Key())
+		if err != nil {
+			return 0, err
+		}
+		e, err := h.hash(iter.Value())
+		if err != nil {
+			return 0, err
+		}
+		sum += Combine64(h.seed, k, e)
+	}
+	return HashValueMap(h.seed, v.Len(), sum), nil
+}
+
+var (
+	binaryMarshalerType = reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem()
+	textMarshalerType   = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// marshalStruct returns value tag and bytes of struct v marshaled by
+// MarshalBinary or MarshalText.  It returns ok false if v doesn't
+// implement encoding.BinaryMarshaler or encoding.TextMarshaler.
+func marshalStruct(v reflect.Value) (tag uint64, b []byte, ok bool, err error) {
+	t := v.Type()
+	if !t.Implements(binaryMarshalerType) && !t.Implements(textMarshalerType) {
+		pt := reflect.PtrTo(t)
+		if !pt.Implements(binaryMarshalerType) && !pt.Implements(textMarshalerType) {
+			return 0, nil, false, nil
+		}
+		// Methods have pointer receivers.
+		if v.CanAddr() {
+			v = v.Addr()
+		} else {
+			p := reflect.New(t)
+			p.Elem().Set(v)
+			v = p
+		}
+	}
+
+	switch m := v.Interface().(type) {
+	case encoding.BinaryMarshaler:
+		b, err = m.MarshalBinary()
+		return valueTagBinary, b, true, err
+	case encoding.TextMarshaler:
+		b, err = m.MarshalText()
+		return valueTagText, b, true, err
+	}
+	return 0, nil, false, nil
+}
+
+// hashedField returns true if struct field f is included in digest.
+func hashedField(f reflect.StructField) bool {
+	return f.PkgPath == "" && f.Tag.Get("circlehash") != "-"
+}
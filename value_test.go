@@ -0,0 +1,388 @@
+// Copyright 2026 Faye Amacker
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     https://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package circlehash
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+	"unsafe"
+)
+
+type valueTestInner struct {
+	Name  string
+	Score float64
+}
+
+type valueTestOuter struct {
+	ID       uint32
+	Enabled  bool
+	Tags     []string
+	Inner    valueTestInner
+	InnerPtr *valueTestInner
+	Attrs    map[string]int
+	Any      interface{}
+	Ignored  string `circlehash:"-"`
+	private  int
+}
+
+func TestHashValueTypedHelpers(t *testing.T) {
+	seed := numsGoldenRatio
+
+	var nilPtr *int
+	var nilIface interface{}
+
+	testCases := []struct {
+		name  string
+		value interface{}
+		want  uint64
+	}{
+		{"nil", nil, HashValueNil(seed)},
+		{"nil pointer", nilPtr, HashValueNil(seed)},
+		{"nil interface pointer", &nilIface, HashValueNil(seed)},
+		{"bool false", false, HashValueBool(false, seed)},
+		{"bool true", true, HashValueBool(true, seed)},
+		{"int", int(-7), HashValueInt(-7, seed)},
+		{"int8", int8(-7), HashValueInt(-7, seed)},
+		{"int64", int64(math.MinInt64), HashValueInt(math.MinInt64, seed)},
+		{"uint", uint(7), HashValueUint(7, seed)},
+		{"uint16", uint16(7), HashValueUint(7, seed)},
+		{"uintptr", uintptr(7), HashValueUint(7, seed)},
+		{"float32", float32(1.5), HashValueFloat(1.5, seed)},
+		{"float64", float64(1.5), HashValueFloat(1.5, seed)},
+		{"complex64", complex64(1 + 2i), HashValueComplex(1+2i, seed)},
+		{"complex128", complex128(1 + 2i), HashValueComplex(1+2i, seed)},
+		{"string", "hello", HashValueString("hello", seed)},
+		{"byte slice", []byte("hello"), HashValueString("hello", seed)},
+		{"pointer", &valueTestInner{"a", 1}, HashValueStruct(seed, HashValueString("a", seed), HashValueFloat(1, seed))},
+		{"nil slice", []int(nil), HashValueSlice(seed, 0, nil)},
+		{"nil map", map[int]int(nil), HashValueMap(seed, 0, 0)},
+		{"slice", []int{1, 2}, HashValueSlice(seed, 2, func(i int) uint64 { return HashValueInt(int64(i+1), seed) })},
+		{"array", [2]int{1, 2}, HashValueSlice(seed, 2, func(i int) uint64 { return HashValueInt(int64(i+1), seed) })},
+		{"map", map[string]bool{"a": true, "b": false}, HashValueMap(seed, 2,
+			Combine64(seed, HashValueString("a", seed), HashValueBool(true, seed))+
+				Combine64(seed, HashValueString("b", seed), HashValueBool(false, seed)))},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := HashValue(tc.value, seed)
+			if err != nil {
+				t.Fatalf("HashValue(%v) returned error %v", tc.value, err)
+			}
+			if got != tc.want {
+				t.Errorf("HashValue(%v) = 0x%016x; want 0x%016x", tc.value, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestHashValueStruct(t *testing.T) {
+	seed := numsGoldenRatio
+
+	v := valueTestOuter{
+		ID:       1,
+		Enabled:  true,
+		Tags:     []string{"x", "y"},
+		Inner:    valueTestInner{"a", 1},
+		InnerPtr: &valueTestInner{"b", 2},
+		Attrs:    map[string]int{"k": 3},
+		Any:      "z",
+		Ignored:  "ignored",
+		private:  4,
+	}
+
+	want := HashValueStruct(seed,
+		HashValueUint(1, seed),
+		HashValueBool(true, seed),
+		HashValueSlice(seed, 2, func(i int) uint64 { return HashValueString(v.Tags[i], seed) }),
+		HashValueStruct(seed, HashValueString("a", seed), HashValueFloat(1, seed)),
+		HashValueStruct(seed, HashValueString("b", seed), HashValueFloat(2, seed)),
+		HashValueMap(seed, 1, Combine64(seed, HashValueString("k", seed), HashValueInt(3, seed))),
+		HashValueString("z", seed),
+	)
+
+	got, err := HashValue(v, seed)
+	if err != nil {
+		t.Fatalf("HashValue(%v) returned error %v", v, err)
+	}
+	if got != want {
+		t.Errorf("HashValue(%v) = 0x%016x; want 0x%016x", v, got, want)
+	}
+
+	// Skipped fields don't change digest.
+	v.Ignored = "changed"
+	v.private = 5
+	if got2, _ := HashValue(v, seed); got2 != got {
+		t.Errorf("HashValue() changed after modifying skipped fields: 0x%016x != 0x%016x", got2, got)
+	}
+
+	// Hashed fields change digest.
+	v.Attrs["k"] = 4
+	if got2, _ := HashValue(v, seed); got2 == got {
+		t.Errorf("HashValue() didn't change after modifying map value")
+	}
+}
+
+func TestHashValueMapOrder(t *testing.T) {
+	seed := numsGoldenRatio
+
+	m1 := make(map[int]string)
+	m2 := make(map[int]string)
+	for i := 0; i < 100; i++ {
+		m1[i] = "v"
+		m2[99-i] = "v"
+	}
+
+	h1, err := HashValue(m1, seed)
+	if err != nil {
+		t.Fatalf("HashValue() returned error %v", err)
+	}
+	h2, err := HashValue(m2, seed)
+	if err != nil {
+		t.Fatalf("HashValue() returned error %v", err)
+	}
+	if h1 != h2 {
+		t.Errorf("HashValue() of equal maps = 0x%016x and 0x%016x", h1, h2)
+	}
+}
+
+func TestHashValueNormalizedFloats(t *testing.T) {
+	seed := numsGoldenRatio
+
+	testCases := []struct {
+		name string
+		f1   float64
+		f2   float64
+	}{
+		{"zeros", 0, math.Copysign(0, -1)},
+		{"NaNs", math.NaN(), math.Float64frombits(0x7FF0000000000123)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			h1, _ := HashValue(tc.f1, seed)
+			h2, _ := HashValue(tc.f2, seed)
+			if h1 != h2 {
+				t.Errorf("HashValue(%v) = 0x%016x; HashValue(%v) = 0x%016x", tc.f1, h1, tc.f2, h2)
+			}
+		})
+	}
+}
+
+func TestHashValueDistinct(t *testing.T) {
+	seed := numsGoldenRatio
+
+	values := []interface{}{
+		nil,
+		false,
+		int(0),
+		uint(0),
+		float64(0),
+		"",
+		[]int{},
+		map[int]int{},
+		struct{}{},
+		[]int{0},
+		[]string{""},
+		[]string{"", ""},
+		[]string{"a", "b"},
+		[]string{"b", "a"},
+		[]string{"ab"},
+		struct{ A, B string }{"a", "b"},
+		struct{ A, B string }{"b", "a"},
+	}
+
+	seen := make(map[uint64]interface{})
+	for _, v := range values {
+		h, err := HashValue(v, seed)
+		if err != nil {
+			t.Fatalf("HashValue(%#v) returned error %v", v, err)
+		}
+		if prev, ok := seen[h]; ok {
+			t.Errorf("HashValue(%#v) == HashValue(%#v) = 0x%016x", v, prev, h)
+		}
+		seen[h] = v
+	}
+}
+
+func TestHashValueErrors(t *testing.T) {
+	seed := numsGoldenRatio
+
+	type node struct {
+		Next *node
+	}
+	cyclicPtr := &node{}
+	cyclicPtr.Next = cyclicPtr
+
+	cyclicMap := make(map[string]interface{})
+	cyclicMap["self"] = cyclicMap
+
+	cyclicSlice := make([]interface{}, 1)
+	cyclicSlice[0] = cyclicSlice
+
+	testCases := []struct {
+		name        string
+		value       interface{}
+		wantErr     error
+		wantErrType reflect.Type
+	}{
+		{"cyclic pointer", cyclicPtr, ErrCycle, nil},
+		{"cyclic map", cyclicMap, ErrCycle, nil},
+		{"cyclic slice", cyclicSlice, ErrCycle, nil},
+		{"func", func() {}, nil, reflect.TypeOf(func() {})},
+		{"chan", make(chan int), nil, reflect.TypeOf(make(chan int))},
+		{"unsafe pointer", unsafe.Pointer(nil), nil, reflect.TypeOf(unsafe.Pointer(nil))},
+		{"func in struct", struct{ F func() }{}, nil, reflect.TypeOf(func() {})},
+		{"chan in map value", map[int]chan int{1: nil}, nil, reflect.TypeOf(make(chan int))},
+		{"func in map key", map[interface{}]int{new(func()): 1}, nil, reflect.TypeOf(func() {})},
+		{"only unexported fields", struct{ x int }{1}, nil, reflect.TypeOf(struct{ x int }{})},
+		{"errors.New", errors.New("a"), nil, reflect.TypeOf(errors.New("a")).Elem()},
+		{"fmt.Errorf", fmt.Errorf("a: %w", errors.New("b")), nil, reflect.TypeOf(fmt.Errorf("a: %w", errors.New("b"))).Elem()},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := HashValue(tc.value, seed)
+			if err == nil {
+				t.Fatalf("HashValue() didn't return error")
+			}
+
+			if tc.wantErr != nil && !errors.Is(err, tc.wantErr) {
+				t.Errorf("HashValue() returned error %v; want %v", err, tc.wantErr)
+			}
+
+			if tc.wantErrType != nil {
+				var typeErr *UnsupportedTypeError
+				if !errors.As(err, &typeErr) {
+					t.Fatalf("HashValue() returned error %v (%T); want *UnsupportedTypeError", err, err)
+				}
+				if typeErr.Type != tc.wantErrType {
+					t.Errorf("UnsupportedTypeError.Type = %s; want %s", typeErr.Type, tc.wantErrType)
+				}
+			}
+		})
+	}
+}
+
+func TestHashValueMarshalers(t *testing.T) {
+	seed := numsGoldenRatio
+
+	hash := func(v interface{}) uint64 {
+		t.Helper()
+		h, err := HashValue(v, seed)
+		if err != nil {
+			t.Fatalf("HashValue(%v) returned error %v", v, err)
+		}
+		return h
+	}
+
+	// Structs with unexported state are hashed with their marshal methods.
+	t1, t2 := time.Unix(1, 0).UTC(), time.Unix(2, 0).UTC()
+	if hash(t1) == hash(t2) {
+		t.Errorf("HashValue(%v) == HashValue(%v)", t1, t2)
+	}
+	if hash(t1) != hash(time.Unix(1, 0).UTC()) {
+		t.Errorf("HashValue(%v) isn't stable", t1)
+	}
+	b, _ := t1.MarshalBinary()
+	if got, want := hash(t1), circle64fUint64x2(valueTagBinary, Hash64(b, seed), seed); got != want {
+		t.Errorf("HashValue(%v) = 0x%016x; want 0x%016x", t1, got, want)
+	}
+
+	// big.Int implements encoding.TextMarshaler with pointer receiver.
+	n1, n2 := big.NewInt(1), big.NewInt(2)
+	if hash(n1) == hash(n2) {
+		t.Errorf("HashValue(%v) == HashValue(%v)", n1, n2)
+	}
+	if got, want := hash(*n1), circle64fUint64x2(valueTagText, Hash64([]byte("1"), seed), seed); got != want {
+		t.Errorf("HashValue(big.Int 1) = 0x%016x; want 0x%016x", got, want)
+	}
+	if hash(n1) != hash(*n1) {
+		t.Errorf("HashValue(*big.Int) != HashValue(big.Int)")
+	}
+
+	type event struct {
+		Time time.Time
+		N    *big.Int
+	}
+	if hash(event{t1, n1}) == hash(event{t2, n1}) || hash(event{t1, n1}) == hash(event{t1, n2}) {
+		t.Errorf("HashValue() of struct doesn't depend on marshaled fields")
+	}
+}
+
+func TestHashValueSharedPointers(t *testing.T) {
+	seed := numsGoldenRatio
+
+	// Shared pointers (without cycle) are allowed.
+	inner := &valueTestInner{"a", 1}
+	v := []*valueTestInner{inner, inner}
+
+	got, err := HashValue(v, seed)
+	if err != nil {
+		t.Fatalf("HashValue() returned error %v", err)
+	}
+
+	want, _ := HashValue([]valueTestInner{{"a", 1}, {"a", 1}}, seed)
+	if got != want {
+		t.Errorf("HashValue() = 0x%016x; want 0x%016x", got, want)
+	}
+}
+
+// TestHashValueStable verifies value encoding version 1 doesn't change.
+func TestHashValueStable(t *testing.T) {
+	testCases := []struct {
+		name  string
+		value interface{}
+		want  uint64
+	}{
+		{"nil", nil, 0xe28f13a7543b1f35},
+		{"int", 42, 0xfa2d8f1a6e7bac8c},
+		{"string", "hello", 0x7388eb3b080bbe64},
+		{"struct", valueTestInner{"a", 1}, 0x890ad640b1ccf69a},
+		{"map", map[string]int{"a": 1, "b": 2}, 0xc363661aabb46e83},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := HashValue(tc.value, numsGoldenRatio)
+			if err != nil {
+				t.Fatalf("HashValue() returned error %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("HashValue(%v) = 0x%016x; want 0x%016x", tc.value, got, tc.want)
+			}
+		})
+	}
+}
+
+func BenchmarkHashValue(b *testing.B) {
+	v := valueTestOuter{
+		ID:       1,
+		Enabled:  true,
+		Tags:     []string{"x", "y"},
+		Inner:    valueTestInner{"a", 1},
+		InnerPtr: &valueTestInner{"b", 2},
+		Attrs:    map[string]int{"k": 3},
+		Any:      "z",
+	}
+	for i := 0; i < b.N; i++ {
+		_, _ = HashValue(v, numsGoldenRatio)
+	}
+}